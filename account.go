@@ -0,0 +1,91 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"refinder/savedir"
+	"strconv"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+func addSaveDirFlag(flags *flag.FlagSet) *string {
+	return flags.String("save-dir", "", fmt.Sprintf("save folder or a folder of account folders (default: $%s or autodetect)", savedir.EnvVar))
+}
+
+// selectAccount finds the save folder to read. When several accounts are
+// found the user picks one on a terminal, the most recently played is the
+// default.
+func selectAccount(saveDir string) (savedir.Account, error) {
+	return findAccount(saveDir, term.IsTerminal(int(os.Stdin.Fd())))
+}
+
+// findAccount finds the save folder to read. Without prompt, or when stdin
+// is not a terminal, several accounts are an error instead of a question
+// that would block scripts.
+func findAccount(saveDir string, prompt bool) (savedir.Account, error) {
+	var accounts []savedir.Account
+	var err error
+	if saveDir != "" {
+		accounts, err = savedir.Accounts(saveDir)
+	} else {
+		accounts, err = savedir.Find()
+	}
+	if err != nil {
+		return savedir.Account{}, fmt.Errorf("could not read save folder: %w", err)
+	}
+
+	switch len(accounts) {
+	case 0:
+		return savedir.Account{}, fmt.Errorf("could not find a save folder, use --save-dir or %s", savedir.EnvVar)
+	case 1:
+		return accounts[0], nil
+	}
+
+	if !prompt {
+		return savedir.Account{}, severalAccountsError(accounts)
+	}
+	// The prompt goes to stderr so it does not end up in piped output.
+	return chooseAccount(accounts, os.Stdin, os.Stderr)
+}
+
+func severalAccountsError(accounts []savedir.Account) error {
+	var dirs []string
+	for _, account := range accounts {
+		dirs = append(dirs, "  "+account.Dir)
+	}
+	return fmt.Errorf("found %d accounts, use --save-dir or %s to pick one of them:\n%s", len(accounts), savedir.EnvVar, strings.Join(dirs, "\n"))
+}
+
+func chooseAccount(accounts []savedir.Account, in io.Reader, out io.Writer) (savedir.Account, error) {
+	fmt.Fprintln(out, "Found several accounts:")
+	for i, account := range accounts {
+		fmt.Fprintf(out, "  %d) %s (last played %s)\n     %s\n", i+1, account.Name(), account.Modified.Format("2006-01-02 15:04"), account.Dir)
+	}
+
+	reader := bufio.NewReader(in)
+	for {
+		fmt.Fprintf(out, "Select an account [1-%d, default 1]: ", len(accounts))
+		line, err := reader.ReadString('\n')
+		line = strings.TrimSpace(line)
+		if line == "" {
+			if err != nil {
+				return savedir.Account{}, fmt.Errorf("no account selected, use --save-dir to pick one of them")
+			}
+			return accounts[0], nil
+		}
+
+		choice, convErr := strconv.Atoi(line)
+		if convErr == nil && choice >= 1 && choice <= len(accounts) {
+			return accounts[choice-1], nil
+		}
+		if err != nil {
+			return savedir.Account{}, fmt.Errorf("invalid account %q", line)
+		}
+		fmt.Fprintf(out, "Invalid choice %q\n", line)
+	}
+}
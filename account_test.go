@@ -0,0 +1,93 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"refinder/savedir"
+	"strings"
+	"testing"
+)
+
+// writeAccounts writes an empty profile in each named subfolder of a new
+// folder of accounts.
+func writeAccounts(t *testing.T, names ...string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for _, name := range names {
+		err := os.Mkdir(filepath.Join(dir, name), 0o755)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = os.WriteFile(filepath.Join(dir, name, profileFileName), nil, 0o644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestFindAccount(t *testing.T) {
+	single := writeAccounts(t, "111")
+	several := writeAccounts(t, "111", "222")
+
+	tests := []struct {
+		name    string
+		saveDir string
+		account string
+		err     string
+	}{
+		{"single account", single, filepath.Join(single, "111"), ""},
+		{"several accounts", several, "", "found 2 accounts, use --save-dir"},
+		{"no account", t.TempDir(), "", "could not find a save folder"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			account, err := findAccount(test.saveDir, false)
+			if test.err != "" {
+				if err == nil || !strings.Contains(err.Error(), test.err) {
+					t.Fatalf("err = %v, want it to contain %q", err, test.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if account.Dir != test.account {
+				t.Errorf("account = %s, want %s", account.Dir, test.account)
+			}
+		})
+	}
+}
+
+func TestChooseAccount(t *testing.T) {
+	accounts := []savedir.Account{{Dir: "first"}, {Dir: "second"}}
+
+	tests := []struct {
+		name    string
+		input   string
+		account string
+		err     bool
+	}{
+		{"default", "\n", "first", false},
+		{"choice", "2\n", "second", false},
+		{"invalid then valid", "9\n2\n", "second", false},
+		{"end of input", "", "", true},
+		{"invalid at end of input", "x", "", true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var out bytes.Buffer
+			account, err := chooseAccount(accounts, strings.NewReader(test.input), &out)
+			if (err != nil) != test.err {
+				t.Fatalf("err = %v, want error %t", err, test.err)
+			}
+			if account.Dir != test.account {
+				t.Errorf("account = %q, want %q", account.Dir, test.account)
+			}
+			if !strings.Contains(out.String(), "Select an account") {
+				t.Errorf("prompt missing from %q", out.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,228 @@
+package main
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// The API is read-only JSON. Every response carries an ETag built from the
+// CRC32 of the save files it was made from, clients that send it back in
+// If-None-Match get 304 Not Modified until the game writes those files.
+
+type apiCharacter struct {
+	CharacterData
+	Active bool `json:"active"`
+}
+
+type apiSearchResult struct {
+	Character   int32  `json:"character"`
+	ZoneID      int32  `json:"zone_id"`
+	Zone        string `json:"zone"`
+	Kind        string `json:"kind"`
+	Event       string `json:"event,omitempty"`
+	Name        string `json:"name"`
+	DisplayName string `json:"display_name"`
+	Quantity    int32  `json:"quantity"`
+	Owned       bool   `json:"owned"`
+}
+
+// etag joins the checksums of the files a response depends on.
+func etag(crcs ...uint32) string {
+	if len(crcs) == 1 {
+		return fmt.Sprintf(`"%08x"`, crcs[0])
+	}
+	data := make([]byte, 4*len(crcs))
+	for i, crc := range crcs {
+		binary.LittleEndian.PutUint32(data[4*i:], crc)
+	}
+	return fmt.Sprintf(`"%08x"`, crc32.ChecksumIEEE(data))
+}
+
+func notModified(r *http.Request, tag string) bool {
+	for _, match := range strings.Split(r.Header.Get("If-None-Match"), ",") {
+		match = strings.TrimPrefix(strings.TrimSpace(match), "W/")
+		if match == tag || match == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+func writeAPIJSON(w http.ResponseWriter, r *http.Request, tag string, value interface{}) {
+	w.Header().Set("ETag", tag)
+	w.Header().Set("Cache-Control", "no-cache")
+	if notModified(r, tag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		log.Println("error:", err)
+		http.Error(w, "could not encode response", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(append(data, '\n'))
+}
+
+// pathCharacter returns the character given by the {id} path segment, must
+// be called with mu held for reading.
+func (s *server) pathCharacter(w http.ResponseWriter, r *http.Request) (CharacterData, bool) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 32)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("invalid character %q", r.PathValue("id")), http.StatusBadRequest)
+		return CharacterData{}, false
+	}
+	characterData, ok := s.session.Characters[int32(id)]
+	if !ok {
+		http.Error(w, fmt.Sprintf("character %d does not exist", id), http.StatusNotFound)
+		return CharacterData{}, false
+	}
+	return characterData, true
+}
+
+func (s *server) handleCharacters(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	characters := []apiCharacter{}
+	for _, characterID := range s.session.CharacterIDs() {
+		characters = append(characters, apiCharacter{
+			CharacterData: s.session.Characters[characterID],
+			Active:        characterID == s.session.ActiveCharacterID,
+		})
+	}
+	writeAPIJSON(w, r, etag(s.session.ProfileCRC), characters)
+}
+
+// handleWorlds returns the adventure and the campaign of a character. Only
+// the configured world is kept in the session, the save is read again unless
+// the client already has the current version.
+func (s *server) handleWorlds(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	characterData, ok := s.pathCharacter(w, r)
+	profileCRC := s.session.ProfileCRC
+	zoneInfo, loaded := s.session.Zones[characterData.ID]
+	path := s.session.Account.Path(saveFileName(characterData.ID))
+	ownedItems := s.session.OwnedItems(characterData.ID)
+	s.mu.RUnlock()
+	if !ok {
+		return
+	}
+
+	if loaded && notModified(r, etag(profileCRC, zoneInfo.CRC)) {
+		w.Header().Set("ETag", etag(profileCRC, zoneInfo.CRC))
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	archive, err := readSaveArchiveFile(path)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+
+	// A character that never started a campaign or an adventure has no
+	// such world, it is left out. Any other error is a save that could not
+	// be parsed.
+	worlds := map[string]ZoneInfo{}
+	for _, world := range []string{WorldAdventure, WorldCampaign} {
+		zoneInfo, err := findWorld(&archive, ownedItems, world)
+		if errors.Is(err, errNoWorld) {
+			continue
+		}
+		if err != nil {
+			log.Printf("error: %s of character %d: %v\n", world, characterData.ID, err)
+			http.Error(w, fmt.Sprintf("could not read the %s: %v", world, err), http.StatusInternalServerError)
+			return
+		}
+		worlds[world] = zoneInfo
+	}
+	writeAPIJSON(w, r, etag(profileCRC, archive.Header.Crc), worlds)
+}
+
+func (s *server) handleInventory(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	characterData, ok := s.pathCharacter(w, r)
+	if !ok {
+		return
+	}
+
+	inventory := []inventoryEntry{}
+	for _, item := range characterData.Inventory {
+		inventory = append(inventory, newInventoryEntry(item))
+	}
+	writeAPIJSON(w, r, etag(s.session.ProfileCRC), inventory)
+}
+
+func searchZone(zone *ZoneActor, characterID int32, query string, results *[]apiSearchResult) {
+	if zone == nil {
+		return
+	}
+
+	matches := func(name string) bool {
+		return strings.Contains(strings.ToLower(name), query) || strings.Contains(strings.ToLower(getPrintableName(name)), query)
+	}
+
+	for _, item := range zone.Items {
+		if matches(item.Name) {
+			kind := "item"
+			if isMaterial(item.Name) {
+				kind = "material"
+			}
+			*results = append(*results, apiSearchResult{
+				Character: characterID, ZoneID: zone.ID, Zone: zone.Label, Kind: kind,
+				Name: item.Name, DisplayName: getPrintableName(item.Name), Quantity: item.Quantity, Owned: item.OwnedByCharacter,
+			})
+		}
+	}
+	for _, event := range zone.Events {
+		for _, reward := range event.Rewards {
+			if matches(reward.ActorBP) {
+				*results = append(*results, apiSearchResult{
+					Character: characterID, ZoneID: zone.ID, Zone: zone.Label, Kind: "reward", Event: event.Name,
+					Name: reward.ActorBP, DisplayName: getPrintableName(reward.ActorBP), Quantity: reward.Quantity, Owned: reward.OwnedByCharacter,
+				})
+			}
+		}
+	}
+
+	for _, child := range zone.Children {
+		searchZone(child, characterID, query, results)
+	}
+}
+
+// handleSearch finds items and rewards by blueprint or display name in the
+// worlds of all characters.
+func (s *server) handleSearch(w http.ResponseWriter, r *http.Request) {
+	query := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("item")))
+	if query == "" {
+		http.Error(w, "missing item parameter", http.StatusBadRequest)
+		return
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	results := []apiSearchResult{}
+	crcs := []uint32{s.session.ProfileCRC}
+	for _, characterID := range s.session.CharacterIDs() {
+		zoneInfo, ok := s.session.Zones[characterID]
+		if !ok {
+			continue
+		}
+		crcs = append(crcs, zoneInfo.CRC)
+		searchZone(zoneInfo.ZoneActor, characterID, query, &results)
+	}
+	writeAPIJSON(w, r, etag(crcs...), results)
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"refinder/remnant"
+	"refinder/savedir"
+	"testing"
+)
+
+func TestHandleWorlds(t *testing.T) {
+	// The character of the account never started the campaign.
+	account := t.TempDir()
+	writeCheckAccount(t, account, "Nerud", nil)
+
+	brokenWorld := t.TempDir()
+	writeCheckAccount(t, brokenWorld, "Nerud", nil)
+	writeTestArchive(t, filepath.Join(brokenWorld, saveFileName(0)), testSaveArchive(remnant.REMNANT_SAVE_GAME,
+		testObject("PersistentLevel",
+			remnant.Property{Name: "Key", Type: "StrProperty", Value: "/Game/Maps/Main.Main:PersistentLevel"},
+			remnant.Property{Name: "Blob", Type: "IntProperty", Value: int32(0)},
+		),
+	))
+
+	tests := []struct {
+		name    string
+		account string
+		path    string
+		status  int
+		worlds  []string
+	}{
+		{"missing campaign", account, "/characters/0/worlds", http.StatusOK, []string{WorldAdventure}},
+		{"unknown character", account, "/characters/3/worlds", http.StatusNotFound, nil},
+		{"invalid character", account, "/characters/x/worlds", http.StatusBadRequest, nil},
+		{"unreadable world", brokenWorld, "/characters/0/worlds", http.StatusInternalServerError, nil},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			session := NewSession(savedir.Account{Dir: test.account}, WorldAdventure)
+			err := session.RefreshProfile()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			recorder := httptest.NewRecorder()
+			newServer(session).routes().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, test.path, nil))
+			if recorder.Code != test.status {
+				t.Fatalf("status = %d, want %d: %s", recorder.Code, test.status, recorder.Body)
+			}
+			if test.status != http.StatusOK {
+				return
+			}
+
+			var worlds map[string]json.RawMessage
+			err = json.Unmarshal(recorder.Body.Bytes(), &worlds)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(worlds) != len(test.worlds) {
+				t.Errorf("worlds = %s, want %v", recorder.Body, test.worlds)
+			}
+			for _, world := range test.worlds {
+				if _, ok := worlds[world]; !ok {
+					t.Errorf("world %s missing from %s", world, recorder.Body)
+				}
+			}
+		})
+	}
+}
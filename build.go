@@ -0,0 +1,102 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+)
+
+type buildReport struct {
+	Character int32  `json:"character"`
+	Archetype string `json:"archetype"`
+	Progression
+}
+
+func printBuild(w io.Writer, report buildReport) error {
+	fmt.Fprintf(w, "Character %d: %s\n\n", report.Character, report.Archetype)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ARCHETYPE\tLEVEL\tEXPERIENCE\t\t")
+	for _, archetype := range report.Archetypes {
+		role := ""
+		if archetype.Primary {
+			role = "(primary)"
+		} else if archetype.Secondary {
+			role = "(secondary)"
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%s\t\n", archetype.Name, archetype.Level, archetype.Experience, role)
+	}
+	err := tw.Flush()
+	if err != nil {
+		return err
+	}
+
+	if perk := report.PrimePerk; perk != nil {
+		switch {
+		case perk.Name == "":
+			fmt.Fprintf(w, "\nPrime perk of %s: not in the catalog\n", perk.Archetype)
+		case perk.Unlocked:
+			fmt.Fprintf(w, "\nPrime perk of %s: %s, level %d\n", perk.Archetype, getItemName(perk.Name), perk.Level)
+		default:
+			fmt.Fprintf(w, "\nPrime perk of %s: %s, locked\n", perk.Archetype, getItemName(perk.Name))
+		}
+	}
+	fmt.Fprintf(w, "Trait points: %d\n", report.TraitPoints)
+	if len(report.Missing) > 0 {
+		fmt.Fprintf(w, "Not in the save: %s\n", strings.Join(report.Missing, ", "))
+	}
+	fmt.Fprintln(w)
+
+	tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "TRAIT\tLEVEL\t\t")
+	for _, trait := range report.Traits {
+		assigned := ""
+		if trait.Assigned {
+			assigned = "(assigned)"
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%s\t\n", getItemName(trait.Name), trait.Level, assigned)
+	}
+	return tw.Flush()
+}
+
+func runBuild(args []string) error {
+	flags := flag.NewFlagSet("build", flag.ExitOnError)
+	format := flags.String("format", "text", "output format (text or json)")
+	characterFlag := flags.Int("character", -1, "ID of the character (default: the active character)")
+	saveDir := addSaveDirFlag(flags)
+	addLangFlag(flags)
+	positional, err := parseCommandArgs(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 0 || *format != "text" && *format != "json" {
+		return fmt.Errorf("usage: %s", commands["build"].Usage)
+	}
+
+	account, err := selectAccount(*saveDir)
+	if err != nil {
+		return err
+	}
+	session := NewSession(account, WorldAdventure)
+	err = session.RefreshProfile()
+	if err != nil {
+		return err
+	}
+	characterIDs, err := session.SelectCharacters(*characterFlag, false)
+	if err != nil {
+		return err
+	}
+
+	characterData := session.Characters[characterIDs[0]]
+	report := buildReport{Character: characterData.ID, Archetype: characterData.Archetype, Progression: characterData.Progression}
+	if *format == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	}
+	return printBuild(os.Stdout, report)
+}
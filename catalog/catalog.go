@@ -0,0 +1,244 @@
+// Package catalog describes the items of Remnant 2 by blueprint class, the
+// name the save files use for them (like Weapon_Nightweed_C).
+//
+// The catalog shipped with ReFinder is embedded from items.json. A user file
+// in the same format, or CSV with the columns
+//
+//	class,name,category,subcategory,dlc,biome,related
+//
+// adds entries or overrides fields of shipped ones, related classes are
+// separated by ";" in CSV. Classes the catalog does not know are described
+// from their class name, see derive.go.
+package catalog
+
+import (
+	_ "embed"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// EnvVar names a catalog file that overrides the shipped catalog.
+const EnvVar = "REFINDER_CATALOG"
+
+// Item categories. Events are quests and points of interest, the rest can
+// be owned by a character.
+const (
+	Weapon      = "Weapon"
+	Armor       = "Armor"
+	Ring        = "Ring"
+	Amulet      = "Amulet"
+	Mod         = "Mod"
+	Mutator     = "Mutator"
+	Relic       = "Relic"
+	Trait       = "Trait"
+	Perk        = "Perk"
+	Archetype   = "Archetype"
+	Consumable  = "Consumable"
+	Material    = "Material"
+	Currency    = "Currency"
+	Event       = "Event"
+	Uncataloged = ""
+)
+
+//go:embed items.json
+var itemsJSON []byte
+
+type Item struct {
+	Class       string `json:"class"`
+	Name        string `json:"name"`
+	Category    string `json:"category"`
+	Subcategory string `json:"subcategory,omitempty"`
+	DLC         string `json:"dlc,omitempty"`
+	// Biome is the internal name of the biome the item is found in, like
+	// Jungle, empty for items found in several.
+	Biome   string   `json:"biome,omitempty"`
+	Related []string `json:"related,omitempty"`
+}
+
+// Tag returns what ReFinder prints in braces before the name: the category,
+// or the kind of event. Materials and currencies have none.
+func (item Item) Tag() string {
+	switch item.Category {
+	case Event:
+		return item.Subcategory
+	case Material, Currency, Uncataloged:
+		return ""
+	}
+	return item.Category
+}
+
+// String returns the name the way ReFinder prints it.
+func (item Item) String() string {
+	if item.Tag() == "" {
+		return item.Name
+	}
+	return fmt.Sprintf("{%s} %s", item.Tag(), item.Name)
+}
+
+type Catalog struct {
+	items map[string]Item
+}
+
+// Default returns the catalog shipped with ReFinder.
+func Default() *Catalog {
+	var items []Item
+	err := json.Unmarshal(itemsJSON, &items)
+	if err != nil {
+		panic(fmt.Sprintf("catalog: invalid items.json: %v", err))
+	}
+
+	c := &Catalog{items: map[string]Item{}}
+	for _, item := range items {
+		c.items[item.Class] = item
+	}
+	return c
+}
+
+// Lookup returns the entry of a class, classes that are not in the catalog
+// are derived from the class name.
+func (c *Catalog) Lookup(class string) Item {
+	if item, ok := c.items[class]; ok {
+		return item
+	}
+	return derive(class)
+}
+
+// Items returns every cataloged item ordered by class.
+func (c *Catalog) Items() []Item {
+	items := make([]Item, 0, len(c.items))
+	for _, item := range c.items {
+		items = append(items, item)
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].Class < items[j].Class })
+	return items
+}
+
+// Load merges a JSON or CSV catalog file into the catalog. Fields a user
+// entry leaves empty keep the value of the existing entry.
+func (c *Catalog) Load(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var items []Item
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		items, err = readCSV(f)
+	} else {
+		err = json.NewDecoder(f).Decode(&items)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	for i, item := range items {
+		if item.Class == "" {
+			return fmt.Errorf("%s: entry %d has no class", path, i+1)
+		}
+	}
+	for _, item := range items {
+		c.merge(item)
+	}
+	return nil
+}
+
+func (c *Catalog) merge(item Item) {
+	existing, ok := c.items[item.Class]
+	if !ok {
+		existing = derive(item.Class)
+	}
+	if item.Name != "" {
+		existing.Name = item.Name
+	}
+	if item.Category != "" {
+		existing.Category = item.Category
+	}
+	if item.Subcategory != "" {
+		existing.Subcategory = item.Subcategory
+	}
+	if item.DLC != "" {
+		existing.DLC = item.DLC
+	}
+	if item.Biome != "" {
+		existing.Biome = item.Biome
+	}
+	if item.Related != nil {
+		existing.Related = item.Related
+	}
+	c.items[item.Class] = existing
+}
+
+var csvColumns = []string{"class", "name", "category", "subcategory", "dlc", "biome", "related"}
+
+func readCSV(r io.Reader) ([]Item, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	columns := map[string]int{}
+	for i, column := range records[0] {
+		columns[strings.ToLower(strings.TrimSpace(column))] = i
+	}
+	if _, ok := columns["class"]; !ok {
+		return nil, fmt.Errorf("missing class column (columns: %s)", strings.Join(csvColumns, ","))
+	}
+
+	field := func(record []string, column string) string {
+		i, ok := columns[column]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	var items []Item
+	for _, record := range records[1:] {
+		item := Item{
+			Class:       field(record, "class"),
+			Name:        field(record, "name"),
+			Category:    field(record, "category"),
+			Subcategory: field(record, "subcategory"),
+			DLC:         field(record, "dlc"),
+			Biome:       field(record, "biome"),
+		}
+		if related := field(record, "related"); related != "" {
+			item.Related = strings.Split(related, ";")
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// UserFile returns the catalog file of the user: the file named by EnvVar,
+// or catalog.json or catalog.csv in the refinder config folder. It returns
+// "" when there is none.
+func UserFile() string {
+	if path := os.Getenv(EnvVar); path != "" {
+		return path
+	}
+
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	for _, name := range []string{"catalog.json", "catalog.csv"} {
+		path := filepath.Join(configDir, "refinder", name)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return ""
+}
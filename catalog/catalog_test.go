@@ -0,0 +1,135 @@
+package catalog
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDefault(t *testing.T) {
+	seen := map[string]bool{}
+	for _, item := range Default().Items() {
+		if item.Class == "" || item.Name == "" || item.Category == "" {
+			t.Errorf("incomplete entry %+v", item)
+		}
+		if seen[item.Class] {
+			t.Errorf("duplicate entry %s", item.Class)
+		}
+		seen[item.Class] = true
+	}
+}
+
+func writeCatalog(t *testing.T, name, data string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	err := os.WriteFile(path, []byte(data), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	nightfall := Default().Lookup("Weapon_Nightfall_C")
+
+	tests := []struct {
+		name  string
+		file  string
+		data  string
+		class string
+		item  Item
+	}{
+		{"rename keeps the other fields", "catalog.json", `[{"class": "Weapon_Nightfall_C", "name": "Nachtfall"}]`, "Weapon_Nightfall_C",
+			Item{Class: "Weapon_Nightfall_C", Name: "Nachtfall", Category: nightfall.Category, Subcategory: nightfall.Subcategory, DLC: nightfall.DLC, Biome: nightfall.Biome, Related: nightfall.Related}},
+		{"override every field", "catalog.json", `[{"class": "Weapon_Nightfall_C", "name": "N", "category": "Relic", "subcategory": "S", "dlc": "D", "biome": "Jungle", "related": ["Mod_X_C"]}]`, "Weapon_Nightfall_C",
+			Item{Class: "Weapon_Nightfall_C", Name: "N", Category: Relic, Subcategory: "S", DLC: "D", Biome: "Jungle", Related: []string{"Mod_X_C"}}},
+		{"new class is derived first", "catalog.json", `[{"class": "Ring_BrandNew_C", "biome": "Nerud"}]`, "Ring_BrandNew_C",
+			Item{Class: "Ring_BrandNew_C", Name: "Brand New", Category: Ring, Biome: "Nerud"}},
+		{"csv", "catalog.csv", "class,name,category,related\nAmulet_Csv_C,From CSV,Amulet,Ring_A_C;Ring_B_C\n", "Amulet_Csv_C",
+			Item{Class: "Amulet_Csv_C", Name: "From CSV", Category: Amulet, Related: []string{"Ring_A_C", "Ring_B_C"}}},
+		{"csv columns in any order and case", "catalog.CSV", " Biome , CLASS \nFae,Weapon_Nightfall_C\n", "Weapon_Nightfall_C",
+			Item{Class: "Weapon_Nightfall_C", Name: nightfall.Name, Category: nightfall.Category, Subcategory: nightfall.Subcategory, DLC: nightfall.DLC, Biome: "Fae", Related: nightfall.Related}},
+		{"csv short row", "catalog.csv", "class,name,category\nMod_Short_C,Short\n", "Mod_Short_C",
+			Item{Class: "Mod_Short_C", Name: "Short", Category: Mod}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := Default()
+			err := c.Load(writeCatalog(t, test.file, test.data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if item := c.Lookup(test.class); !reflect.DeepEqual(item, test.item) {
+				t.Errorf("Lookup(%s) = %+v, want %+v", test.class, item, test.item)
+			}
+		})
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		data string
+		err  string
+	}{
+		{"invalid JSON", "catalog.json", `[{"class": }]`, "invalid character"},
+		{"entry without class", "catalog.json", `[{"class": "Ring_A_C", "name": "A"}, {"name": "B"}]`, "entry 2 has no class"},
+		{"csv without class column", "catalog.csv", "name,category\nA,Ring\n", "missing class column"},
+		{"csv row without class", "catalog.csv", "class,name\n,A\n", "entry 1 has no class"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := Default()
+			err := c.Load(writeCatalog(t, test.file, test.data))
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Fatalf("err = %v, want it to contain %q", err, test.err)
+			}
+			// A file with an error is not merged at all.
+			if item := c.Lookup("Ring_A_C"); item.Name != derive("Ring_A_C").Name {
+				t.Errorf("Ring_A_C was merged: %+v", item)
+			}
+		})
+	}
+
+	err := Default().Load(filepath.Join(t.TempDir(), "missing.json"))
+	if err == nil {
+		t.Error("no error for a missing file")
+	}
+}
+
+func TestDerive(t *testing.T) {
+	tests := []struct {
+		class string
+		item  Item
+	}{
+		{"Weapon_SMG_C", Item{Class: "Weapon_SMG_C", Name: "SMG", Category: Weapon}},
+		{"Weapon_XMG57Bonesaw_C", Item{Class: "Weapon_XMG57Bonesaw_C", Name: "XMG57 Bonesaw", Category: Weapon}},
+		{"Ring_BandOfStrength_C", Item{Class: "Ring_BandOfStrength_C", Name: "Band Of Strength", Category: Ring}},
+		{"Perk_DeadToRights_C", Item{Class: "Perk_DeadToRights_C", Name: "Dead To Rights", Category: Perk}},
+		{"Material_LumeniteCrystal_C", Item{Class: "Material_LumeniteCrystal_C", Name: "Lumenite Crystal", Category: Material}},
+		{"Quest_Miniboss_BloatKing_C", Item{Class: "Quest_Miniboss_BloatKing_C", Name: "Bloat King", Category: Event, Subcategory: "Miniboss"}},
+		{"NotAClass", Item{Class: "NotAClass", Name: "NotAClass"}},
+	}
+	for _, test := range tests {
+		if item := derive(test.class); !reflect.DeepEqual(item, test.item) {
+			t.Errorf("derive(%s) = %+v, want %+v", test.class, item, test.item)
+		}
+	}
+}
+
+// TestDeriveShipped keeps derive in line with the perk classes of the
+// shipped catalog, a perk missing from a user catalog falls back to derive.
+func TestDeriveShipped(t *testing.T) {
+	for _, item := range Default().Items() {
+		if item.Category != Perk {
+			continue
+		}
+		derived := derive(item.Class)
+		if derived.Category != item.Category || !strings.EqualFold(derived.Name, item.Name) {
+			t.Errorf("derive(%s) = %s %q, want %s %q", item.Class, derived.Category, derived.Name, item.Category, item.Name)
+		}
+	}
+}
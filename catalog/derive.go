@@ -0,0 +1,84 @@
+package catalog
+
+import (
+	"strings"
+	"unicode"
+)
+
+// splitByCapital turns CamelCase into separate words. Runs of capitals are
+// kept together as an acronym, so SMG stays SMG and XMG57Bonesaw becomes
+// XMG57 Bonesaw.
+func splitByCapital(str string) string {
+	runes := []rune(str)
+	var words strings.Builder
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) {
+			previous := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(previous) || nextLower && (unicode.IsUpper(previous) || unicode.IsDigit(previous)) {
+				words.WriteRune(' ')
+			}
+		}
+		words.WriteRune(r)
+	}
+	return strings.TrimSpace(words.String())
+}
+
+// eventKinds are the quest class prefixes that name a kind of event.
+var eventKinds = map[string]string{
+	"Story":         "Story",
+	"WorldBoss":     "World Boss",
+	"OverworldBoss": "Overworld Boss",
+	"Boss":          "Boss",
+	"Injectable":    "Injectable",
+	"SideD":         "Side Dungeon",
+	"OverworldPOI":  "Point of Interest",
+	"Miniboss":      "Miniboss",
+}
+
+// derive describes a class the catalog does not know from its name. Names
+// that are not blueprint classes are kept as they are.
+func derive(class string) Item {
+	item := Item{Class: class, Name: class}
+	if !strings.HasSuffix(class, "_C") {
+		return item
+	}
+	name := strings.TrimSuffix(class, "_C")
+
+	switch {
+	case strings.HasPrefix(name, "Material_"):
+		item.Category = Material
+		item.Name = splitByCapital(strings.TrimPrefix(name, "Material_"))
+
+	case strings.HasPrefix(name, "Amulet_"), strings.HasPrefix(name, "Armor_"), strings.HasPrefix(name, "Ring_"), strings.HasPrefix(name, "Weapon_"),
+		strings.HasPrefix(name, "Mod_"), strings.HasPrefix(name, "Mutator_"), strings.HasPrefix(name, "Relic_"), strings.HasPrefix(name, "Trait_"):
+		splitted := strings.Split(name, "_")
+		item.Category = splitted[0]
+		item.Name = splitByCapital(strings.Join(splitted[1:], ""))
+
+	case strings.HasPrefix(name, "Perk_"):
+		// Perks are named without their archetype, like Perk_DeadToRights.
+		item.Category = Perk
+		item.Name = splitByCapital(strings.TrimPrefix(name, "Perk_"))
+
+	case strings.HasPrefix(name, "Item_HiddenContainer_Material_Engram_"):
+		item.Category = Archetype
+		item.Subcategory = "Engram"
+		item.Name = splitByCapital(strings.TrimPrefix(name, "Item_HiddenContainer_Material_Engram_"))
+
+	case strings.HasPrefix(name, "Quest_"):
+		splitted := strings.Split(strings.TrimPrefix(name, "Quest_"), "_")
+		item.Category = Event
+		if kind, ok := eventKinds[splitted[0]]; ok && len(splitted) > 1 {
+			item.Subcategory = kind
+			splitted = splitted[1:]
+		}
+		item.Name = splitByCapital(strings.Join(splitted, ""))
+
+	case strings.HasPrefix(name, "GemContainer_"):
+		item.Category = Currency
+		item.Name = splitByCapital(strings.TrimPrefix(name, "GemContainer_"))
+	}
+
+	return item
+}
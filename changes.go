@@ -0,0 +1,176 @@
+package main
+
+import "fmt"
+
+type ChangeKind int
+
+const (
+	Unchanged ChangeKind = iota
+	ChangeAdded
+	ChangeModified
+)
+
+// maxChangeLines caps the detailed lines of the summary, a freshly rolled
+// world would otherwise list every zone.
+const maxChangeLines = 10
+
+// WorldChanges is what changed in a character's world since the previous
+// save was read. Zones, items, events and rewards are marked by key so the
+// renderer can highlight them, removed entries only appear in the summary.
+type WorldChanges struct {
+	Summary []string
+	marks   map[string]ChangeKind
+}
+
+// Mark returns how the entry with the given key changed, nil changes mark
+// nothing.
+func (c *WorldChanges) Mark(key string) ChangeKind {
+	if c == nil {
+		return Unchanged
+	}
+	return c.marks[key]
+}
+
+func zoneChangeKey(zone *ZoneActor) string {
+	return fmt.Sprintf("zone/%d", zone.ID)
+}
+
+// itemChangeKey tells items apart by the ID of their actor, a zone can hold
+// several items of the same class.
+func itemChangeKey(zone *ZoneActor, item ItemData) string {
+	return fmt.Sprintf("item/%d/%d/%s", zone.ID, item.Properties.ID, item.Name)
+}
+
+func eventChangeKey(zone *ZoneActor, event Event) string {
+	return fmt.Sprintf("event/%d/%s", zone.ID, event.Name)
+}
+
+// rewardChangeKey tells rewards apart by their persistent ID, an event can
+// reward the same class more than once.
+func rewardChangeKey(zone *ZoneActor, event Event, reward LootSpawn) string {
+	return fmt.Sprintf("reward/%d/%s/%d/%s", zone.ID, event.Name, reward.PersistenceKey.PersistentID, reward.ActorBP)
+}
+
+// worldEntry is a zone, item, event or reward flattened out of the tree.
+type worldEntry struct {
+	kind     string
+	name     string
+	zone     string
+	quantity int32
+	owned    bool
+	hasOwner bool
+	status   EventStatus
+}
+
+func flattenWorld(zone *ZoneActor, entries map[string]worldEntry, keys *[]string) {
+	if zone == nil {
+		return
+	}
+
+	add := func(key string, entry worldEntry) {
+		if _, ok := entries[key]; !ok {
+			*keys = append(*keys, key)
+		}
+		entries[key] = entry
+	}
+
+	add(zoneChangeKey(zone), worldEntry{kind: "zone", name: zone.Label})
+	for _, item := range zone.Items {
+		add(itemChangeKey(zone, item), worldEntry{
+			kind:     "item",
+			name:     getPrintableName(item.Name),
+			zone:     zone.Label,
+			quantity: item.Quantity,
+			owned:    item.OwnedByCharacter,
+			hasOwner: !isMaterial(item.Name),
+		})
+	}
+	for _, event := range zone.Events {
+		add(eventChangeKey(zone, event), worldEntry{kind: "event", name: getPrintableName(event.Name), zone: zone.Label, status: event.Status})
+		for _, reward := range event.Rewards {
+			add(rewardChangeKey(zone, event, reward), worldEntry{
+				kind:     "reward",
+				name:     getPrintableName(reward.ActorBP),
+				zone:     zone.Label,
+				quantity: reward.Quantity,
+				owned:    reward.OwnedByCharacter,
+				hasOwner: true,
+			})
+		}
+	}
+
+	for _, child := range zone.Children {
+		flattenWorld(child, entries, keys)
+	}
+}
+
+func (entry worldEntry) String() string {
+	if entry.zone == "" {
+		return fmt.Sprintf("%s %s", entry.kind, entry.name)
+	}
+	return fmt.Sprintf("%s %s (%s)", entry.kind, entry.name, entry.zone)
+}
+
+// diffWorlds compares the previous and the current world of a character.
+func diffWorlds(previous, current ZoneInfo) *WorldChanges {
+	changes := &WorldChanges{marks: map[string]ChangeKind{}}
+	var details []string
+
+	if previous.Biome != current.Biome {
+		details = append(details, fmt.Sprintf("Biome: %s -> %s", getBiomeName(previous.Biome), getBiomeName(current.Biome)))
+	}
+	if previous.BloodMoon != current.BloodMoon {
+		details = append(details, fmt.Sprintf("Blood Moon: %v -> %v", previous.BloodMoon, current.BloodMoon))
+	}
+
+	previousEntries, currentEntries := map[string]worldEntry{}, map[string]worldEntry{}
+	var previousKeys, currentKeys []string
+	flattenWorld(previous.ZoneActor, previousEntries, &previousKeys)
+	flattenWorld(current.ZoneActor, currentEntries, &currentKeys)
+
+	var added, removed, owned, status, modified int
+	for _, key := range currentKeys {
+		entry := currentEntries[key]
+		previousEntry, ok := previousEntries[key]
+		switch {
+		case !ok:
+			added++
+			changes.marks[key] = ChangeAdded
+			details = append(details, "+ "+entry.String())
+		case entry.hasOwner && entry.owned != previousEntry.owned:
+			owned++
+			changes.marks[key] = ChangeModified
+			if entry.owned {
+				details = append(details, "now owned: "+entry.String())
+			} else {
+				details = append(details, "no longer owned: "+entry.String())
+			}
+		case entry.status != previousEntry.status:
+			status++
+			changes.marks[key] = ChangeModified
+			details = append(details, fmt.Sprintf("~ %s %s -> %s", entry, previousEntry.status, entry.status))
+		case entry.quantity != previousEntry.quantity:
+			modified++
+			changes.marks[key] = ChangeModified
+			details = append(details, fmt.Sprintf("~ %s x%d -> x%d", entry, previousEntry.quantity, entry.quantity))
+		}
+	}
+	for _, key := range previousKeys {
+		if _, ok := currentEntries[key]; !ok {
+			removed++
+			details = append(details, "- "+previousEntries[key].String())
+		}
+	}
+
+	if len(details) == 0 {
+		return changes
+	}
+
+	changes.Summary = append(changes.Summary, fmt.Sprintf("Changes since last save: %d added, %d removed, %d ownership, %d status, %d quantity", added, removed, owned, status, modified))
+	if len(details) > maxChangeLines {
+		details = append(details[:maxChangeLines], fmt.Sprintf("... and %d more", len(details)-maxChangeLines))
+	}
+	changes.Summary = append(changes.Summary, details...)
+
+	return changes
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDiffWorlds(t *testing.T) {
+	item := func(id int32, name string, owned bool) ItemData {
+		return ItemData{Name: name, Properties: ItemProperties{ID: id}, OwnedByCharacter: owned, Quantity: 1}
+	}
+	world := func(items []ItemData, status EventStatus, rewardOwned bool) ZoneInfo {
+		return ZoneInfo{Biome: "Fae", ZoneActor: &ZoneActor{ID: 1, Label: "Zone", Items: items, Events: []Event{{
+			Name:    "Quest_Boss_Unknown_C",
+			Status:  status,
+			Rewards: []LootSpawn{{ActorBP: "Ring_Reward_C", Quantity: 1, OwnedByCharacter: rewardOwned}},
+		}}}}
+	}
+	zone := &ZoneActor{ID: 1}
+	boss := Event{Name: "Quest_Boss_Unknown_C"}
+	reward := LootSpawn{ActorBP: "Ring_Reward_C"}
+	first, second := item(10, "Ring_Twin_C", false), item(11, "Ring_Twin_C", false)
+
+	tests := []struct {
+		name     string
+		previous ZoneInfo
+		current  ZoneInfo
+		marks    map[string]ChangeKind
+		summary  []string
+	}{
+		{"unchanged", world([]ItemData{first, second}, EventNotStarted, false), world([]ItemData{first, second}, EventNotStarted, false),
+			map[string]ChangeKind{}, nil},
+		{"one of two identical items removed", world([]ItemData{first, second}, EventNotStarted, false), world([]ItemData{first}, EventNotStarted, false),
+			map[string]ChangeKind{itemChangeKey(zone, first): Unchanged},
+			[]string{"1 removed", "- item " + getPrintableName("Ring_Twin_C")}},
+		{"item added", world([]ItemData{first}, EventNotStarted, false), world([]ItemData{first, second}, EventNotStarted, false),
+			map[string]ChangeKind{itemChangeKey(zone, first): Unchanged, itemChangeKey(zone, second): ChangeAdded},
+			[]string{"1 added"}},
+		{"item picked up", world([]ItemData{first}, EventNotStarted, false), world([]ItemData{item(10, "Ring_Twin_C", true)}, EventNotStarted, false),
+			map[string]ChangeKind{itemChangeKey(zone, first): ChangeModified},
+			[]string{"1 ownership", "now owned: item"}},
+		{"event completed", world(nil, EventNotStarted, false), world(nil, EventCompleted, true),
+			map[string]ChangeKind{eventChangeKey(zone, boss): ChangeModified, rewardChangeKey(zone, boss, reward): ChangeModified},
+			[]string{"1 status", "1 ownership", "not started -> completed"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			changes := diffWorlds(test.previous, test.current)
+			for key, kind := range test.marks {
+				if mark := changes.Mark(key); mark != kind {
+					t.Errorf("Mark(%s) = %d, want %d", key, mark, kind)
+				}
+			}
+			summary := strings.Join(changes.Summary, "\n")
+			if test.summary == nil && summary != "" {
+				t.Errorf("summary = %q, want none", summary)
+			}
+			for _, want := range test.summary {
+				if !strings.Contains(summary, want) {
+					t.Errorf("summary %q does not contain %q", summary, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"refinder/remnant"
+	"text/tabwriter"
+	"time"
+)
+
+// Unreal keeps Timespan and DateTime values as ticks of 100ns, DateTime
+// counts them from 0001-01-01.
+const (
+	ticksPerSecond   = 10_000_000
+	unixEpochInTicks = 621_355_968_000_000_000
+)
+
+// ticks reads a Timespan or DateTime struct of the given name.
+func (r *componentReader) ticks(name, structName string) int64 {
+	if property, ok := r.properties[name].(remnant.StructProperty); ok && property.Name == structName {
+		if ticks, ok := property.Value.(int64); ok {
+			return ticks
+		}
+	}
+	r.missing = append(r.missing, name)
+	return 0
+}
+
+// ticksToDuration converts the ticks of a Timespan.
+func ticksToDuration(ticks int64) time.Duration {
+	return time.Duration(ticks) * (time.Second / ticksPerSecond)
+}
+
+// ticksToTime converts the ticks of a DateTime, which is in UTC.
+func ticksToTime(ticks int64) time.Time {
+	ticks -= unixEpochInTicks
+	return time.Unix(ticks/ticksPerSecond, ticks%ticksPerSecond*100).UTC()
+}
+
+// readCharacterStats reads the power level, play time and save time of a
+// SavedCharacter. Stats it does not have are left at zero and listed in
+// MissingStats.
+func readCharacterStats(characterData *CharacterData, properties map[string]interface{}) {
+	r := componentReader{properties: properties}
+	characterData.PowerLevel = r.int("PowerLevel")
+	if ticks := r.ticks("PlayTime", "Timespan"); ticks > 0 {
+		characterData.PlayTime = ticksToDuration(ticks)
+	}
+	if ticks := r.ticks("LastSaved", "DateTime"); ticks > unixEpochInTicks {
+		characterData.LastSaved = ticksToTime(ticks)
+	}
+	characterData.MissingStats = r.missing
+}
+
+func formatPlayTime(playTime time.Duration) string {
+	if playTime <= 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%dh %02dm", int(playTime.Hours()), int(playTime.Minutes())%60)
+}
+
+type characterSummary struct {
+	ID         int32     `json:"id"`
+	Archetype  string    `json:"archetype"`
+	Type       string    `json:"type"`
+	PowerLevel int32     `json:"power_level"`
+	PlayTime   int64     `json:"playtime_seconds"`
+	Biome      string    `json:"biome"`
+	LastSaved  time.Time `json:"last_saved"`
+	Active     bool      `json:"active"`
+	// Missing lists the stats the save does not record.
+	Missing []string `json:"missing,omitempty"`
+}
+
+func runCharacters(args []string) error {
+	flags := flag.NewFlagSet("characters", flag.ExitOnError)
+	format := flags.String("format", "text", "output format (text or json)")
+	saveDir := addSaveDirFlag(flags)
+	world := addWorldFlag(flags)
+	addLangFlag(flags)
+	positional, err := parseCommandArgs(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 0 || *format != "text" && *format != "json" {
+		return fmt.Errorf("usage: %s", commands["characters"].Usage)
+	}
+
+	account, err := selectAccount(*saveDir)
+	if err != nil {
+		return err
+	}
+	session := NewSession(account, *world)
+	err = session.RefreshProfile()
+	if err != nil {
+		return err
+	}
+
+	summaries := []characterSummary{}
+	for _, characterID := range session.CharacterIDs() {
+		characterData := session.Characters[characterID]
+		summary := characterSummary{
+			ID:         characterData.ID,
+			Archetype:  characterData.Archetype,
+			Type:       getCharacterTypeName(characterData.Type),
+			PowerLevel: characterData.PowerLevel,
+			PlayTime:   int64(characterData.PlayTime.Seconds()),
+			LastSaved:  characterData.LastSaved,
+			Active:     characterID == session.ActiveCharacterID,
+			Missing:    characterData.MissingStats,
+		}
+		// Characters without a world yet have no biome.
+		_, err := session.RefreshCharacter(characterID)
+		if err != nil && !errors.Is(err, errNoWorld) {
+			return fmt.Errorf("character %d: %w", characterID, err)
+		}
+		if err == nil {
+			summary.Biome = getBiomeName(session.Zones[characterID].Biome)
+		}
+		if summary.LastSaved.IsZero() {
+			info, err := os.Stat(account.Path(saveFileName(characterID)))
+			if err == nil {
+				summary.LastSaved = info.ModTime()
+			}
+		}
+		summaries = append(summaries, summary)
+	}
+
+	if *format == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(summaries)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "\tID\tARCHETYPE\tTYPE\tPOWER\tPLAYTIME\tBIOME\tLAST SAVED\t")
+	for _, summary := range summaries {
+		active, power, biome, lastSaved := "", "-", "-", "-"
+		if summary.Active {
+			active = "*"
+		}
+		if summary.PowerLevel > 0 {
+			power = fmt.Sprint(summary.PowerLevel)
+		}
+		if summary.Biome != "" {
+			biome = summary.Biome
+		}
+		if !summary.LastSaved.IsZero() {
+			lastSaved = summary.LastSaved.Local().Format("2006-01-02 15:04")
+		}
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\t%s\t%s\t%s\t%s\t\n", active, summary.ID, summary.Archetype, summary.Type, power,
+			formatPlayTime(time.Duration(summary.PlayTime)*time.Second), biome, lastSaved)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"refinder/remnant"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTicks(t *testing.T) {
+	// 2024-05-01 12:30:15.5 UTC and 12h 34m 56s.
+	lastSaved := int64(638501634155000000)
+	if saved, want := ticksToTime(lastSaved), time.Date(2024, 5, 1, 12, 30, 15, 500_000_000, time.UTC); !saved.Equal(want) {
+		t.Errorf("ticksToTime = %s, want %s", saved, want)
+	}
+	if saved := ticksToTime(unixEpochInTicks); !saved.Equal(time.Unix(0, 0)) {
+		t.Errorf("ticksToTime of the Unix epoch = %s", saved)
+	}
+	if playTime, want := ticksToDuration(452_960_000_000), 12*time.Hour+34*time.Minute+56*time.Second; playTime != want {
+		t.Errorf("ticksToDuration = %s, want %s", playTime, want)
+	}
+}
+
+func TestReadCharacterStats(t *testing.T) {
+	timespan := remnant.StructProperty{Name: "Timespan", Value: int64(452_960_000_000)}
+	dateTime := remnant.StructProperty{Name: "DateTime", Value: int64(638501634155000000)}
+	tests := []struct {
+		name       string
+		properties map[string]interface{}
+		want       CharacterData
+	}{
+		{"all stats", map[string]interface{}{"PowerLevel": int32(12), "PlayTime": timespan, "LastSaved": dateTime},
+			CharacterData{PowerLevel: 12, PlayTime: 12*time.Hour + 34*time.Minute + 56*time.Second, LastSaved: time.Date(2024, 5, 1, 12, 30, 15, 500_000_000, time.UTC)}},
+		// Structs under other names or of the other type are not taken.
+		{"other names", map[string]interface{}{"ItemLevel": int32(12), "TimePlayed": timespan, "SaveDateTime": dateTime, "LastSaved": timespan},
+			CharacterData{MissingStats: []string{"PowerLevel", "PlayTime", "LastSaved"}}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var characterData CharacterData
+			readCharacterStats(&characterData, test.properties)
+			if !reflect.DeepEqual(characterData, test.want) {
+				t.Errorf("readCharacterStats = %+v, want %+v", characterData, test.want)
+			}
+		})
+	}
+}
+
+func TestRunCharactersBrokenWorld(t *testing.T) {
+	// A world that can not be parsed is an error, not a row without biome.
+	err := runCharacters([]string{"--save-dir", writeBrokenWorld(t)})
+	if err == nil || !strings.Contains(err.Error(), "character 0") {
+		t.Errorf("runCharacters = %v, want the error of character 0", err)
+	}
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"path"
+	"strings"
+)
+
+// check exits 0 when every condition holds for the world of a character, 1
+// when one does not and 2 when the save could not be read, so scripts can
+// branch on a roll without parsing output.
+const (
+	checkFailed = 1
+	checkError  = 2
+)
+
+type checkResult struct {
+	Condition string
+	OK        bool
+	Found     []string
+}
+
+// stripCategory removes the {Category} prefix of a printable name.
+func stripCategory(name string) string {
+	if strings.HasPrefix(name, "{") {
+		if i := strings.Index(name, "} "); i >= 0 {
+			return name[i+2:]
+		}
+	}
+	return name
+}
+
+// nameMatches matches a pattern against a blueprint name as a glob, or
+// against its printable name ignoring case and the category.
+func nameMatches(pattern, name string) bool {
+	if globMatch(pattern, name) {
+		return true
+	}
+	printableName := getPrintableName(name)
+	return strings.EqualFold(pattern, printableName) || strings.EqualFold(pattern, stripCategory(printableName))
+}
+
+// findNamed collects the items and rewards, or the events, of the zone tree
+// that match a pattern.
+func findNamed(zone *ZoneActor, pattern string, events bool, found *[]string) {
+	if zone == nil {
+		return
+	}
+
+	for _, item := range zone.Items {
+		if !events && nameMatches(pattern, item.Name) {
+			*found = append(*found, fmt.Sprintf("%s in %s", getPrintableName(item.Name), zone.Label))
+		}
+	}
+	for _, event := range zone.Events {
+		if events && nameMatches(pattern, event.Name) {
+			*found = append(*found, fmt.Sprintf("%s in %s", getPrintableName(event.Name), zone.Label))
+		}
+		for _, reward := range event.Rewards {
+			if !events && nameMatches(pattern, reward.ActorBP) {
+				*found = append(*found, fmt.Sprintf("%s from %s in %s", getPrintableName(reward.ActorBP), getPrintableName(event.Name), zone.Label))
+			}
+		}
+	}
+
+	for _, child := range zone.Children {
+		findNamed(child, pattern, events, found)
+	}
+}
+
+func runCheck(args []string) error {
+	flags := flag.NewFlagSet("check", flag.ContinueOnError)
+	var items, events []string
+	addPattern := func(patterns *[]string) func(string) error {
+		return func(value string) error {
+			if _, err := path.Match(value, ""); err != nil {
+				return fmt.Errorf("invalid pattern %q", value)
+			}
+			*patterns = append(*patterns, value)
+			return nil
+		}
+	}
+	flags.Func("has-item", "item or reward that must be in the world, blueprint glob or name (repeatable)", addPattern(&items))
+	flags.Func("has-event", "event that must be in the world, blueprint glob or name (repeatable)", addPattern(&events))
+	biome := flags.String("biome", "", "biome the world must have, internal or in-game name")
+	bloodMoon := flags.Bool("bloodmoon", false, "the world must have a Blood Moon (--bloodmoon=false: must not)")
+	characterFlag := flags.Int("character", -1, "ID of the character to check (default: the active character)")
+	verbose := flags.Bool("v", false, "print every condition and what matched it")
+	saveDir := addSaveDirFlag(flags)
+	addLangFlag(flags)
+	world := addWorldFlag(flags)
+	// The flag set already printed the parse error or the -h usage, both
+	// exit 2 so -h is never taken for a passing check. A broken catalog or
+	// REFINDER_LANG exits 2 too and is only printed with -v.
+	positional, err := parseCommandArgs(flags, args)
+	var settingsErr *settingsError
+	if errors.As(err, &settingsErr) && *verbose {
+		return &exitError{Code: checkError, Err: err}
+	}
+	if err != nil {
+		return &exitError{Code: checkError}
+	}
+
+	// Without -v nothing is printed, not even the notes of the parser.
+	if !*verbose {
+		defer log.SetOutput(log.Writer())
+		log.SetOutput(io.Discard)
+	}
+
+	// Errors are only printed with -v, the exit code tells them apart.
+	checkErr := func(err error) error {
+		if !*verbose {
+			err = nil
+		}
+		return &exitError{Code: checkError, Err: err}
+	}
+
+	bloodMoonSet := false
+	flags.Visit(func(f *flag.Flag) {
+		if f.Name == "bloodmoon" {
+			bloodMoonSet = true
+		}
+	})
+	if len(positional) != 0 || len(items) == 0 && len(events) == 0 && *biome == "" && !bloodMoonSet {
+		return checkErr(fmt.Errorf("usage: %s", commands["check"].Usage))
+	}
+
+	// check never prompts for an account, there is no one to answer.
+	account, err := findAccount(*saveDir, false)
+	if err != nil {
+		return checkErr(err)
+	}
+	session := NewSession(account, *world)
+	err = session.RefreshProfile()
+	if err != nil {
+		return checkErr(err)
+	}
+	characterIDs, err := session.SelectCharacters(*characterFlag, false)
+	if err != nil {
+		return checkErr(err)
+	}
+	characterID := characterIDs[0]
+	_, err = session.RefreshCharacter(characterID)
+	if err != nil {
+		return checkErr(err)
+	}
+	zoneInfo := session.Zones[characterID]
+
+	var results []checkResult
+	if *biome != "" {
+		results = append(results, checkResult{
+			Condition: "biome " + *biome,
+			OK:        matchBiome(*biome, zoneInfo),
+			Found:     []string{getBiomeName(zoneInfo.Biome)},
+		})
+	}
+	if bloodMoonSet {
+		results = append(results, checkResult{
+			Condition: fmt.Sprintf("blood moon %v", *bloodMoon),
+			OK:        zoneInfo.BloodMoon == *bloodMoon,
+			Found:     []string{fmt.Sprintf("blood moon %v", zoneInfo.BloodMoon)},
+		})
+	}
+	for _, pattern := range items {
+		result := checkResult{Condition: "item " + pattern}
+		findNamed(zoneInfo.ZoneActor, pattern, false, &result.Found)
+		result.OK = len(result.Found) > 0
+		results = append(results, result)
+	}
+	for _, pattern := range events {
+		result := checkResult{Condition: "event " + pattern}
+		findNamed(zoneInfo.ZoneActor, pattern, true, &result.Found)
+		result.OK = len(result.Found) > 0
+		results = append(results, result)
+	}
+
+	ok := true
+	for _, result := range results {
+		ok = ok && result.OK
+		if !*verbose {
+			continue
+		}
+		status := "ok  "
+		if !result.OK {
+			status = "FAIL"
+		}
+		found := "nothing"
+		if len(result.Found) > 0 {
+			found = strings.Join(result.Found, ", ")
+		}
+		fmt.Printf("%s %s: %s\n", status, result.Condition, found)
+	}
+
+	if !ok {
+		return &exitError{Code: checkFailed}
+	}
+	return nil
+}
@@ -0,0 +1,256 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"refinder/catalog"
+	"refinder/locale"
+	"refinder/remnant"
+	"refinder/ue"
+	"testing"
+)
+
+// writeTestArchive writes an archive as a compressed save file.
+func writeTestArchive(t *testing.T, path string, archive remnant.SaveArchive) {
+	t.Helper()
+	data, err := remnant.WriteSaveArchive(&archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = remnant.WriteData(path, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// testSaveArchive returns an archive of the given class, the objects follow
+// the loaded save game object.
+func testSaveArchive(classPath string, objects ...remnant.UObject) remnant.SaveArchive {
+	return remnant.SaveArchive{
+		Header: remnant.SaveHeader{SaveGameFileVersion: 9},
+		Data: remnant.SaveData{
+			PackageVersion:    &remnant.PackageVersion{},
+			SaveGameClassPath: &ue.FTopLevelAssetPath{Path: classPath, Name: filepath.Base(classPath) + "_C"},
+			NamesTable:        []string{"None"},
+			Objects: append([]remnant.UObject{{
+				WasLoaded:  true,
+				ObjectPath: classPath,
+				LoadedData: &remnant.UObjectLoadedData{},
+			}}, objects...),
+		},
+	}
+}
+
+// testObject returns an object of the archive with the given properties.
+func testObject(name string, properties ...remnant.Property) remnant.UObject {
+	return remnant.UObject{
+		ObjectPath:   "/Game/" + name,
+		LoadedData:   &remnant.UObjectLoadedData{Name: name},
+		PropertyList: properties,
+	}
+}
+
+// testContainer returns a Blob property holding the given actors.
+func testContainer(actors map[uint64]remnant.Actor) remnant.Property {
+	return remnant.Property{Name: "Blob", Type: "StructProperty", Value: remnant.StructProperty{
+		Name:  "PersistenceBlob",
+		Value: remnant.PersistenceContainer{Version: 1, Actors: actors},
+	}}
+}
+
+// writeCheckAccount writes a profile with character 0 and the save of its
+// adventure in the given biome, the quest container holds the given actors.
+func writeCheckAccount(t *testing.T, dir, biome string, actors map[uint64]remnant.Actor) {
+	t.Helper()
+
+	characterBlob := remnant.SaveData{PackageVersion: &remnant.PackageVersion{}, NamesTable: []string{"None"}}
+	writeTestArchive(t, filepath.Join(dir, profileFileName), testSaveArchive(remnant.REMNANT_SAVE_GAME_PROFILE,
+		testObject("SavedCharacter",
+			remnant.Property{Name: "ID", Type: "IntProperty", Value: int32(0)},
+			remnant.Property{Name: "Archetype", Type: "StrProperty", Value: "/Game/Archetype_Hunter_UI.Archetype_Hunter_UI_C"},
+			remnant.Property{Name: "SecondaryArchetype", Type: "StrProperty", Value: "/Game/Archetype_Medic_UI.Archetype_Medic_UI_C"},
+			remnant.Property{Name: "CharacterData", Type: "StructProperty", Value: remnant.StructProperty{
+				Name:  "PersistenceBlob",
+				Value: remnant.PersistenceBlob{Archive: characterBlob},
+			}},
+		),
+	))
+
+	questActor := remnant.Actor{
+		Archive: remnant.SaveData{
+			NamesTable: []string{"None"},
+			Objects: []remnant.UObject{{
+				WasLoaded:    true,
+				ObjectPath:   "/Game/Quest",
+				LoadedData:   &remnant.UObjectLoadedData{},
+				PropertyList: []remnant.Property{{Name: "ID", Type: "IntProperty", Value: int32(7)}},
+			}},
+		},
+		DynamicData: &remnant.DynamicActor{
+			UniqueID:  1,
+			Transform: &ue.FTransform{},
+			ClassPath: ue.FTopLevelAssetPath{Path: "/Game/Quest", Name: "Quest_AdventureMode_" + biome + "_C"},
+		},
+	}
+	writeTestArchive(t, filepath.Join(dir, saveFileName(0)), testSaveArchive(remnant.REMNANT_SAVE_GAME,
+		testObject("PersistentLevel",
+			remnant.Property{Name: "Key", Type: "StrProperty", Value: "/Game/Maps/Main.Main:PersistentLevel"},
+			testContainer(map[uint64]remnant.Actor{1: questActor}),
+		),
+		testObject("QuestContainer",
+			remnant.Property{Name: "Key", Type: "StrProperty", Value: "/Game/Quest_7_Container"},
+			testContainer(actors),
+		),
+	))
+}
+
+// writeBrokenWorld writes an account whose character 0 has a world save
+// without a PersistentLevel container to read, and returns its folder.
+func writeBrokenWorld(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	writeCheckAccount(t, dir, "Nerud", nil)
+	writeTestArchive(t, filepath.Join(dir, saveFileName(0)), testSaveArchive(remnant.REMNANT_SAVE_GAME,
+		testObject("PersistentLevel",
+			remnant.Property{Name: "Key", Type: "StrProperty", Value: "/Game/Maps/Main.Main:PersistentLevel"},
+			remnant.Property{Name: "Blob", Type: "IntProperty", Value: int32(0)},
+		),
+	))
+	return dir
+}
+
+func TestCheckExitCodes(t *testing.T) {
+	account := t.TempDir()
+	writeCheckAccount(t, account, "Nerud", nil)
+
+	unreadable := t.TempDir()
+	err := os.WriteFile(filepath.Join(unreadable, profileFileName), []byte("not a save"), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	brokenWorld := writeBrokenWorld(t)
+
+	tests := []struct {
+		name string
+		args []string
+		code int
+	}{
+		{"matching biome", []string{"--save-dir", account, "--character", "0", "--biome", "Nerud"}, 0},
+		{"other biome", []string{"--save-dir", account, "--character", "0", "--biome", "Yaesha"}, checkFailed},
+		{"no blood moon", []string{"--save-dir", account, "--character", "0", "--bloodmoon"}, checkFailed},
+		{"missing item", []string{"--save-dir", account, "--character", "0", "--has-item", "Ring_*"}, checkFailed},
+		{"help", []string{"-h"}, checkError},
+		{"unknown flag", []string{"--nope"}, checkError},
+		{"no condition", []string{"--save-dir", account}, checkError},
+		{"unknown character", []string{"--save-dir", account, "--character", "3", "--biome", "Nerud"}, checkError},
+		{"unreadable profile", []string{"--save-dir", unreadable, "--character", "0", "--biome", "Nerud"}, checkError},
+		{"unreadable world", []string{"--save-dir", brokenWorld, "--character", "0", "--biome", "Nerud"}, checkError},
+		{"several accounts", []string{"--save-dir", writeAccounts(t, "111", "222"), "--biome", "Nerud"}, checkError},
+		{"missing account", []string{"--save-dir", filepath.Join(account, "missing"), "--biome", "Nerud"}, checkError},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			code := 0
+			err := runCheck(test.args)
+			var exit *exitError
+			if errors.As(err, &exit) {
+				code = exit.Code
+			} else if err != nil {
+				t.Fatalf("runCheck returned %v, want an exitError", err)
+			}
+			if code != test.code {
+				t.Errorf("exit code = %d, want %d (err: %v)", code, test.code, err)
+			}
+		})
+	}
+}
+
+func TestCheckSettingsErrors(t *testing.T) {
+	account := t.TempDir()
+	writeCheckAccount(t, account, "Nerud", nil)
+	brokenCatalog := filepath.Join(t.TempDir(), "catalog.json")
+	err := os.WriteFile(brokenCatalog, []byte("{broken"), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		env     string
+		value   string
+		verbose bool
+	}{
+		{"broken catalog", catalog.EnvVar, brokenCatalog, false},
+		{"broken catalog verbose", catalog.EnvVar, brokenCatalog, true},
+		{"unknown language", locale.EnvVar, "xx", false},
+		{"unknown language verbose", locale.EnvVar, "xx", true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Setenv(test.env, test.value)
+			args := []string{"--save-dir", account, "--character", "0", "--biome", "Nerud"}
+			if test.verbose {
+				args = append(args, "-v")
+			}
+			err := runCheck(args)
+			var exit *exitError
+			if !errors.As(err, &exit) || exit.Code != checkError {
+				t.Fatalf("runCheck returned %v, want exit code %d", err, checkError)
+			}
+			// The error is only printed with -v.
+			if (exit.Err != nil) != test.verbose {
+				t.Errorf("error %v with -v %t", exit.Err, test.verbose)
+			}
+		})
+	}
+
+	// --lang wins over an unknown REFINDER_LANG.
+	t.Setenv(locale.EnvVar, "xx")
+	defer func(table *locale.Table) { language = table }(language)
+	err = runCheck([]string{"--save-dir", account, "--character", "0", "--biome", "Nerud", "--lang", "de"})
+	if err != nil {
+		t.Errorf("runCheck with --lang returned %v", err)
+	}
+}
+
+func TestCheckQuiet(t *testing.T) {
+	// An actor without an ID is skipped with a note that check must not
+	// print without -v.
+	account := t.TempDir()
+	writeCheckAccount(t, account, "Nerud", map[uint64]remnant.Actor{1: {
+		Archive: remnant.SaveData{NamesTable: []string{"None"}, Objects: []remnant.UObject{testObject("Decoration",
+			remnant.Property{Name: "Seed", Type: "IntProperty", Value: int32(3)},
+		)}},
+		DynamicData: &remnant.DynamicActor{
+			UniqueID:  1,
+			Transform: &ue.FTransform{},
+			ClassPath: ue.FTopLevelAssetPath{Path: "/Game/Decoration", Name: "Decoration_C"},
+		},
+	}})
+
+	var logged bytes.Buffer
+	log.SetOutput(&logged)
+	defer log.SetOutput(os.Stderr)
+	stdout := os.Stdout
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Stdout = w
+	err = runCheck([]string{"--save-dir", account, "--character", "0", "--biome", "Nerud"})
+	os.Stdout = stdout
+	w.Close()
+	printed, _ := io.ReadAll(r)
+
+	if err != nil {
+		t.Errorf("runCheck returned %v", err)
+	}
+	if len(printed) > 0 || logged.Len() > 0 {
+		t.Errorf("check printed %q and logged %q without -v", printed, logged.String())
+	}
+}
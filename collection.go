@@ -0,0 +1,211 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"refinder/catalog"
+	"slices"
+	"strings"
+	"text/tabwriter"
+)
+
+// The collection is what the whole account owns: an item counts once any
+// character has it. Totals are the cataloged items of a category together
+// with the items owned or seen in the adventures of the characters, so items
+// the catalog misses still count once they show up. Every category is
+// shown, one without cataloged or owned items as 0/0.
+
+type collectionCategory struct {
+	Label    string
+	Category string
+}
+
+var collectionCategories = []collectionCategory{
+	{"Weapons", catalog.Weapon},
+	{"Armor", catalog.Armor},
+	{"Rings", catalog.Ring},
+	{"Amulets", catalog.Amulet},
+	{"Mods", catalog.Mod},
+	{"Mutators", catalog.Mutator},
+	{"Traits", catalog.Trait},
+	{"Archetypes", catalog.Archetype},
+}
+
+func isCollectible(item catalog.Item) bool {
+	return slices.ContainsFunc(collectionCategories, func(category collectionCategory) bool {
+		return category.Category == item.Category
+	})
+}
+
+type collectionRow struct {
+	Name  string `json:"name"`
+	Owned int    `json:"owned"`
+	Total int    `json:"total"`
+}
+
+func (row collectionRow) Percent() float64 {
+	if row.Total == 0 {
+		return 0
+	}
+	return 100 * float64(row.Owned) / float64(row.Total)
+}
+
+type collection struct {
+	Categories []collectionRow `json:"categories"`
+	Biomes     []collectionRow `json:"biomes"`
+}
+
+// collectWorldItems adds the items and rewards of a zone tree to seen.
+func collectWorldItems(zone *ZoneActor, seen map[string]bool) {
+	if zone == nil {
+		return
+	}
+	for _, item := range zone.Items {
+		seen[item.Name] = true
+	}
+	for _, event := range zone.Events {
+		for _, reward := range event.Rewards {
+			seen[reward.ActorBP] = true
+		}
+	}
+	for _, child := range zone.Children {
+		collectWorldItems(child, seen)
+	}
+}
+
+// newCollection counts the owned items per category and per biome. worlds
+// holds the adventures of the characters, an item belongs to the biome the
+// catalog gives it and to the biomes of the adventures it was seen in.
+func newCollection(ownedItems []string, worlds []ZoneInfo) collection {
+	items := map[string]catalog.Item{}
+	biomes := map[string]map[string]bool{}
+	addBiome := func(class, biome string) {
+		if biomes[class] == nil {
+			biomes[class] = map[string]bool{}
+		}
+		biomes[class][biome] = true
+	}
+
+	for _, item := range itemCatalog.Items() {
+		items[item.Class] = item
+		if item.Biome != "" {
+			addBiome(item.Class, item.Biome)
+		}
+	}
+	owned := map[string]bool{}
+	for _, class := range ownedItems {
+		owned[class] = true
+		items[class] = itemCatalog.Lookup(class)
+	}
+	for _, zoneInfo := range worlds {
+		seen := map[string]bool{}
+		collectWorldItems(zoneInfo.ZoneActor, seen)
+		for class := range seen {
+			items[class] = itemCatalog.Lookup(class)
+			addBiome(class, zoneInfo.Biome)
+		}
+	}
+
+	var result collection
+	for _, category := range collectionCategories {
+		row := collectionRow{Name: language.Term(category.Label)}
+		for class, item := range items {
+			if item.Category == category.Category {
+				row.Total++
+				if owned[class] {
+					row.Owned++
+				}
+			}
+		}
+		result.Categories = append(result.Categories, row)
+	}
+
+	biomeRows := map[string]*collectionRow{}
+	for class, item := range items {
+		if !isCollectible(item) {
+			continue
+		}
+		for biome := range biomes[class] {
+			row, ok := biomeRows[biome]
+			if !ok {
+				row = &collectionRow{Name: getBiomeName(biome)}
+				biomeRows[biome] = row
+			}
+			row.Total++
+			if owned[class] {
+				row.Owned++
+			}
+		}
+	}
+	result.Biomes = []collectionRow{}
+	for _, row := range biomeRows {
+		result.Biomes = append(result.Biomes, *row)
+	}
+	slices.SortFunc(result.Biomes, func(a, b collectionRow) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	return result
+}
+
+func runCollection(args []string) error {
+	flags := flag.NewFlagSet("collection", flag.ExitOnError)
+	format := flags.String("format", "text", "output format (text or json)")
+	saveDir := addSaveDirFlag(flags)
+	addLangFlag(flags)
+	positional, err := parseCommandArgs(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 0 || *format != "text" && *format != "json" {
+		return fmt.Errorf("usage: %s", commands["collection"].Usage)
+	}
+
+	account, err := selectAccount(*saveDir)
+	if err != nil {
+		return err
+	}
+	session := NewSession(account, WorldAdventure)
+	err = session.RefreshProfile()
+	if err != nil {
+		return err
+	}
+
+	// Characters that have no adventure yet only add what they own.
+	var worlds []ZoneInfo
+	for _, characterID := range session.CharacterIDs() {
+		_, err := session.RefreshCharacter(characterID)
+		if errors.Is(err, errNoWorld) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("character %d: %w", characterID, err)
+		}
+		worlds = append(worlds, session.Zones[characterID])
+	}
+
+	result := newCollection(session.AccountItems(), worlds)
+	if *format == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(result)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for i, section := range []struct {
+		Title string
+		Rows  []collectionRow
+	}{{"CATEGORY", result.Categories}, {"BIOME", result.Biomes}} {
+		if i > 0 {
+			fmt.Fprintln(w, "\t\t\t\t")
+		}
+		fmt.Fprintf(w, "%s\tOWNED\tTOTAL\tCOMPLETE\t\n", section.Title)
+		for _, row := range section.Rows {
+			fmt.Fprintf(w, "%s\t%d\t%d\t%.0f%%\t\n", row.Name, row.Owned, row.Total, row.Percent())
+		}
+	}
+	return w.Flush()
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"refinder/catalog"
+	"strings"
+	"testing"
+)
+
+// countCatalog counts the cataloged items of a category, of any biome when
+// biome is empty.
+func countCatalog(category, biome string) int {
+	count := 0
+	for _, item := range itemCatalog.Items() {
+		if item.Category == category && (biome == "" || item.Biome == biome) {
+			count++
+		}
+	}
+	return count
+}
+
+func TestNewCollection(t *testing.T) {
+	owned := []string{"Weapon_Nightfall_C", "Ring_Owned_C"}
+	worlds := []ZoneInfo{{
+		Biome: "Fae",
+		ZoneActor: &ZoneActor{Items: []ItemData{
+			{Name: "Weapon_Uncataloged_C"},
+			{Name: "Ring_Seen_C"},
+		}},
+	}}
+	result := newCollection(owned, worlds)
+
+	fae := 0
+	for _, category := range collectionCategories {
+		fae += countCatalog(category.Category, "Fae")
+	}
+	tests := []struct {
+		name  string
+		rows  []collectionRow
+		row   string
+		found bool
+		owned int
+		total int
+	}{
+		{"weapons", result.Categories, "Weapons", true, 1, countCatalog(catalog.Weapon, "") + 1},
+		{"mods", result.Categories, "Mods", true, 0, countCatalog(catalog.Mod, "")},
+		{"archetypes", result.Categories, "Archetypes", true, 0, countCatalog(catalog.Archetype, "")},
+		{"rings", result.Categories, "Rings", true, 1, countCatalog(catalog.Ring, "") + 2},
+		{"armor", result.Categories, "Armor", true, 0, countCatalog(catalog.Armor, "")},
+		{"fae", result.Biomes, getBiomeName("Fae"), true, 1, fae + 2},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for _, row := range test.rows {
+				if row.Name != test.row {
+					continue
+				}
+				if !test.found {
+					t.Fatalf("row %s = %+v, want no row", test.row, row)
+				}
+				if row.Owned != test.owned || row.Total != test.total {
+					t.Errorf("row %s = %d/%d, want %d/%d", test.row, row.Owned, row.Total, test.owned, test.total)
+				}
+				return
+			}
+			if test.found {
+				t.Errorf("row %s missing", test.row)
+			}
+		})
+	}
+}
+
+func TestNewCollectionEmptyCatalog(t *testing.T) {
+	defer func(c *catalog.Catalog) { itemCatalog = c }(itemCatalog)
+	itemCatalog = &catalog.Catalog{}
+
+	// Categories without cataloged or owned items are shown as 0/0, not
+	// left out.
+	result := newCollection([]string{"Ring_Owned_C"}, nil)
+	if len(result.Categories) != len(collectionCategories) {
+		t.Fatalf("%d categories, want %d", len(result.Categories), len(collectionCategories))
+	}
+	for i, row := range result.Categories {
+		want := collectionRow{Name: collectionCategories[i].Label}
+		if collectionCategories[i].Category == catalog.Ring {
+			want.Owned, want.Total = 1, 1
+		}
+		if row != want {
+			t.Errorf("row %d = %+v, want %+v", i, row, want)
+		}
+	}
+}
+
+func TestRunCollectionBrokenWorld(t *testing.T) {
+	// A world that can not be parsed is an error, not a character without
+	// an adventure that is left out of the totals.
+	err := runCollection([]string{"--save-dir", writeBrokenWorld(t)})
+	if err == nil || !strings.Contains(err.Error(), "character 0") {
+		t.Errorf("runCollection = %v, want the error of character 0", err)
+	}
+}
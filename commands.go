@@ -0,0 +1,142 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+)
+
+// Command is a subcommand selected by the first command line argument.
+// Running refinder without a known command watches the active character.
+type Command struct {
+	Usage       string
+	Description string
+	Run         func(args []string) error
+}
+
+var commands map[string]Command
+
+// exitError makes a command exit with the given code. Err is logged when
+// set, a nil Err exits silently.
+type exitError struct {
+	Code int
+	Err  error
+}
+
+func (e *exitError) Error() string {
+	if e.Err == nil {
+		return fmt.Sprintf("exit status %d", e.Code)
+	}
+	return e.Err.Error()
+}
+
+func (e *exitError) Unwrap() error {
+	return e.Err
+}
+
+func init() {
+	commands = map[string]Command{
+		"build": {
+			Usage:       "build [--character id] [--format text|json] [--save-dir dir]",
+			Description: "show the archetype levels, prime perk and traits of a character",
+			Run:         runBuild,
+		},
+		"characters": {
+			Usage:       "characters [--format text|json] [--world adventure|campaign] [--save-dir dir]",
+			Description: "show every character with archetypes, type, power level, playtime and biome",
+			Run:         runCharacters,
+		},
+		"check": {
+			Usage:       "check [--has-item X] [--has-event X] [--biome B] [--bloodmoon] [-v]",
+			Description: "exit 0 if the world of a character matches every condition, 1 if not, 2 on errors",
+			Run:         runCheck,
+		},
+		"collection": {
+			Usage:       "collection [--format text|json] [--save-dir dir]",
+			Description: "show how much of each category and biome the account owns",
+			Run:         runCollection,
+		},
+		"diff": {
+			Usage:       "diff [-filter a,b] <a.sav> <b.sav>",
+			Description: "show added, removed and changed values between two saves",
+			Run:         runDiff,
+		},
+		"dump": {
+			Usage:       "dump [-o out.json] <file.sav>",
+			Description: "write the full decoded save as type-annotated JSON",
+			Run:         runDump,
+		},
+		"import": {
+			Usage:       "import <file.json> -o <file.sav> [--allow-hardcore]",
+			Description: "rebuild a save from a dump, the previous save is kept as a timestamped .bak",
+			Run:         runImport,
+		},
+		"inventory": {
+			Usage:       "inventory [--character id] [--format text|json|csv] [--save-dir dir]",
+			Description: "show the items of a character with quantities, levels and currencies",
+			Run:         runInventory,
+		},
+		"list": {
+			Usage:       "list [--save-dir dir]",
+			Description: "list the characters of the account with their IDs",
+			Run:         runList,
+		},
+		"missing": {
+			Usage:       "missing [--character id] [--world adventure|campaign] [--format text|json] [-v]",
+			Description: "list the unowned items of a world by zone and the items no roll ever had",
+			Run:         runMissing,
+		},
+		"restore": {
+			Usage:       "restore <file.sav> [--allow-hardcore]",
+			Description: "put the newest .bak of a save back in its place, the replaced save becomes the newest .bak",
+			Run:         runRestore,
+		},
+		"serve": {
+			Usage:       "serve [--addr 127.0.0.1:8080] [--save-dir dir] [--world adventure|campaign]",
+			Description: "serve a live web page with the worlds of all characters",
+			Run:         runServe,
+		},
+		"tui": {
+			Usage:       "tui [--save-dir dir] [--world adventure|campaign]",
+			Description: "browse the worlds of all characters in a full-screen view",
+			Run:         runTUI,
+		},
+	}
+
+	flag.Usage = func() {
+		out := flag.CommandLine.Output()
+		fmt.Fprintf(out, "Usage: %s [flags]\n       %s <command> [arguments]\n\nFlags:\n", os.Args[0], os.Args[0])
+		flag.PrintDefaults()
+
+		names := make([]string, 0, len(commands))
+		for name := range commands {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		fmt.Fprintf(out, "\nCommands:\n")
+		for _, name := range names {
+			fmt.Fprintf(out, "  %s\n    \t%s\n", commands[name].Usage, commands[name].Description)
+		}
+	}
+}
+
+// parseCommandArgs parses flags that may appear before or after the
+// positional arguments and returns the positional ones. The settings are
+// loaded once the flags are parsed, see loadSettings.
+func parseCommandArgs(flags *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		err := flags.Parse(args)
+		if err != nil {
+			return nil, err
+		}
+		args = flags.Args()
+		if len(args) == 0 {
+			return positional, loadSettings(flags)
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
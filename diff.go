@@ -0,0 +1,44 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"refinder/remnant"
+	"strings"
+)
+
+func runDiff(args []string) error {
+	flags := flag.NewFlagSet("diff", flag.ExitOnError)
+	filter := flags.String("filter", "", "comma separated substrings, only paths containing one of them are shown")
+	positional, err := parseCommandArgs(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 2 {
+		return fmt.Errorf("usage: %s", commands["diff"].Usage)
+	}
+
+	a, err := readSaveArchiveFile(positional[0])
+	if err != nil {
+		return err
+	}
+	b, err := readSaveArchiveFile(positional[1])
+	if err != nil {
+		return err
+	}
+
+	var substrings []string
+	if *filter != "" {
+		substrings = strings.Split(*filter, ",")
+	}
+	differences := remnant.FilterDifferences(remnant.DiffArchives(&a, &b), substrings)
+
+	counts := map[remnant.DifferenceKind]int{}
+	for _, difference := range differences {
+		fmt.Println(difference)
+		counts[difference.Kind]++
+	}
+	fmt.Printf("%d added, %d removed, %d changed\n", counts[remnant.Added], counts[remnant.Removed], counts[remnant.Changed])
+
+	return nil
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"refinder/config"
+	"refinder/remnant"
+)
+
+// readSaveArchiveFile reads and decodes a save file, errors name the file.
+func readSaveArchiveFile(fullPath string) (remnant.SaveArchive, error) {
+	return readSaveFile(fullPath, remnant.ReadSaveArchive)
+}
+
+// readExactSaveArchiveFile reads a save file with the numbers of names, for
+// the JSON dump and import that write it back.
+func readExactSaveArchiveFile(fullPath string) (remnant.SaveArchive, error) {
+	return readSaveFile(fullPath, remnant.ReadExactSaveArchive)
+}
+
+func readSaveFile(fullPath string, read func(io.ReadSeeker) (remnant.SaveArchive, error)) (remnant.SaveArchive, error) {
+	fileData, err := remnant.ReadData(fullPath)
+	if err != nil {
+		return remnant.SaveArchive{}, fmt.Errorf("%s: %w", fullPath, err)
+	}
+
+	archive, err := read(bytes.NewReader(fileData))
+	if err != nil {
+		return remnant.SaveArchive{}, fmt.Errorf("%s: %w", fullPath, err)
+	}
+
+	if config.DEBUG_SAVE_JSON {
+		writeDebugSaveJSON(fullPath, &archive)
+	}
+
+	return archive, nil
+}
+
+// writeDebugSaveJSON dumps every save the application reads into the working
+// directory when DEBUG_SAVE_JSON is set.
+func writeDebugSaveJSON(fullPath string, archive *remnant.SaveArchive) {
+	jsonPath := filepath.Base(fullPath) + ".json"
+	file, err := os.Create(jsonPath)
+	if err != nil {
+		log.Println("could not write save json:", err)
+		return
+	}
+	defer file.Close()
+
+	err = writeSaveJSON(file, archive)
+	if err != nil {
+		log.Println("could not write save json:", err)
+		return
+	}
+	log.Printf("Wrote %s\n", jsonPath)
+}
+
+func writeSaveJSON(w io.Writer, archive *remnant.SaveArchive) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	return encoder.Encode(archive)
+}
+
+func runDump(args []string) error {
+	flags := flag.NewFlagSet("dump", flag.ExitOnError)
+	output := flags.String("o", "", "output file (default: stdout)")
+	positional, err := parseCommandArgs(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return fmt.Errorf("usage: %s", commands["dump"].Usage)
+	}
+
+	archive, err := readExactSaveArchiveFile(positional[0])
+	if err != nil {
+		return err
+	}
+
+	if *output == "" {
+		return writeSaveJSON(os.Stdout, &archive)
+	}
+
+	file, err := os.Create(*output)
+	if err != nil {
+		return err
+	}
+	err = writeSaveJSON(file, &archive)
+	if err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
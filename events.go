@@ -0,0 +1,150 @@
+package main
+
+import (
+	"fmt"
+	"refinder/catalog"
+	"slices"
+	"strings"
+)
+
+// EventKind is what an event is in the game. Zones list their events in
+// the order of eventKinds, so every format shows them grouped by kind.
+type EventKind string
+
+const (
+	EventStory           EventKind = "story"
+	EventWorldBoss       EventKind = "world-boss"
+	EventOverworldBoss   EventKind = "overworld-boss"
+	EventBoss            EventKind = "boss"
+	EventMiniboss        EventKind = "miniboss"
+	EventDungeon         EventKind = "dungeon"
+	EventPointOfInterest EventKind = "poi"
+	EventInjectable      EventKind = "injectable"
+	EventOther           EventKind = "other"
+)
+
+var eventKinds = []EventKind{EventStory, EventWorldBoss, EventOverworldBoss, EventBoss, EventMiniboss, EventDungeon, EventPointOfInterest, EventInjectable, EventOther}
+
+// eventKindLabels are shown in place of [Event] by the text formats.
+var eventKindLabels = map[EventKind]string{
+	EventStory:           "Story",
+	EventWorldBoss:       "World Boss",
+	EventOverworldBoss:   "Overworld Boss",
+	EventBoss:            "Boss",
+	EventMiniboss:        "Miniboss",
+	EventDungeon:         "Dungeon",
+	EventPointOfInterest: "POI",
+	EventInjectable:      "Injectable",
+	EventOther:           "Event",
+}
+
+// Label returns the translated label of the kind.
+func (kind EventKind) Label() string {
+	label, ok := eventKindLabels[kind]
+	if !ok {
+		label = eventKindLabels[EventOther]
+	}
+	return language.Term(label)
+}
+
+// catalogEventKinds maps the event subcategories of the catalog to kinds.
+var catalogEventKinds = map[string]EventKind{
+	"Story":             EventStory,
+	"World Boss":        EventWorldBoss,
+	"Overworld Boss":    EventOverworldBoss,
+	"Boss":              EventBoss,
+	"Miniboss":          EventMiniboss,
+	"Side Dungeon":      EventDungeon,
+	"Point of Interest": EventPointOfInterest,
+	"Injectable":        EventInjectable,
+}
+
+// getEventKind tells the kind of an event from the catalog, which names the
+// world and overworld bosses and derives the others from the quest class
+// prefix, and otherwise from its POI component.
+func getEventKind(name string, components ItemComponents) EventKind {
+	item := itemCatalog.Lookup(name)
+	if kind, ok := catalogEventKinds[item.Subcategory]; ok && item.Category == catalog.Event {
+		return kind
+	}
+	if components.POI != nil {
+		return EventPointOfInterest
+	}
+	return EventOther
+}
+
+// sortEvents groups events by kind and keeps their order within a kind.
+func sortEvents(events []Event) {
+	slices.SortStableFunc(events, func(a, b Event) int {
+		return slices.Index(eventKinds, a.Kind) - slices.Index(eventKinds, b.Kind)
+	})
+}
+
+// parseEventKinds parses a comma separated list of kinds, plurals like
+// bosses and stories are accepted.
+func parseEventKinds(value string) ([]EventKind, error) {
+	var kinds []EventKind
+	for _, name := range strings.Split(value, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		found := false
+		for _, kind := range eventKinds {
+			if name == string(kind) || name == string(kind)+"s" || name == string(kind)+"es" || name == strings.TrimSuffix(string(kind), "y")+"ies" {
+				kinds = append(kinds, kind)
+				found = true
+				break
+			}
+		}
+		if !found {
+			names := make([]string, len(eventKinds))
+			for i, kind := range eventKinds {
+				names[i] = string(kind)
+			}
+			return nil, fmt.Errorf("unknown event kind %q (supported: %s)", name, strings.Join(names, ", "))
+		}
+	}
+	return kinds, nil
+}
+
+// filterZone copies the tree with only the events of the given kinds. Loose
+// items are left out, zones without a matching event are left out unless a
+// child zone has one.
+func filterZone(zone *ZoneActor, kinds []EventKind) *ZoneActor {
+	if zone == nil {
+		return nil
+	}
+
+	filtered := *zone
+	filtered.Items = nil
+	filtered.Events = nil
+	filtered.Children = nil
+	for _, event := range zone.Events {
+		if slices.Contains(kinds, event.Kind) {
+			filtered.Events = append(filtered.Events, event)
+		}
+	}
+	for _, child := range zone.Children {
+		if child := filterZone(child, kinds); child != nil {
+			filtered.Children = append(filtered.Children, child)
+		}
+	}
+
+	if len(filtered.Events) == 0 && len(filtered.Children) == 0 {
+		return nil
+	}
+	return &filtered
+}
+
+// filterWorld returns the world with only the events of the given kinds, all
+// of it when kinds is empty.
+func filterWorld(zoneInfo ZoneInfo, kinds []EventKind) ZoneInfo {
+	if len(kinds) == 0 {
+		return zoneInfo
+	}
+	root := zoneInfo.ZoneActor
+	zoneInfo.ZoneActor = filterZone(root, kinds)
+	if zoneInfo.ZoneActor == nil && root != nil {
+		// Keep the root zone so the world still shows where it is.
+		zoneInfo.ZoneActor = &ZoneActor{ID: root.ID, Label: root.Label, ZoneLinks: root.ZoneLinks}
+	}
+	return zoneInfo
+}
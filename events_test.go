@@ -0,0 +1,74 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGetEventKind(t *testing.T) {
+	tests := []struct {
+		name       string
+		components ItemComponents
+		kind       EventKind
+	}{
+		{"Quest_Story_RedThrone_C", ItemComponents{}, EventStory},
+		{"Quest_WorldBoss_Unknown_C", ItemComponents{}, EventWorldBoss},
+		{"Quest_OverworldBoss_Unknown_C", ItemComponents{}, EventOverworldBoss},
+		// The catalog tells world and overworld bosses apart among Quest_Boss_.
+		{"Quest_Boss_NightWeaver_C", ItemComponents{}, EventWorldBoss},
+		{"Quest_Boss_BloatKing_C", ItemComponents{}, EventOverworldBoss},
+		{"Quest_Boss_Unknown_C", ItemComponents{}, EventBoss},
+		{"Quest_Miniboss_Unknown_C", ItemComponents{}, EventMiniboss},
+		{"Quest_SideD_Unknown_C", ItemComponents{}, EventDungeon},
+		{"Quest_OverworldPOI_Unknown_C", ItemComponents{}, EventPointOfInterest},
+		{"Quest_Injectable_Unknown_C", ItemComponents{}, EventInjectable},
+		{"Quest_Event_Unknown_C", ItemComponents{POI: &POIComponent{}}, EventPointOfInterest},
+		{"Quest_Event_Unknown_C", ItemComponents{}, EventOther},
+	}
+	for _, test := range tests {
+		if kind := getEventKind(test.name, test.components); kind != test.kind {
+			t.Errorf("getEventKind(%s) = %s, want %s", test.name, kind, test.kind)
+		}
+	}
+}
+
+func TestParseEventKinds(t *testing.T) {
+	tests := []struct {
+		value string
+		kinds []EventKind
+		err   bool
+	}{
+		{"bosses,dungeons", []EventKind{EventBoss, EventDungeon}, false},
+		{" World-Bosses , overworld-boss", []EventKind{EventWorldBoss, EventOverworldBoss}, false},
+		{"stories", []EventKind{EventStory}, false},
+		{"story,poi", []EventKind{EventStory, EventPointOfInterest}, false},
+		{"bosses,dragons", nil, true},
+	}
+	for _, test := range tests {
+		kinds, err := parseEventKinds(test.value)
+		if (err != nil) != test.err {
+			t.Errorf("parseEventKinds(%q) err = %v, want error %t", test.value, err, test.err)
+		}
+		if !reflect.DeepEqual(kinds, test.kinds) && !test.err {
+			t.Errorf("parseEventKinds(%q) = %v, want %v", test.value, kinds, test.kinds)
+		}
+	}
+}
+
+func TestSortEvents(t *testing.T) {
+	events := []Event{
+		{Name: "a", Kind: EventOther},
+		{Name: "b", Kind: EventBoss},
+		{Name: "c", Kind: EventStory},
+		{Name: "d", Kind: EventWorldBoss},
+		{Name: "e", Kind: EventBoss},
+	}
+	sortEvents(events)
+	var names string
+	for _, event := range events {
+		names += event.Name
+	}
+	if names != "cdbea" {
+		t.Errorf("order = %s, want cdbea", names)
+	}
+}
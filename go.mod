@@ -2,6 +2,9 @@ module refinder
 
 go 1.22
 
-require github.com/fsnotify/fsnotify v1.7.0
+require (
+	github.com/fsnotify/fsnotify v1.7.0
+	golang.org/x/term v0.10.0
+)
 
-require golang.org/x/sys v0.6.0 // indirect
+require golang.org/x/sys v0.10.0 // indirect
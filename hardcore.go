@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"refinder/savedir"
+	"slices"
+	"strings"
+)
+
+// A hardcore character that dies is gone, and so is one whose save a bad
+// import breaks. Commands that write save files look up whose files they
+// touch first, and history keeps hardcore runs apart from standard ones.
+
+const hardcoreCharacterType = "ERemnantCharacterType::Hardcore"
+
+func isHardcore(characterData CharacterData) bool {
+	return characterData.Type == hardcoreCharacterType
+}
+
+// hardcoreOwners returns the hardcore characters whose files a save file
+// belongs to: the character of a save_N.sav, or every character for the
+// profile. Files outside a save folder belong to no one.
+func hardcoreOwners(savePath string) ([]CharacterData, error) {
+	account, name, err := savedir.FileAccount(savePath)
+	if err != nil || account == nil {
+		return nil, err
+	}
+	characters, _, _, err := refreshProfile(account.Path(profileFileName))
+	if err != nil {
+		return nil, err
+	}
+
+	characterID, isSave := parseSaveFileName(name)
+	var owners []CharacterData
+	for _, characterData := range characters {
+		if !isHardcore(characterData) {
+			continue
+		}
+		if name == profileFileName || isSave && characterData.ID == characterID {
+			owners = append(owners, characterData)
+		}
+	}
+	slices.SortFunc(owners, func(a, b CharacterData) int {
+		return int(a.ID - b.ID)
+	})
+	return owners, nil
+}
+
+// checkHardcoreWrite refuses to replace a save file of a hardcore character,
+// or one whose owners can not be told, unless allowHardcore is set.
+func checkHardcoreWrite(savePath string, allowHardcore bool) error {
+	owners, err := hardcoreOwners(savePath)
+	if err != nil {
+		if !allowHardcore {
+			return fmt.Errorf("could not tell whether %s belongs to a hardcore character: %w; run again with --allow-hardcore to write it anyway", savePath, err)
+		}
+		log.Printf("WARNING: could not tell whether %s belongs to a hardcore character (%v), writing it because of --allow-hardcore\n", savePath, err)
+		return nil
+	}
+	if len(owners) == 0 {
+		return nil
+	}
+
+	var names []string
+	for _, owner := range owners {
+		names = append(names, fmt.Sprintf("character %d (%s)", owner.ID, owner.Archetype))
+	}
+	if !allowHardcore {
+		return fmt.Errorf("%s belongs to hardcore %s, a broken save can not be undone in hardcore; run again with --allow-hardcore to write it anyway", savePath, strings.Join(names, ", "))
+	}
+	log.Printf("WARNING: %s belongs to hardcore %s, writing it because of --allow-hardcore\n", savePath, strings.Join(names, ", "))
+	return nil
+}
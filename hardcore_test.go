@@ -0,0 +1,35 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCheckHardcoreWrite(t *testing.T) {
+	// A profile that can not be read may hold hardcore characters.
+	unreadable := t.TempDir()
+	err := os.WriteFile(filepath.Join(unreadable, profileFileName), []byte("not a save"), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name          string
+		path          string
+		allowHardcore bool
+		err           bool
+	}{
+		{"outside of an account", filepath.Join(t.TempDir(), "save_0.sav"), false, false},
+		{"unreadable profile", filepath.Join(unreadable, "save_0.sav"), false, true},
+		{"unreadable profile, allowed", filepath.Join(unreadable, "save_0.sav"), true, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := checkHardcoreWrite(test.path, test.allowHardcore)
+			if (err != nil) != test.err {
+				t.Errorf("err = %v, want error %t", err, test.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+// HistoryEnvVar names the history file instead of history.json in the
+// refinder config folder.
+const HistoryEnvVar = "REFINDER_HISTORY"
+
+// seenItem is an item or reward that was in a world ReFinder read.
+type seenItem struct {
+	FirstSeen time.Time `json:"first_seen"`
+	// Biomes are the internal names of the biomes it was seen in.
+	Biomes []string `json:"biomes"`
+}
+
+// seenHistory remembers every item that was ever seen in a saved roll, so
+// the items a roll never had can be told apart from the ones it had. Rolls
+// of hardcore characters are kept apart in Hardcore.
+type seenHistory struct {
+	path     string
+	Items    map[string]*seenItem `json:"items"`
+	Hardcore map[string]*seenItem `json:"hardcore"`
+	changed  bool
+}
+
+func historyPath() string {
+	if path := os.Getenv(HistoryEnvVar); path != "" {
+		return path
+	}
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(configDir, "refinder", "history.json")
+}
+
+// loadHistory reads the history file, a missing file is an empty history.
+func loadHistory(path string) (*seenHistory, error) {
+	history := &seenHistory{path: path, Items: map[string]*seenItem{}, Hardcore: map[string]*seenItem{}}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return history, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal(data, history)
+	if err != nil {
+		return nil, &fs.PathError{Op: "parse", Path: path, Err: err}
+	}
+	if history.Items == nil {
+		history.Items = map[string]*seenItem{}
+	}
+	if history.Hardcore == nil {
+		history.Hardcore = map[string]*seenItem{}
+	}
+	return history, nil
+}
+
+// items returns the items seen by hardcore or by standard characters.
+func (h *seenHistory) items(hardcore bool) map[string]*seenItem {
+	if hardcore {
+		return h.Hardcore
+	}
+	return h.Items
+}
+
+func (h *seenHistory) add(items map[string]*seenItem, class, biome string, now time.Time) {
+	item, ok := items[class]
+	if !ok {
+		item = &seenItem{FirstSeen: now, Biomes: []string{}}
+		items[class] = item
+		h.changed = true
+	}
+	if biome != "" && !slices.Contains(item.Biomes, biome) {
+		item.Biomes = append(item.Biomes, biome)
+		slices.Sort(item.Biomes)
+		h.changed = true
+	}
+}
+
+// Record adds the items and rewards of the world of a character. Campaign
+// worlds span every biome, their items are recorded without one.
+func (h *seenHistory) Record(characterData CharacterData, zoneInfo ZoneInfo) {
+	if h == nil {
+		return
+	}
+
+	biome := zoneInfo.Biome
+	if zoneInfo.Mode != WorldAdventure {
+		biome = ""
+	}
+	seen := map[string]bool{}
+	collectWorldItems(zoneInfo.ZoneActor, seen)
+	now := time.Now().UTC()
+	items := h.items(isHardcore(characterData))
+	for class := range seen {
+		h.add(items, class, biome, now)
+	}
+}
+
+// Seen reports whether an item was in any recorded world of hardcore or of
+// standard characters.
+func (h *seenHistory) Seen(class string, hardcore bool) bool {
+	if h == nil {
+		return false
+	}
+	_, ok := h.items(hardcore)[class]
+	return ok
+}
+
+// Biome returns the biome an item was seen in by any character, "" when it
+// was never seen in an adventure or was seen in several biomes.
+func (h *seenHistory) Biome(class string) string {
+	if h == nil {
+		return ""
+	}
+	var biomes []string
+	for _, items := range []map[string]*seenItem{h.Items, h.Hardcore} {
+		if item, ok := items[class]; ok {
+			for _, biome := range item.Biomes {
+				if !slices.Contains(biomes, biome) {
+					biomes = append(biomes, biome)
+				}
+			}
+		}
+	}
+	if len(biomes) != 1 {
+		return ""
+	}
+	return biomes[0]
+}
+
+// Save writes the history if it changed since it was loaded.
+func (h *seenHistory) Save() error {
+	if h == nil || !h.changed || h.path == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(h, "", "  ")
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(filepath.Dir(h.path), 0o755)
+	if err != nil {
+		return err
+	}
+
+	// Write a temporary file first so an interrupted write does not lose
+	// the history.
+	tmp := h.path + ".tmp"
+	err = os.WriteFile(tmp, append(data, '\n'), 0o644)
+	if err != nil {
+		return err
+	}
+	err = os.Rename(tmp, h.path)
+	if err != nil {
+		return err
+	}
+	h.changed = false
+	return nil
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"refinder/remnant"
+	"sort"
+	"strings"
+	"time"
+)
+
+func runImport(args []string) error {
+	flags := flag.NewFlagSet("import", flag.ExitOnError)
+	output := flags.String("o", "", "save file to write (required)")
+	allowHardcore := flags.Bool("allow-hardcore", false, "write the save even if it belongs to a hardcore character")
+	positional, err := parseCommandArgs(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 || *output == "" {
+		return fmt.Errorf("usage: %s", commands["import"].Usage)
+	}
+
+	jsonData, err := os.ReadFile(positional[0])
+	if err != nil {
+		return err
+	}
+
+	var archive remnant.SaveArchive
+	err = json.Unmarshal(jsonData, &archive)
+	if err != nil {
+		return fmt.Errorf("could not parse %s: %w", positional[0], err)
+	}
+
+	// A save that does not load can not be undone in hardcore, the game
+	// would not let the character back.
+	err = checkHardcoreWrite(*output, *allowHardcore)
+	if err != nil {
+		return err
+	}
+
+	data, err := remnant.WriteSaveArchive(&archive)
+	if err != nil {
+		return err
+	}
+
+	// Write next to the target first, the save is only replaced once the new
+	// file reads back as the archive that was imported.
+	tempPath := *output + ".tmp"
+	crc, err := remnant.WriteData(tempPath, data)
+	if err != nil {
+		os.Remove(tempPath)
+		return err
+	}
+
+	err = validateSaveFile(tempPath, &archive)
+	if err != nil {
+		os.Remove(tempPath)
+		return fmt.Errorf("written save is not valid: %w", err)
+	}
+
+	backupPath, err := backupSave(*output, time.Now())
+	if err != nil {
+		os.Remove(tempPath)
+		return err
+	}
+	if backupPath != "" {
+		log.Printf("Previous save moved to %s\n", backupPath)
+	}
+
+	err = os.Rename(tempPath, *output)
+	if err != nil {
+		os.Remove(tempPath)
+		return undoBackup(backupPath, *output, err)
+	}
+	log.Printf("Wrote %s (crc32 %08x)\n", *output, crc)
+
+	return nil
+}
+
+// backupTimeFormat names backups by when they were made, names of the same
+// save sort from the oldest to the newest backup.
+const backupTimeFormat = "20060102-150405.000"
+
+// backupSave moves a save out of the way as <save>.<time>.bak, so every
+// import keeps the save it replaced. It returns "" when there is no save.
+func backupSave(savePath string, now time.Time) (string, error) {
+	if _, err := os.Stat(savePath); err != nil {
+		return "", nil
+	}
+	backupPath := fmt.Sprintf("%s.%s.bak", savePath, now.Format(backupTimeFormat))
+	if _, err := os.Stat(backupPath); err == nil {
+		return "", fmt.Errorf("backup %s already exists", backupPath)
+	}
+	err := os.Rename(savePath, backupPath)
+	if err != nil {
+		return "", err
+	}
+	return backupPath, nil
+}
+
+// undoBackup moves a save backupSave moved away back into place after err
+// kept the new save from replacing it. When that fails too, the error tells
+// where the save is.
+func undoBackup(backupPath, savePath string, err error) error {
+	if backupPath == "" {
+		return err
+	}
+	if renameErr := os.Rename(backupPath, savePath); renameErr != nil {
+		return fmt.Errorf("%w; the previous save is at %s", err, backupPath)
+	}
+	log.Printf("Previous save moved back to %s\n", savePath)
+	return err
+}
+
+// latestBackup returns the newest backup backupSave made of a save. The
+// folder is listed instead of globbed, save paths may hold [, * or ?.
+func latestBackup(savePath string) (string, error) {
+	entries, err := os.ReadDir(filepath.Dir(savePath))
+	if err != nil {
+		return "", err
+	}
+	prefix := filepath.Base(savePath) + "."
+	var backups []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.IsDir() && strings.HasPrefix(name, prefix) && strings.HasSuffix(name, ".bak") && len(name) > len(prefix)+len(".bak") {
+			backups = append(backups, name)
+		}
+	}
+	if len(backups) == 0 {
+		return "", fmt.Errorf("%s has no backup", savePath)
+	}
+	sort.Strings(backups)
+	return filepath.Join(filepath.Dir(savePath), backups[len(backups)-1]), nil
+}
+
+// validateSaveFile reads a written save back and compares it with the
+// archive it was written from. Objects the writer adds for new object
+// references are expected.
+func validateSaveFile(fullPath string, archive *remnant.SaveArchive) error {
+	written, err := readExactSaveArchiveFile(fullPath)
+	if err != nil {
+		return err
+	}
+
+	var differences []string
+	for _, difference := range remnant.DiffArchives(archive, &written) {
+		if object, ok := difference.New.(remnant.UObject); ok && difference.Kind == remnant.Added && object.PropertyList == nil && object.Components == nil {
+			continue
+		}
+		differences = append(differences, difference.String())
+	}
+	if len(differences) > 0 {
+		if len(differences) > 5 {
+			differences = append(differences[:5], fmt.Sprintf("and %d more", len(differences)-5))
+		}
+		return fmt.Errorf("it reads back different from the input:\n  %s", strings.Join(differences, "\n  "))
+	}
+
+	return nil
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"refinder/remnant"
+	"refinder/ue"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testArchive(properties ...remnant.Property) remnant.SaveArchive {
+	return remnant.SaveArchive{
+		Header: remnant.SaveHeader{SaveGameFileVersion: 9},
+		Data: remnant.SaveData{
+			PackageVersion:    &remnant.PackageVersion{},
+			SaveGameClassPath: &ue.FTopLevelAssetPath{Path: remnant.REMNANT_SAVE_GAME, Name: "BP_RemnantSaveGame_C"},
+			NamesTable:        []string{"None"},
+			Objects: []remnant.UObject{{
+				WasLoaded:    true,
+				ObjectPath:   remnant.REMNANT_SAVE_GAME,
+				LoadedData:   &remnant.UObjectLoadedData{},
+				PropertyList: properties,
+			}},
+		},
+	}
+}
+
+func TestValidateSaveFile(t *testing.T) {
+	written := testArchive(
+		remnant.Property{Name: "Level", Type: "IntProperty", Value: int32(3)},
+		remnant.Property{Name: "Item", Type: "ObjectProperty", Value: remnant.ObjectProperty{ClassName: "/Game/Item.Item_C", Index: -1}},
+	)
+
+	tests := []struct {
+		name    string
+		archive remnant.SaveArchive
+		err     string
+	}{
+		{"same archive", written, ""},
+		{"changed value", testArchive(
+			remnant.Property{Name: "Level", Type: "IntProperty", Value: int32(4)},
+			remnant.Property{Name: "Item", Type: "ObjectProperty", Value: remnant.ObjectProperty{ClassName: "/Game/Item.Item_C", Index: -1}},
+		), "~ [" + remnant.REMNANT_SAVE_GAME + "].Level: 4 -> 3"},
+		{"missing property", testArchive(
+			remnant.Property{Name: "Level", Type: "IntProperty", Value: int32(3)},
+		), "+ [" + remnant.REMNANT_SAVE_GAME + "].Item"},
+	}
+
+	path := filepath.Join(t.TempDir(), "save_0.sav")
+	data, err := remnant.WriteSaveArchive(&written)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = remnant.WriteData(path, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := validateSaveFile(path, &test.archive)
+			switch {
+			case test.err == "" && err != nil:
+				t.Errorf("unexpected error: %v", err)
+			case test.err != "" && (err == nil || !strings.Contains(err.Error(), test.err)):
+				t.Errorf("error = %v, want it to contain %q", err, test.err)
+			}
+		})
+	}
+}
+
+func TestBackupSave(t *testing.T) {
+	savePath := filepath.Join(t.TempDir(), "save_0.sav")
+	first := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	backupPath, err := backupSave(savePath, first)
+	if err != nil || backupPath != "" {
+		t.Fatalf("backup of a missing save = %q, %v", backupPath, err)
+	}
+	_, err = latestBackup(savePath)
+	if err == nil {
+		t.Error("no error for a save without backup")
+	}
+
+	// Every import keeps the save it replaced.
+	steps := []struct {
+		data string
+		now  time.Time
+	}{
+		{"original", first},
+		{"first import", first.Add(time.Minute)},
+		{"second import", first.Add(2 * time.Minute)},
+	}
+	for _, step := range steps {
+		err := os.WriteFile(savePath, []byte(step.data), 0o644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = backupSave(savePath, step.now)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	backups, err := filepath.Glob(savePath + ".*.bak")
+	if err != nil || len(backups) != len(steps) {
+		t.Fatalf("backups = %q, %v, want %d", backups, err, len(steps))
+	}
+	latest, err := latestBackup(savePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(latest); string(data) != "second import" {
+		t.Errorf("latest backup %s holds %q, want the second import", latest, data)
+	}
+
+	err = os.WriteFile(savePath, []byte("third import"), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = backupSave(savePath, first)
+	if err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("err = %v, want an existing backup to be kept", err)
+	}
+}
+
+func TestLatestBackupSpecialPath(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "Proton [1] *?")
+	err := os.MkdirAll(dir, 0o755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	savePath := filepath.Join(dir, "save_0.sav")
+	files := []string{
+		"save_0.sav.20240501-120000.000.bak",
+		"save_0.sav.20240501-120100.000.bak",
+		"save_0.sav.tmp",
+		"save_01.sav.20240501-120200.000.bak",
+		"save_1.sav.20240501-120300.000.bak",
+	}
+	for _, name := range files {
+		err := os.WriteFile(filepath.Join(dir, name), nil, 0o644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	latest, err := latestBackup(savePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(dir, files[1]); latest != want {
+		t.Errorf("latestBackup = %s, want %s", latest, want)
+	}
+}
+
+func TestUndoBackup(t *testing.T) {
+	dir := t.TempDir()
+	savePath := filepath.Join(dir, "save_0.sav")
+	backupPath := savePath + ".20240501-120000.000.bak"
+	renameErr := os.ErrPermission
+
+	err := os.WriteFile(backupPath, []byte("original"), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = undoBackup(backupPath, savePath, renameErr)
+	if err != renameErr {
+		t.Errorf("err = %v, want %v", err, renameErr)
+	}
+	if data, _ := os.ReadFile(savePath); string(data) != "original" {
+		t.Errorf("save holds %q, want the original back", data)
+	}
+
+	// A backup that can not be moved back is named in the error.
+	err = undoBackup(backupPath, savePath, renameErr)
+	if err == nil || !strings.Contains(err.Error(), backupPath) {
+		t.Errorf("err = %v, want it to name %s", err, backupPath)
+	}
+
+	if err := undoBackup("", savePath, renameErr); err != renameErr {
+		t.Errorf("err = %v without a backup, want %v", err, renameErr)
+	}
+}
@@ -0,0 +1,236 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"refinder/catalog"
+	"refinder/remnant"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// InventoryItem is an entry of the Inventory component of a character.
+// Quantities and levels are either on the entry or on the object its
+// InstanceData points to. Like for the quest components, fields an entry
+// does not have are listed in Missing and left at their zero value.
+type InventoryItem struct {
+	Name     string `json:"name"`
+	Quantity int32  `json:"quantity"`
+	// Level is the upgrade level of weapons and the level of archetypes and
+	// traits.
+	Level int32 `json:"level"`
+	// Experience is the experience of archetypes.
+	Experience int32 `json:"experience,omitempty"`
+	Favorite   bool  `json:"favorite"`
+	New        bool  `json:"new"`
+	// EquippedSlot is the index of the equipment slot the item is in, empty
+	// when it is not equipped.
+	EquippedSlot string   `json:"equipped_slot,omitempty"`
+	Missing      []string `json:"missing,omitempty"`
+}
+
+// decodeInventoryItem reads an entry of the Items array of the Inventory
+// component. objects is the archive the entry is from, its InstanceData
+// indexes into it.
+func decodeInventoryItem(entry map[string]interface{}, objects []remnant.UObject) InventoryItem {
+	properties := map[string]interface{}{}
+	if instance, ok := entry["InstanceData"].(remnant.ObjectProperty); ok && instance.Index >= 0 && int(instance.Index) < len(objects) {
+		for name, value := range objects[instance.Index].Properties {
+			properties[name] = value
+		}
+	}
+	for name, value := range entry {
+		properties[name] = value
+	}
+
+	r := componentReader{properties: properties}
+	item := InventoryItem{
+		Quantity:   r.int("Quantity"),
+		Level:      r.int("Level"),
+		Experience: r.int("Experience"),
+		Favorite:   r.bool("Favorited"),
+		New:        r.bool("New"),
+	}
+	if bp, ok := entry["ItemBP"].(remnant.ObjectProperty); ok {
+		item.Name = className(bp)
+	} else {
+		r.missing = append(r.missing, "ItemBP")
+	}
+	slot := r.int("EquippedSlotIndex")
+	item.Missing = r.missing
+	if item.has("EquippedSlotIndex") && slot >= 0 {
+		item.EquippedSlot = strconv.Itoa(int(slot))
+	}
+	return item
+}
+
+// has tells whether the entry had the property of a field.
+func (item InventoryItem) has(name string) bool {
+	return !slices.Contains(item.Missing, name)
+}
+
+// isCurrency tells whether an item is counted rather than collected, like
+// scrap, relic fragments and materials.
+func isCurrency(name string) bool {
+	category := itemCatalog.Lookup(name).Category
+	return category == catalog.Currency || category == catalog.Material
+}
+
+type inventoryEntry struct {
+	InventoryItem
+	DisplayName string `json:"display_name"`
+	Category    string `json:"category"`
+}
+
+func newInventoryEntry(item InventoryItem) inventoryEntry {
+	return inventoryEntry{
+		InventoryItem: item,
+		DisplayName:   getPrintableName(item.Name),
+		Category:      itemCatalog.Lookup(item.Name).Category,
+	}
+}
+
+type inventoryReport struct {
+	Character  int32            `json:"character"`
+	Items      []inventoryEntry `json:"items"`
+	Currencies []inventoryEntry `json:"currencies"`
+}
+
+// newInventoryReport splits an inventory into items, sorted by category and
+// name, and currencies followed by materials.
+func newInventoryReport(characterData CharacterData) inventoryReport {
+	report := inventoryReport{Character: characterData.ID, Items: []inventoryEntry{}, Currencies: []inventoryEntry{}}
+	for _, item := range characterData.Inventory {
+		if isCurrency(item.Name) {
+			report.Currencies = append(report.Currencies, newInventoryEntry(item))
+		} else {
+			report.Items = append(report.Items, newInventoryEntry(item))
+		}
+	}
+
+	compare := func(a, b inventoryEntry) int {
+		if a.Category != b.Category {
+			return strings.Compare(a.Category, b.Category)
+		}
+		return strings.Compare(a.DisplayName, b.DisplayName)
+	}
+	slices.SortFunc(report.Items, compare)
+	slices.SortFunc(report.Currencies, compare)
+	return report
+}
+
+// field formats a number of the entry, or "?" when the entry does not have
+// it.
+func (item InventoryItem) field(name string, value int32) string {
+	if !item.has(name) {
+		return "?"
+	}
+	return strconv.Itoa(int(value))
+}
+
+func printInventory(w io.Writer, report inventoryReport) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ITEM\tLEVEL\tEQUIPPED\tFLAGS\t")
+	for _, entry := range report.Items {
+		level := ""
+		if entry.Level > 0 {
+			level = "+" + strconv.Itoa(int(entry.Level))
+		}
+		if entry.has("Quantity") && entry.Quantity != 1 {
+			entry.DisplayName = fmt.Sprintf("%s x%d", entry.DisplayName, entry.Quantity)
+		}
+		var flags []string
+		if entry.Favorite {
+			flags = append(flags, "favorite")
+		}
+		if entry.New {
+			flags = append(flags, "new")
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t\n", entry.DisplayName, level, entry.EquippedSlot, strings.Join(flags, ", "))
+	}
+
+	if len(report.Currencies) > 0 {
+		fmt.Fprintln(tw, "\t\t\t\t")
+		fmt.Fprintln(tw, "CURRENCY\tQUANTITY\t\t\t")
+		for _, entry := range report.Currencies {
+			fmt.Fprintf(tw, "%s\t%s\t\t\t\n", entry.DisplayName, entry.field("Quantity", entry.Quantity))
+		}
+	}
+	return tw.Flush()
+}
+
+var inventoryCSVHeader = []string{
+	"character_id", "category", "name", "display_name", "quantity", "level", "favorite", "new", "equipped_slot",
+}
+
+func writeInventoryCSV(w io.Writer, report inventoryReport) error {
+	writer := csv.NewWriter(w)
+	err := writer.Write(inventoryCSVHeader)
+	if err != nil {
+		return err
+	}
+	for _, entry := range append(report.Items, report.Currencies...) {
+		err = writer.Write([]string{
+			strconv.Itoa(int(report.Character)),
+			entry.Category,
+			entry.Name,
+			entry.DisplayName,
+			entry.field("Quantity", entry.Quantity),
+			entry.field("Level", entry.Level),
+			strconv.FormatBool(entry.Favorite),
+			strconv.FormatBool(entry.New),
+			entry.EquippedSlot,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func runInventory(args []string) error {
+	flags := flag.NewFlagSet("inventory", flag.ExitOnError)
+	format := flags.String("format", "text", "output format (text, json or csv)")
+	characterFlag := flags.Int("character", -1, "ID of the character (default: the active character)")
+	saveDir := addSaveDirFlag(flags)
+	addLangFlag(flags)
+	positional, err := parseCommandArgs(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 0 || !slices.Contains([]string{"text", "json", "csv"}, *format) {
+		return fmt.Errorf("usage: %s", commands["inventory"].Usage)
+	}
+
+	account, err := selectAccount(*saveDir)
+	if err != nil {
+		return err
+	}
+	session := NewSession(account, WorldAdventure)
+	err = session.RefreshProfile()
+	if err != nil {
+		return err
+	}
+	characterIDs, err := session.SelectCharacters(*characterFlag, false)
+	if err != nil {
+		return err
+	}
+
+	report := newInventoryReport(session.Characters[characterIDs[0]])
+	switch *format {
+	case "json":
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	case "csv":
+		return writeInventoryCSV(os.Stdout, report)
+	}
+	return printInventory(os.Stdout, report)
+}
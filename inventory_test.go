@@ -0,0 +1,71 @@
+package main
+
+import (
+	"refinder/remnant"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestDecodeInventoryItem(t *testing.T) {
+	itemBP := func(class string) remnant.ObjectProperty {
+		return remnant.ObjectProperty{ClassName: "/Game/Items/" + class + "." + class, Index: -1}
+	}
+	objects := []remnant.UObject{
+		{Properties: map[string]interface{}{"Quantity": int32(1250), "Level": int32(0)}},
+		{Properties: map[string]interface{}{"Level": int32(7), "Experience": int32(52000)}},
+	}
+	tests := []struct {
+		name  string
+		entry map[string]interface{}
+		want  InventoryItem
+	}{
+		{"instance data", map[string]interface{}{
+			"ItemBP": itemBP("Material_Scrap_C"), "InstanceData": remnant.ObjectProperty{Index: 0},
+			"Favorited": false, "New": true, "EquippedSlotIndex": int32(-1), "Experience": int32(0),
+		}, InventoryItem{Name: "Material_Scrap_C", Quantity: 1250, New: true}},
+		{"equipped", map[string]interface{}{
+			"ItemBP": itemBP("Archetype_Hunter_C"), "InstanceData": remnant.ObjectProperty{Index: 1},
+			"Quantity": int32(1), "Favorited": true, "New": false, "EquippedSlotIndex": int32(0),
+		}, InventoryItem{Name: "Archetype_Hunter_C", Quantity: 1, Level: 7, Experience: 52000, Favorite: true, EquippedSlot: "0"}},
+		// Nothing is made up for properties under other names.
+		{"missing fields", map[string]interface{}{
+			"ItemBP": itemBP("Weapon_Nightfall_C"), "InstanceData": remnant.ObjectProperty{Index: 5},
+			"Count": int32(3), "bFavorite": true, "Slot": int32(1),
+		}, InventoryItem{Name: "Weapon_Nightfall_C", Missing: []string{"Quantity", "Level", "Experience", "Favorited", "New", "EquippedSlotIndex"}}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			item := decodeInventoryItem(test.entry, objects)
+			if !reflect.DeepEqual(item, test.want) {
+				t.Errorf("decodeInventoryItem = %+v, want %+v", item, test.want)
+			}
+		})
+	}
+}
+
+func TestWriteInventoryMissingQuantity(t *testing.T) {
+	report := newInventoryReport(CharacterData{Inventory: []InventoryItem{
+		{Name: "Material_Scrap_C", Missing: []string{"Quantity"}},
+		{Name: "Weapon_Nightfall_C", Quantity: 1, Level: 3},
+	}})
+
+	var text, csv strings.Builder
+	err := printInventory(&text, report)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = writeInventoryCSV(&csv, report)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !regexp.MustCompile(`\n` + getPrintableName("Material_Scrap_C") + ` +\? `).MatchString(text.String()) {
+		t.Errorf("text %q does not show an unknown scrap quantity", text.String())
+	}
+	for _, want := range []string{",Material_Scrap_C," + getPrintableName("Material_Scrap_C") + ",?,0,", ",1,3,"} {
+		if !strings.Contains(csv.String(), want) {
+			t.Errorf("CSV %q does not contain %q", csv.String(), want)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+)
+
+func runList(args []string) error {
+	flags := flag.NewFlagSet("list", flag.ExitOnError)
+	saveDir := addSaveDirFlag(flags)
+	positional, err := parseCommandArgs(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 0 {
+		return fmt.Errorf("usage: %s", commands["list"].Usage)
+	}
+
+	account, err := selectAccount(*saveDir)
+	if err != nil {
+		return err
+	}
+	session := NewSession(account, WorldAdventure)
+	err = session.RefreshProfile()
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tARCHETYPE\tTYPE\t")
+	for _, characterID := range session.CharacterIDs() {
+		characterData := session.Characters[characterID]
+		active := ""
+		if characterID == session.ActiveCharacterID {
+			active = "(active)"
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", characterData.ID, characterData.Archetype, getCharacterTypeName(characterData.Type), active)
+	}
+	return w.Flush()
+}
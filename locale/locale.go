@@ -0,0 +1,162 @@
+// Package locale translates the names ReFinder prints: items and events by
+// blueprint class, biomes by their internal name, and the categories shown
+// in braces. English is built into the catalog, every other language is a
+// table that falls back to English for what it does not translate.
+//
+// Tables are embedded from <lang>.json. A table of the same name in the
+// lang folder of the refinder config folder is merged over the shipped one,
+// which also adds languages that are not shipped.
+package locale
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// EnvVar selects the language when no flag is given.
+const EnvVar = "REFINDER_LANG"
+
+// English is the language of the catalog, it needs no table.
+const English = "en"
+
+//go:embed *.json
+var tables embed.FS
+
+type Table struct {
+	// Names maps blueprint classes of items and events to their name.
+	Names map[string]string `json:"names"`
+	// Biomes maps internal biome names, like Jungle, to their name.
+	Biomes map[string]string `json:"biomes"`
+	// Terms maps categories and kinds of events, like Weapon or Side
+	// Dungeon, to their name.
+	Terms map[string]string `json:"terms"`
+}
+
+func userDir() string {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(configDir, "refinder", "lang")
+}
+
+// Languages returns the shipped languages and the ones in the user folder.
+func Languages() []string {
+	languages := map[string]bool{English: true}
+	entries, _ := tables.ReadDir(".")
+	if dir := userDir(); dir != "" {
+		userEntries, _ := os.ReadDir(dir)
+		entries = append(entries, userEntries...)
+	}
+	for _, entry := range entries {
+		if name, ok := strings.CutSuffix(entry.Name(), ".json"); ok {
+			languages[name] = true
+		}
+	}
+
+	names := make([]string, 0, len(languages))
+	for name := range languages {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Load returns the table of a language, nil for English.
+func Load(lang string) (*Table, error) {
+	lang = strings.ToLower(lang)
+	if lang == English {
+		return nil, nil
+	}
+	if strings.ContainsAny(lang, `/\.`) {
+		return nil, fmt.Errorf("unknown language %q (supported: %s)", lang, strings.Join(Languages(), ", "))
+	}
+
+	table := &Table{Names: map[string]string{}, Biomes: map[string]string{}, Terms: map[string]string{}}
+	found := false
+
+	data, err := tables.ReadFile(lang + ".json")
+	if err == nil {
+		found = true
+		err = table.merge(data)
+		if err != nil {
+			panic(fmt.Sprintf("locale: invalid %s.json: %v", lang, err))
+		}
+	}
+
+	if dir := userDir(); dir != "" {
+		path := filepath.Join(dir, lang+".json")
+		data, err := os.ReadFile(path)
+		if err == nil {
+			found = true
+			err = table.merge(data)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", path, err)
+			}
+		} else if !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+	}
+
+	if !found {
+		return nil, fmt.Errorf("unknown language %q (supported: %s)", lang, strings.Join(Languages(), ", "))
+	}
+	return table, nil
+}
+
+func (t *Table) merge(data []byte) error {
+	var other Table
+	err := json.Unmarshal(data, &other)
+	if err != nil {
+		return err
+	}
+	for class, name := range other.Names {
+		t.Names[class] = name
+	}
+	for biome, name := range other.Biomes {
+		t.Biomes[biome] = name
+	}
+	for term, name := range other.Terms {
+		t.Terms[term] = name
+	}
+	return nil
+}
+
+func lookup(names map[string]string, key, english string) string {
+	if name, ok := names[key]; ok && name != "" {
+		return name
+	}
+	return english
+}
+
+// Name returns the name of an item or event class, english when the table
+// has none. A nil table is English.
+func (t *Table) Name(class, english string) string {
+	if t == nil {
+		return english
+	}
+	return lookup(t.Names, class, english)
+}
+
+// Biome returns the name of a biome, english when the table has none.
+func (t *Table) Biome(biome, english string) string {
+	if t == nil {
+		return english
+	}
+	return lookup(t.Biomes, biome, english)
+}
+
+// Term returns the name of a category or kind of event.
+func (t *Table) Term(term string) string {
+	if t == nil {
+		return term
+	}
+	return lookup(t.Terms, term, term)
+}
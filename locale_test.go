@@ -0,0 +1,39 @@
+package main
+
+import (
+	"refinder/catalog"
+	"refinder/locale"
+	"refinder/remnant"
+	"testing"
+)
+
+// TestShippedTranslations checks that the shipped tables translate the
+// biomes, the categories and every item and event of the catalog.
+func TestShippedTranslations(t *testing.T) {
+	for _, lang := range []string{"de", "ru"} {
+		t.Run(lang, func(t *testing.T) {
+			table, err := locale.Load(lang)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for biome := range remnant.BiomeNames {
+				if _, ok := table.Biomes[biome]; !ok {
+					t.Errorf("biome %s is not translated", biome)
+				}
+			}
+			for _, category := range collectionCategories {
+				if _, ok := table.Terms[category.Label]; !ok {
+					t.Errorf("category %s is not translated", category.Label)
+				}
+			}
+			for _, item := range catalog.Default().Items() {
+				if tag := item.Tag(); tag != "" && table.Terms[tag] == "" {
+					t.Errorf("term %s of %s is not translated", tag, item.Class)
+				}
+				if table.Names[item.Class] == "" {
+					t.Errorf("name of %s is not translated", item.Class)
+				}
+			}
+		})
+	}
+}
@@ -1,19 +1,18 @@
 package main
 
 import (
-	"bytes"
+	"errors"
+	"flag"
 	"fmt"
 	"log"
 	"os"
 	"os/signal"
-	"path"
+	"refinder/catalog"
+	"refinder/locale"
 	"refinder/remnant"
-	"regexp"
 	"slices"
-	"strconv"
 	"strings"
-
-	"github.com/fsnotify/fsnotify"
+	"time"
 )
 
 type ItemProperties struct {
@@ -25,68 +24,102 @@ type ItemProperties struct {
 type ItemComponents struct {
 	LootSpawns      interface{}
 	Zone            interface{}
-	POI             interface{}
+	POI             *POIComponent
 	Rewards         []interface{}
-	QuestObjectives []interface{}
+	QuestObjectives []QuestObjective
 }
 
 type ItemData struct {
-	Name             string
-	Properties       ItemProperties
-	Components       ItemComponents
-	OwnedByCharacter bool
-	Quantity         int32
+	Name             string         `json:"name"`
+	Properties       ItemProperties `json:"-"`
+	Components       ItemComponents `json:"-"`
+	OwnedByCharacter bool           `json:"owned"`
+	Quantity         int32          `json:"quantity"`
 }
 
 type ZoneLinkInfo struct {
-	ZoneID          int32
-	Label           string
-	Type            string
-	DestinationLink string
-	DestinationZone string
-	NameID          string
+	ZoneID          int32  `json:"zone_id"`
+	Label           string `json:"label"`
+	Type            string `json:"type"`
+	DestinationLink string `json:"destination_link"`
+	DestinationZone string `json:"destination_zone"`
+	NameID          string `json:"name_id"`
 }
 
 type Event struct {
-	Name    string
-	Rewards []LootSpawn
+	Name       string           `json:"name"`
+	Kind       EventKind        `json:"kind"`
+	Status     EventStatus      `json:"status"`
+	Objectives []QuestObjective `json:"objectives"`
+	POI        *POIComponent    `json:"poi"`
+	Rewards    []LootSpawn      `json:"rewards"`
 }
 
 type ZoneActor struct {
-	ID           int32
-	ParentZoneID int32
-	QuestID      int32
-	Label        string
-	ZoneLinks    []ZoneLinkInfo
-	Events       []Event
-	Items        []ItemData
-	Children     []*ZoneActor
+	ID           int32          `json:"id"`
+	ParentZoneID int32          `json:"parent_zone_id"`
+	QuestID      int32          `json:"quest_id"`
+	Label        string         `json:"label"`
+	ZoneLinks    []ZoneLinkInfo `json:"zone_links"`
+	Events       []Event        `json:"events"`
+	Items        []ItemData     `json:"items"`
+	Children     []*ZoneActor   `json:"children"`
 }
 
 type PersistenceKey struct {
-	ContainerKey string
-	PersistentID uint64
+	ContainerKey string `json:"container_key"`
+	PersistentID uint64 `json:"persistent_id"`
 }
 
 type LootSpawn struct {
-	Type             string
-	ActorBP          string
-	Quantity         int32
-	PersistenceKey   PersistenceKey
-	OwnedByCharacter bool
+	Type             string         `json:"type"`
+	ActorBP          string         `json:"actor_bp"`
+	Quantity         int32          `json:"quantity"`
+	PersistenceKey   PersistenceKey `json:"persistence_key"`
+	OwnedByCharacter bool           `json:"owned"`
 }
 
 type CharacterData struct {
-	ID        int32
-	Archetype string
-	Items     []string
-	Type      string
+	ID        int32    `json:"id"`
+	Archetype string   `json:"archetype"`
+	Items     []string `json:"-"`
+	Type      string   `json:"type"`
+	// Inventory holds the entries Items was made from, with their
+	// quantities, levels and flags.
+	Inventory []InventoryItem `json:"-"`
+	// Progression holds the archetype levels, traits and prime perk.
+	Progression Progression `json:"-"`
+	// PowerLevel, PlayTime and LastSaved are zero when the save does not
+	// record them, MissingStats lists their properties then.
+	PowerLevel   int32         `json:"-"`
+	PlayTime     time.Duration `json:"-"`
+	LastSaved    time.Time     `json:"-"`
+	MissingStats []string      `json:"-"`
 }
 
 type ZoneInfo struct {
-	ZoneActor *ZoneActor
-	Biome     string
-	BloodMoon bool
+	ZoneActor *ZoneActor `json:"zone"`
+	Mode      string     `json:"mode"`
+	Biome     string     `json:"biome"`
+	BloodMoon bool       `json:"blood_moon"`
+	// CRC is the checksum of the save file the world was read from.
+	CRC uint32 `json:"-"`
+}
+
+const (
+	WorldAdventure = "adventure"
+	WorldCampaign  = "campaign"
+)
+
+// errNoWorld is returned by findWorld for a save without the world, like the
+// campaign of a character that only played adventures.
+var errNoWorld = errors.New("world not started")
+
+// worldQuestPrefixes maps a world to the class name prefix of its main quest
+// actor, the rest of the class name is the biome.
+var worldQuestPrefixes = map[string]string{
+	WorldAdventure: "Quest_AdventureMode_",
+	WorldCampaign:  "Quest_Campaign_",
 }
 
 func buildTree(zones []ZoneActor) *ZoneActor {
@@ -116,115 +149,39 @@ func buildTree(zones []ZoneActor) *ZoneActor {
 	return root
 }
 
-func splitByCapital(str string) string {
-	re := regexp.MustCompile(`[A-Z][^A-Z]*`)
-	splitStr := re.FindAllString(str, -1)
-	return strings.TrimSpace(strings.Join(splitStr, " "))
-}
-
-func getPrintableName(name string) string {
-	if !strings.HasSuffix(name, "_C") {
-		return name
-	}
-
-	if strings.HasPrefix(name, "Material_") {
-		name = strings.TrimSuffix(name, "_C")
-		name = strings.TrimPrefix(name, "Material_")
-		return splitByCapital(name)
-	}
-
-	if strings.HasPrefix(name, "Amulet_") || strings.HasPrefix(name, "Armor_") || strings.HasPrefix(name, "Ring_") || strings.HasPrefix(name, "Weapon_") {
-		name = strings.TrimSuffix(name, "_C")
-		splitted := strings.Split(name, "_")
-		name = fmt.Sprintf("{%s} %s", splitted[0], splitByCapital(strings.Join(splitted[1:], " ")))
-		return name
-	}
-
-	if strings.HasPrefix(name, "Item_HiddenContainer_Material_Engram_") {
-		name = strings.TrimSuffix(name, "_C")
-		name = strings.TrimPrefix(name, "Item_HiddenContainer_Material_Engram_")
-		return fmt.Sprintf("{Archetype Item} %s", splitByCapital(name))
-	}
-
-	if strings.HasPrefix(name, "Quest_") {
-		name = strings.TrimSuffix(name, "_C")
-		name = strings.TrimPrefix(name, "Quest_")
-		splitted := strings.Split(name, "_")
+// itemCatalog names the items, events and rewards of the saves. The user
+// catalog file is merged in when the program starts.
+var itemCatalog = catalog.Default()
 
-		namePrefix := ""
-		if splitted[0] == "Injectable" {
-			namePrefix = "{Injectable} "
-			splitted = splitted[1:]
-		}
-		if splitted[0] == "SideD" {
-			namePrefix = "{Side Dungeon} "
-			splitted = splitted[1:]
-		}
-		if splitted[0] == "OverworldPOI" {
-			namePrefix = "{Point of Interest} "
-			splitted = splitted[1:]
-		}
-		if splitted[0] == "Miniboss" {
-			namePrefix = "{Miniboss} "
-			splitted = splitted[1:]
-		}
-		return namePrefix + splitByCapital(strings.Join(splitted, "")) // if this will be the case, apply splitByCapital for each item of `splitted``
-	}
+// language translates the printed names, nil is English.
+var language *locale.Table
 
-	if strings.HasPrefix(name, "GemContainer_") {
-		name = strings.TrimSuffix(name, "_C")
-		name = strings.TrimPrefix(name, "GemContainer_")
-		switch name {
-		case "BlueGems":
-			return "Blue Relic Fragment"
-		case "YellowGems":
-			return "Yellow Relic Fragment"
-		case "RedGems":
-			return "Red Relic Fragment"
-		default:
-			return splitByCapital(name)
-		}
+func setLanguage(lang string) error {
+	table, err := locale.Load(lang)
+	if err != nil {
+		return err
 	}
+	language = table
+	return nil
+}
 
-	return name
+func addLangFlag(flags *flag.FlagSet) {
+	flags.Func("lang", fmt.Sprintf("language of item, event and biome names (%s, default: $%s or %s)", strings.Join(locale.Languages(), ", "), locale.EnvVar, locale.English), setLanguage)
 }
 
-func printTreeWithItems(zone *ZoneActor, indent string) {
-	if indent == "" {
-		fmt.Println(indent + zone.Label)
-	} else {
-		fmt.Printf("%s %s\n", indent, zone.Label)
-	}
-	for _, link := range zone.ZoneLinks {
-		if link.Type == "EZoneLinkType::Waypoint" {
-			fmt.Printf("%s--- || [Waypoint] %s\n", indent, link.Label)
-		}
-	}
-	for _, item := range zone.Items {
-		if strings.HasPrefix(item.Name, "Material_") {
-			fmt.Printf("%s--- || [Material] x%d %s\n", indent, item.Quantity, getPrintableName(item.Name))
-		} else {
-			ownedPrint := "✅"
-			if !item.OwnedByCharacter {
-				ownedPrint = "❌"
-			}
-			fmt.Printf("%s--- || [Item] %s x%d %s\n", indent, ownedPrint, item.Quantity, getPrintableName(item.Name))
-		}
-	}
-	for _, event := range zone.Events {
-		fmt.Printf("%s--- || [Event] %s\n", indent, getPrintableName(event.Name))
-		for _, reward := range event.Rewards {
-			ownedPrint := "✅"
-			if !reward.OwnedByCharacter {
-				ownedPrint = "❌"
-			}
-			fmt.Printf("%s------ || [Reward] %s x%d %s\n", indent, ownedPrint, reward.Quantity, getPrintableName(reward.ActorBP))
-		}
-	}
+// getItemName returns the translated name of an item or event without its
+// category, for lines that show the kind on their own.
+func getItemName(class string) string {
+	return language.Name(class, itemCatalog.Lookup(class).Name)
+}
 
-	for _, child := range zone.Children {
-		printTreeWithItems(child, indent+"---")
+func getPrintableName(name string) string {
+	item := itemCatalog.Lookup(name)
+	printableName := language.Name(item.Class, item.Name)
+	if item.Tag() == "" {
+		return printableName
 	}
+	return fmt.Sprintf("{%s} %s", language.Term(item.Tag()), printableName)
 }
 
 func getTextPropertyValue(textProperty remnant.TextProperty) string {
@@ -239,7 +196,32 @@ func getTextPropertyValue(textProperty remnant.TextProperty) string {
 	return ""
 }
 
-func getZoneActor(objects []remnant.UObject) ZoneActor {
+// propertyAs returns a property of the given type, the error names the
+// property when it is missing or has another type.
+func propertyAs[T any](properties map[string]interface{}, name string) (T, error) {
+	value, ok := properties[name].(T)
+	if !ok {
+		return value, fmt.Errorf("could not parse %s", name)
+	}
+	return value, nil
+}
+
+// structAs returns the value of a struct property, like the map of a
+// generic struct or the container of a PersistenceBlob.
+func structAs[T any](properties map[string]interface{}, name string) (T, error) {
+	structProperty, err := propertyAs[remnant.StructProperty](properties, name)
+	if err != nil {
+		var value T
+		return value, err
+	}
+	value, ok := structProperty.Value.(T)
+	if !ok {
+		return value, fmt.Errorf("could not parse %s", name)
+	}
+	return value, nil
+}
+
+func getZoneActor(objects []remnant.UObject) (ZoneActor, error) {
 	var zoneInfo ZoneActor
 	for _, obj := range objects {
 		if len(obj.Properties) == 0 {
@@ -256,22 +238,53 @@ func getZoneActor(objects []remnant.UObject) ZoneActor {
 			zoneInfo.QuestID = questID
 		}
 
-		zoneInfo.Label = getTextPropertyValue(obj.Properties["Label"].(remnant.TextProperty))
+		label, err := propertyAs[remnant.TextProperty](obj.Properties, "Label")
+		if err != nil {
+			return ZoneActor{}, err
+		}
+		zoneInfo.Label = getTextPropertyValue(label)
 
-		for _, zoneLink := range obj.Properties["ZoneLinks"].(remnant.ArrayStructProperty).Items {
-			zoneLinkValue := zoneLink.Value.(map[string]interface{})
-			zoneInfo.ZoneLinks = append(zoneInfo.ZoneLinks, ZoneLinkInfo{
-				ZoneID:          zoneLinkValue["ZoneID"].(int32),
-				DestinationLink: zoneLinkValue["DestinationLink"].(string),
-				DestinationZone: zoneLinkValue["DestinationZone"].(string),
-				NameID:          zoneLinkValue["NameID"].(string),
-				Label:           getTextPropertyValue(zoneLinkValue["Label"].(remnant.TextProperty)),
-				Type:            zoneLinkValue["Type"].(remnant.EnumProperty).EnumValue,
-			})
+		zoneLinks, err := propertyAs[remnant.ArrayStructProperty](obj.Properties, "ZoneLinks")
+		if err != nil {
+			return ZoneActor{}, err
+		}
+		for _, zoneLink := range zoneLinks.Items {
+			zoneLinkValue, ok := zoneLink.Value.(map[string]interface{})
+			if !ok {
+				return ZoneActor{}, fmt.Errorf("could not parse ZoneLinks")
+			}
+			zoneLinkInfo := ZoneLinkInfo{}
+			zoneLinkInfo.ZoneID, err = propertyAs[int32](zoneLinkValue, "ZoneID")
+			if err != nil {
+				return ZoneActor{}, err
+			}
+			zoneLinkInfo.DestinationLink, err = propertyAs[string](zoneLinkValue, "DestinationLink")
+			if err != nil {
+				return ZoneActor{}, err
+			}
+			zoneLinkInfo.DestinationZone, err = propertyAs[string](zoneLinkValue, "DestinationZone")
+			if err != nil {
+				return ZoneActor{}, err
+			}
+			zoneLinkInfo.NameID, err = propertyAs[string](zoneLinkValue, "NameID")
+			if err != nil {
+				return ZoneActor{}, err
+			}
+			label, err := propertyAs[remnant.TextProperty](zoneLinkValue, "Label")
+			if err != nil {
+				return ZoneActor{}, err
+			}
+			zoneLinkInfo.Label = getTextPropertyValue(label)
+			linkType, err := propertyAs[remnant.EnumProperty](zoneLinkValue, "Type")
+			if err != nil {
+				return ZoneActor{}, err
+			}
+			zoneLinkInfo.Type = linkType.EnumValue
+			zoneInfo.ZoneLinks = append(zoneInfo.ZoneLinks, zoneLinkInfo)
 		}
 	}
 
-	return zoneInfo
+	return zoneInfo, nil
 }
 
 func getItemProperties(objects []remnant.UObject) (ItemProperties, error) {
@@ -320,13 +333,13 @@ func getItemComponents(objects []remnant.UObject) ItemComponents {
 				itemComponents.Zone = comp.Properties
 			}
 			if comp.ComponentKey == "POI" {
-				itemComponents.POI = comp.Properties
+				itemComponents.POI = decodePOI(comp.Properties)
 			}
 			if strings.HasPrefix(comp.ComponentKey, "Reward_") {
 				itemComponents.Rewards = append(itemComponents.Rewards, comp.Properties)
 			}
 			if strings.HasPrefix(comp.ComponentKey, "QuestObjective_") {
-				itemComponents.QuestObjectives = append(itemComponents.QuestObjectives, comp.Properties)
+				itemComponents.QuestObjectives = append(itemComponents.QuestObjectives, decodeQuestObjective(comp.ComponentKey, comp.Properties))
 			}
 		}
 	}
@@ -334,6 +347,59 @@ func getItemComponents(objects []remnant.UObject) ItemComponents {
 	return itemComponents
 }
 
+// decodeLootSpawn reads an entry of the Spawns array of a reward.
+func decodeLootSpawn(spawn remnant.StructProperty, characterItems []string) (LootSpawn, error) {
+	spawnEntry, ok := spawn.Value.(map[string]interface{})
+	if !ok {
+		return LootSpawn{}, fmt.Errorf("could not parse Spawns")
+	}
+	spawnProperties, err := structAs[map[string]interface{}](spawnEntry, "SpawnEntry")
+	if err != nil {
+		return LootSpawn{}, err
+	}
+	keyProperties, err := structAs[map[string]interface{}](spawnEntry, "Key")
+	if err != nil {
+		return LootSpawn{}, err
+	}
+
+	actorBP, err := propertyAs[string](spawnProperties, "ActorBP")
+	if err != nil {
+		return LootSpawn{}, err
+	}
+	actorBPSplit := strings.Split(actorBP, ".")
+	if len(actorBPSplit) > 1 {
+		actorBP = actorBPSplit[1]
+	}
+
+	spawnType, err := propertyAs[remnant.EnumProperty](spawnProperties, "Type")
+	if err != nil {
+		return LootSpawn{}, err
+	}
+	quantity, err := propertyAs[int32](spawnProperties, "Quantity")
+	if err != nil {
+		return LootSpawn{}, err
+	}
+	containerKey, err := propertyAs[string](keyProperties, "ContainerKey")
+	if err != nil {
+		return LootSpawn{}, err
+	}
+	persistentID, err := propertyAs[uint64](keyProperties, "PersistentID")
+	if err != nil {
+		return LootSpawn{}, err
+	}
+
+	return LootSpawn{
+		Type:     spawnType.EnumValue,
+		ActorBP:  actorBP,
+		Quantity: quantity,
+		PersistenceKey: PersistenceKey{
+			ContainerKey: containerKey,
+			PersistentID: persistentID,
+		},
+		OwnedByCharacter: slices.Contains(characterItems, actorBP),
+	}, nil
+}
+
 func processItems(items []ItemData, zone ZoneActor, characterItems []string) ([]ItemData, []Event, error) {
 	var resultItems []ItemData
 	var resultEvents []Event
@@ -354,11 +420,32 @@ func processItems(items []ItemData, zone ZoneActor, characterItems []string) ([]
 		if currentZoneID == zone.ID {
 			if item.Components.LootSpawns != nil {
 				// TODO: Actor spawn
-				for _, itemProp := range item.Components.LootSpawns.(remnant.ArrayStructProperty).Items {
-					itemProps := itemProp.Value.(map[string]interface{})
-					spawnPropertyProps := itemProps["SpawnEntry"].(remnant.StructProperty).Value.(map[string]interface{})
-					item.Name = strings.Split(spawnPropertyProps["ActorBP"].(string), ".")[1]
-					item.Quantity = spawnPropertyProps["Quantity"].(int32)
+				lootSpawns, ok := item.Components.LootSpawns.(remnant.ArrayStructProperty)
+				if !ok {
+					return nil, nil, fmt.Errorf("could not parse Spawns")
+				}
+				for _, itemProp := range lootSpawns.Items {
+					itemProps, ok := itemProp.Value.(map[string]interface{})
+					if !ok {
+						return nil, nil, fmt.Errorf("could not parse Spawns")
+					}
+					spawnPropertyProps, err := structAs[map[string]interface{}](itemProps, "SpawnEntry")
+					if err != nil {
+						return nil, nil, err
+					}
+					actorBP, err := propertyAs[string](spawnPropertyProps, "ActorBP")
+					if err != nil {
+						return nil, nil, err
+					}
+					actorBPSplit := strings.Split(actorBP, ".")
+					if len(actorBPSplit) < 2 {
+						return nil, nil, fmt.Errorf("could not parse ActorBP %q", actorBP)
+					}
+					item.Name = actorBPSplit[1]
+					item.Quantity, err = propertyAs[int32](spawnPropertyProps, "Quantity")
+					if err != nil {
+						return nil, nil, err
+					}
 				}
 
 				if slices.Contains(characterItems, item.Name) {
@@ -368,33 +455,30 @@ func processItems(items []ItemData, zone ZoneActor, characterItems []string) ([]
 			} else {
 				var currentEvent Event
 				currentEvent.Name = item.Name
+				currentEvent.Kind = getEventKind(item.Name, item.Components)
+				currentEvent.Objectives = item.Components.QuestObjectives
+				currentEvent.POI = item.Components.POI
+				currentEvent.Status = getEventStatus(currentEvent.Objectives, currentEvent.POI)
 				if item.Components.Rewards != nil {
 					lootSpawns := []LootSpawn{}
 					for _, reward := range item.Components.Rewards {
-						if _, ok := reward.(map[string]interface{})["Spawns"]; !ok {
+						rewardProperties, ok := reward.(map[string]interface{})
+						if !ok {
+							return nil, nil, fmt.Errorf("could not parse reward")
+						}
+						if _, ok := rewardProperties["Spawns"]; !ok {
 							continue
 						}
-						itemSpawns := reward.(map[string]interface{})["Spawns"].(remnant.ArrayStructProperty).Items
-						for _, item := range itemSpawns {
-							spawnProperties := item.Value.(map[string]interface{})["SpawnEntry"].(remnant.StructProperty).Value.(map[string]interface{})
-							keyProperties := item.Value.(map[string]interface{})["Key"].(remnant.StructProperty).Value.(map[string]interface{})
-
-							actorBP := spawnProperties["ActorBP"].(string)
-							actorBPSplit := strings.Split(spawnProperties["ActorBP"].(string), ".")
-							if len(actorBPSplit) > 1 {
-								actorBP = actorBPSplit[1]
+						itemSpawns, err := propertyAs[remnant.ArrayStructProperty](rewardProperties, "Spawns")
+						if err != nil {
+							return nil, nil, err
+						}
+						for _, item := range itemSpawns.Items {
+							lootSpawn, err := decodeLootSpawn(item, characterItems)
+							if err != nil {
+								return nil, nil, err
 							}
-
-							lootSpawns = append(lootSpawns, LootSpawn{
-								Type:     spawnProperties["Type"].(remnant.EnumProperty).EnumValue,
-								ActorBP:  actorBP,
-								Quantity: spawnProperties["Quantity"].(int32),
-								PersistenceKey: PersistenceKey{
-									ContainerKey: keyProperties["ContainerKey"].(string),
-									PersistentID: keyProperties["PersistentID"].(uint64),
-								},
-								OwnedByCharacter: slices.Contains(characterItems, actorBP),
-							})
+							lootSpawns = append(lootSpawns, lootSpawn)
 						}
 					}
 					currentEvent.Rewards = lootSpawns
@@ -404,20 +488,39 @@ func processItems(items []ItemData, zone ZoneActor, characterItems []string) ([]
 		}
 	}
 
+	sortEvents(resultEvents)
 	return resultItems, resultEvents, nil
 }
 
-func findAdventure(result *remnant.SaveArchive, characterItems []string) (ZoneInfo, error) {
+// containerActors returns the actors of the PersistenceContainer stored in
+// the Blob property of an object.
+func containerActors(obj remnant.UObject) (map[uint64]remnant.Actor, error) {
+	container, err := structAs[remnant.PersistenceContainer](obj.Properties, "Blob")
+	if err != nil {
+		return nil, err
+	}
+	return container.Actors, nil
+}
+
+// actorClassName returns the class of a dynamic actor, or "" for an actor
+// without dynamic data.
+func actorClassName(actor remnant.Actor) string {
+	if actor.DynamicData == nil {
+		return ""
+	}
+	return actor.DynamicData.ClassPath.Name
+}
+
+func findWorld(result *remnant.SaveArchive, characterItems []string, world string) (ZoneInfo, error) {
+	questPrefix, ok := worldQuestPrefixes[world]
+	if !ok {
+		return ZoneInfo{}, fmt.Errorf("unknown world %q", world)
+	}
+
 	var adventureObject remnant.UObject
 	for _, obj := range result.Data.Objects {
-		for propName, propValue := range obj.Properties {
-			if propName == "Key" && strings.HasSuffix(propValue.(string), "Main.Main:PersistentLevel") {
-				adventureObject = obj
-				break
-			}
-		}
-
-		if len(adventureObject.Properties) > 0 {
+		if key, ok := obj.Properties["Key"].(string); ok && strings.HasSuffix(key, "Main.Main:PersistentLevel") {
+			adventureObject = obj
 			break
 		}
 	}
@@ -426,20 +529,23 @@ func findAdventure(result *remnant.SaveArchive, characterItems []string) (ZoneIn
 		return ZoneInfo{}, fmt.Errorf("could not find base properties")
 	}
 
+	adventureActors, err := containerActors(adventureObject)
+	if err != nil {
+		return ZoneInfo{}, err
+	}
 	var adventureActor remnant.Actor
-	for _, actorValue := range adventureObject.Properties["Blob"].(remnant.StructProperty).Value.(remnant.PersistenceContainer).Actors {
-		if strings.HasPrefix(actorValue.DynamicData.ClassPath.Name, "Quest_AdventureMode_") {
+	for _, actorValue := range adventureActors {
+		if strings.HasPrefix(actorClassName(actorValue), questPrefix) {
 			adventureActor = actorValue
 			break
 		}
 	}
 
 	if len(adventureActor.Archive.Objects) == 0 {
-		return ZoneInfo{}, fmt.Errorf("could not find adventure actors")
+		return ZoneInfo{}, fmt.Errorf("could not find %s actors: %w", world, errNoWorld)
 	}
 
 	var id int32
-	var ok bool
 	for _, obj := range adventureActor.Archive.Objects {
 		if id, ok = obj.Properties["ID"].(int32); ok {
 			break
@@ -448,33 +554,41 @@ func findAdventure(result *remnant.SaveArchive, characterItems []string) (ZoneIn
 
 	var adventureContainerObject remnant.UObject
 	for _, obj := range result.Data.Objects {
-		for propName, propValue := range obj.Properties {
-			if propName == "Key" && strings.HasPrefix(propValue.(string), fmt.Sprintf("/Game/Quest_%d_Container", id)) {
-				adventureContainerObject = obj
-			}
+		if key, ok := obj.Properties["Key"].(string); ok && strings.HasPrefix(key, fmt.Sprintf("/Game/Quest_%d_Container", id)) {
+			adventureContainerObject = obj
 		}
 	}
 
-	actors := adventureContainerObject.Properties["Blob"].(remnant.StructProperty).Value.(remnant.PersistenceContainer).Actors
+	actors, err := containerActors(adventureContainerObject)
+	if err != nil {
+		return ZoneInfo{}, fmt.Errorf("could not read the Quest_%d container: %w", id, err)
+	}
 
 	zoneActors := []ZoneActor{}
 	items := []ItemData{}
 
 	for _, actor := range actors {
-		if strings.HasPrefix(actor.DynamicData.ClassPath.Name, "Quest_Global_") {
+		className := actorClassName(actor)
+		if className == "" || strings.HasPrefix(className, "Quest_Global_") {
 			continue
 		}
-		if actor.DynamicData.ClassPath.Name == "ZoneActor" {
-			zoneActors = append(zoneActors, getZoneActor(actor.Archive.Objects))
+		if className == "ZoneActor" {
+			zoneActor, err := getZoneActor(actor.Archive.Objects)
+			if err != nil {
+				return ZoneInfo{}, err
+			}
+			zoneActors = append(zoneActors, zoneActor)
 		} else {
+			// Actors that are not items or events have no ID, they are
+			// skipped with a note on stderr, stdout is the renderer's.
 			itemProperties, err := getItemProperties(actor.Archive.Objects)
 			if err != nil {
-				fmt.Println(err)
+				log.Printf("%s: %v", className, err)
 				continue
 			}
 			itemComponents := getItemComponents(actor.Archive.Objects)
 			items = append(items, ItemData{
-				Name:       actor.DynamicData.ClassPath.Name,
+				Name:       className,
 				Properties: itemProperties,
 				Components: itemComponents,
 			})
@@ -485,9 +599,15 @@ func findAdventure(result *remnant.SaveArchive, characterItems []string) (ZoneIn
 	for _, archiveObj := range adventureActor.Archive.Objects {
 		for _, archiveComp := range archiveObj.Components {
 			if archiveComp.ComponentKey == "Variables" {
-				vars := archiveComp.Properties["Variables"].(remnant.Variables)
-				if _, ok := vars.Properties["IsBloodMoon"]; ok {
-					bloodMoon = vars.Properties["IsBloodMoon"].(bool)
+				vars, err := propertyAs[remnant.Variables](archiveComp.Properties, "Variables")
+				if err != nil {
+					return ZoneInfo{}, err
+				}
+				if value, ok := vars.Properties["IsBloodMoon"]; ok {
+					bloodMoon, ok = value.(bool)
+					if !ok {
+						return ZoneInfo{}, fmt.Errorf("could not parse IsBloodMoon")
+					}
 					break
 				}
 			}
@@ -497,7 +617,7 @@ func findAdventure(result *remnant.SaveArchive, characterItems []string) (ZoneIn
 	for i, actor := range zoneActors {
 		items, events, err := processItems(items, actor, characterItems)
 		if err != nil {
-			log.Fatal(err)
+			return ZoneInfo{}, err
 		}
 
 		actor.Items = items
@@ -508,29 +628,27 @@ func findAdventure(result *remnant.SaveArchive, characterItems []string) (ZoneIn
 
 	tree := buildTree(zoneActors)
 
-	biome := adventureActor.DynamicData.ClassPath.Name
-	biome = strings.TrimPrefix(biome, "Quest_AdventureMode_")
+	biome := actorClassName(adventureActor)
+	biome = strings.TrimPrefix(biome, questPrefix)
 	biome = strings.TrimSuffix(biome, "_C")
 
 	return ZoneInfo{
 		ZoneActor: tree,
+		Mode:      world,
 		BloodMoon: bloodMoon,
 		Biome:     biome,
 	}, nil
 }
 
-func refreshSaveFile(fullPath string, characterData CharacterData) (ZoneInfo, error) {
-	fileData, err := remnant.ReadData(fullPath)
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	archive, err := remnant.ReadSaveArchive(bytes.NewReader(fileData))
+func refreshSaveFile(fullPath string, characterData CharacterData, world string) (ZoneInfo, error) {
+	archive, err := readSaveArchiveFile(fullPath)
 	if err != nil {
-		log.Fatal(err)
+		return ZoneInfo{}, err
 	}
 
-	return findAdventure(&archive, characterData.Items)
+	zoneInfo, err := findWorld(&archive, characterData.Items, world)
+	zoneInfo.CRC = archive.Header.Crc
+	return zoneInfo, err
 }
 
 func getArchetypeName(archetype string) string {
@@ -539,15 +657,26 @@ func getArchetypeName(archetype string) string {
 	return archetype
 }
 
-func refreshProfile(fullPath string) (map[int32]CharacterData, int32, error) {
-	fileData, err := remnant.ReadData(fullPath)
+// archetypeProperty returns the archetype name of a class path property like
+// "/Game/.../Archetype_Hunter_UI.Archetype_Hunter_UI_C".
+func archetypeProperty(properties map[string]interface{}, name string) (string, error) {
+	archetype, err := propertyAs[string](properties, name)
 	if err != nil {
-		return nil, 0, err
+		return "", err
 	}
+	archetypeSplit := strings.Split(archetype, ".")
+	if len(archetypeSplit) < 2 {
+		return "", fmt.Errorf("could not parse %s %q", name, archetype)
+	}
+	return getArchetypeName(archetypeSplit[1]), nil
+}
 
-	archive, err := remnant.ReadSaveArchive(bytes.NewReader(fileData))
+// refreshProfile reads the characters of a profile, the active character and
+// the checksum of the profile file.
+func refreshProfile(fullPath string) (map[int32]CharacterData, int32, uint32, error) {
+	archive, err := readSaveArchiveFile(fullPath)
 	if err != nil {
-		return nil, 0, err
+		return nil, 0, 0, err
 	}
 
 	activeCharacterID := int32(-1)
@@ -557,7 +686,7 @@ func refreshProfile(fullPath string) (map[int32]CharacterData, int32, error) {
 			if ok {
 				activeCharacterID, ok = activeCharacter.(int32)
 				if !ok {
-					return nil, 0, fmt.Errorf("could not parse active character")
+					return nil, 0, 0, fmt.Errorf("could not parse active character")
 				}
 				break
 			}
@@ -578,155 +707,329 @@ func refreshProfile(fullPath string) (map[int32]CharacterData, int32, error) {
 		} else {
 			characterData.Type = "ERemnantCharacterType::Standard"
 		}
-		characterData.Archetype = getArchetypeName(strings.Split(obj.Properties["Archetype"].(string), ".")[1]) + " / " + getArchetypeName(strings.Split(obj.Properties["SecondaryArchetype"].(string), ".")[1])
+		primary, err := archetypeProperty(obj.Properties, "Archetype")
+		if err != nil {
+			return nil, 0, 0, err
+		}
+		secondary, err := archetypeProperty(obj.Properties, "SecondaryArchetype")
+		if err != nil {
+			return nil, 0, 0, err
+		}
+		characterData.Archetype = primary + " / " + secondary
+		readCharacterStats(&characterData, obj.Properties)
 		characterData.Items = []string{}
-		for _, characterDataObj := range obj.Properties["CharacterData"].(remnant.StructProperty).Value.(remnant.PersistenceBlob).Archive.Objects {
+		characterData.Inventory = []InventoryItem{}
+		characterBlob, err := structAs[remnant.PersistenceBlob](obj.Properties, "CharacterData")
+		if err != nil {
+			return nil, 0, 0, err
+		}
+		blob := characterBlob.Archive
+		for _, characterDataObj := range blob.Objects {
 			if characterDataObj.LoadedData.Name == "Character_Master_Player_C" {
 				for _, charcaterComp := range characterDataObj.Components {
 					if charcaterComp.ComponentKey == "Inventory" {
-						for _, item := range charcaterComp.Properties["Items"].(remnant.ArrayStructProperty).Items {
-							characterData.Items = append(characterData.Items, strings.Split(item.Value.(map[string]interface{})["ItemBP"].(remnant.ObjectProperty).ClassName, ".")[1])
+						items, err := propertyAs[remnant.ArrayStructProperty](charcaterComp.Properties, "Items")
+						if err != nil {
+							return nil, 0, 0, err
+						}
+						for _, item := range items.Items {
+							itemProperties, ok := item.Value.(map[string]interface{})
+							if !ok {
+								return nil, 0, 0, fmt.Errorf("could not parse Items")
+							}
+							inventoryItem := decodeInventoryItem(itemProperties, blob.Objects)
+							characterData.Items = append(characterData.Items, inventoryItem.Name)
+							characterData.Inventory = append(characterData.Inventory, inventoryItem)
 						}
 					}
 				}
+				characterData.Progression = decodeProgression(primary, secondary, characterData.Inventory, characterDataObj.Components)
 				break
 			}
 		}
 		charactersData[characterData.ID] = characterData
 	}
 
-	return charactersData, activeCharacterID, nil
+	return charactersData, activeCharacterID, archive.Header.Crc, nil
 }
 
-func printCharacter(characterData CharacterData, zoneInfo ZoneInfo) {
-	fmt.Print("\033[2J")
+// printCharacters prints the worlds of the given characters with only the
+// events of the given kinds, or all of them. New wishlist matches ring the
+// terminal bell and are shown in a banner, above the text output or on
+// stderr for the other formats.
+// printCharacters prints the worlds of the given characters. With all set,
+// formats that can hold several characters write them as one document.
+func printCharacters(renderer Renderer, session *Session, characterIDs []int32, all bool, only []EventKind, changes map[int32]*WorldChanges, wishes map[int32][]WishMatch) {
+	textRenderer, isText := renderer.(*TextRenderer)
+	if isText {
+		fmt.Print("\033[2J")
+	}
+	multi, isMulti := renderer.(multiRenderer)
+	isMulti = isMulti && all
+	var documents []worldDocument
+
+	for i, characterID := range characterIDs {
+		var banner []string
+		for _, match := range wishes[characterID] {
+			banner = append(banner, "Wishlist match: "+match.String())
+		}
+		if len(banner) > 0 {
+			if isText {
+				fmt.Print("\a")
+			} else {
+				fmt.Fprint(os.Stderr, "\a"+strings.Join(banner, "\n")+"\n")
+			}
+		}
 
-	fmt.Printf("%-11s %s\n", "Archetype:", characterData.Archetype)
+		if isText {
+			textRenderer.Changes = changes[characterID]
+			textRenderer.Banner = banner
+			if i > 0 {
+				fmt.Println()
+			}
+		}
+
+		zoneInfo := filterWorld(session.Zones[characterID], only)
+		if isMulti {
+			documents = append(documents, worldDocument{Character: session.Characters[characterID], World: zoneInfo})
+			continue
+		}
+		err := renderer.Render(os.Stdout, session.Characters[characterID], zoneInfo)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
 
-	characterType := strings.TrimPrefix(characterData.Type, "ERemnantCharacterType::")
-	fmt.Printf("%-11s %s\n", "Character:", characterType)
-	fmt.Printf("%-11s %s\n", "Biome:", remnant.BiomeNames[zoneInfo.Biome])
+	if isMulti {
+		err := multi.RenderAll(os.Stdout, documents)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+}
 
-	if zoneInfo.Biome == "Jungle" {
-		fmt.Printf("%-11s %v\n\n", "Blood Moon:", zoneInfo.BloodMoon)
+// checkWishes returns the new wishlist matches of the given characters and
+// runs the wishlist command for them.
+func checkWishes(tracker *wishTracker, session *Session, characterIDs []int32) map[int32][]WishMatch {
+	wishes := map[int32][]WishMatch{}
+	for _, characterID := range characterIDs {
+		zoneInfo, ok := session.Zones[characterID]
+		if !ok {
+			continue
+		}
+		wishes[characterID] = tracker.Check(characterID, zoneInfo)
+		for _, match := range wishes[characterID] {
+			tracker.runWishCommand(session.Characters[characterID], zoneInfo, match)
+		}
 	}
+	return wishes
+}
 
-	printTreeWithItems(zoneInfo.ZoneActor, "")
+// settingsError is a user catalog or REFINDER_LANG that can not be loaded.
+type settingsError struct {
+	err error
 }
 
-func main() {
-	characters := map[int32]CharacterData{}
-	activeCharacterID := int32(-1)
-	characterZones := map[int32]ZoneInfo{}
+func (e *settingsError) Error() string {
+	return e.err.Error()
+}
 
-	basePath := path.Join(os.Getenv("USERPROFILE"), "Saved Games", "Remnant2", "Steam")
-	userFolders, err := os.ReadDir(basePath)
-	if err != nil {
-		if !os.IsNotExist(err) {
-			log.Fatal(err)
+func (e *settingsError) Unwrap() error {
+	return e.err
+}
+
+// loadSettings loads the user catalog, and the language of REFINDER_LANG
+// unless --lang was given. It runs after the flags are parsed, so commands
+// can report the errors their own way.
+func loadSettings(flags *flag.FlagSet) error {
+	if path := catalog.UserFile(); path != "" {
+		err := itemCatalog.Load(path)
+		if err != nil {
+			return &settingsError{err}
 		}
 	}
-	if len(userFolders) == 0 {
-		basePath = path.Join(os.Getenv("USERPROFILE"), "Saved Games", "Remnant2")
-		userFolders, err := os.ReadDir(basePath)
+	langSet := false
+	flags.Visit(func(f *flag.Flag) {
+		langSet = langSet || f.Name == "lang"
+	})
+	if lang := os.Getenv(locale.EnvVar); lang != "" && !langSet {
+		err := setLanguage(lang)
 		if err != nil {
-			if !os.IsNotExist(err) {
+			return &settingsError{fmt.Errorf("%s: %w", locale.EnvVar, err)}
+		}
+	}
+	return nil
+}
+
+func main() {
+	if len(os.Args) > 1 {
+		if command, ok := commands[os.Args[1]]; ok {
+			err := command.Run(os.Args[2:])
+			var exit *exitError
+			if errors.As(err, &exit) {
+				if exit.Err != nil {
+					log.Println(exit.Err)
+				}
+				os.Exit(exit.Code)
+			}
+			if err != nil {
 				log.Fatal(err)
 			}
+			return
 		}
-		if len(userFolders) == 0 {
-			log.Fatal("Could not find user folders")
-		}
 	}
 
-	basePath = path.Join(basePath, userFolders[0].Name())
-	fullPath := path.Join(basePath, "profile.sav")
+	format := flag.String("format", "text", fmt.Sprintf("output format (%s)", strings.Join(rendererFormats(), ", ")))
+	once := flag.Bool("once", false, "print the current world and exit instead of watching for changes")
+	saveDir := addSaveDirFlag(flag.CommandLine)
+	characterFlag := flag.Int("character", -1, "ID of the character to show (default: the active character, see list)")
+	all := flag.Bool("all", false, "show every character")
+	world := addWorldFlag(flag.CommandLine)
+	addLangFlag(flag.CommandLine)
+	ownedBy := addOwnedByFlag(flag.CommandLine)
+	var only []EventKind
+	flag.Func("only", "show only events of these kinds, like bosses,dungeons (story, world-boss, overworld-boss, boss, miniboss, dungeon, poi, injectable, other)", func(value string) error {
+		var err error
+		only, err = parseEventKinds(value)
+		return err
+	})
+	wishlistPath := flag.String("wishlist", "", "wishlist file, matches ring the bell and run its command")
+	var webhooks webhookURLs
+	flag.Var(&webhooks, "webhook", "URL to POST new rolls to, can be given more than once")
+	webhookFormat := flag.String("webhook-format", WebhookAuto, fmt.Sprintf("webhook body (%s), auto sends Discord embeds to Discord URLs", strings.Join(webhookFormats, ", ")))
+	textDefaults := NewTextRenderer()
+	indent := flag.String("indent", textDefaults.Indent, "text format: prefix repeated once per zone level")
+	ownedMark := flag.String("owned-mark", textDefaults.Owned, "text format: mark of items the character owns")
+	missingMark := flag.String("missing-mark", textDefaults.Missing, "text format: mark of items the character is missing")
+	flag.Parse()
+	err := loadSettings(flag.CommandLine)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	renderer, err := newRenderer(*format)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if textRenderer, ok := renderer.(*TextRenderer); ok {
+		textRenderer.Indent = *indent
+		textRenderer.Owned = *ownedMark
+		textRenderer.Missing = *missingMark
+	}
 
-	characters, activeCharacterID, err = refreshProfile(fullPath)
+	var tracker *wishTracker
+	if *wishlistPath != "" {
+		wishlist, err := loadWishlist(*wishlistPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		tracker = newWishTracker(wishlist)
+	}
+
+	notifier, err := newWebhookNotifier(webhooks, *webhookFormat)
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	fullPath = path.Join(basePath, fmt.Sprintf("save_%d.sav", activeCharacterID))
-	characterZones[activeCharacterID], err = refreshSaveFile(fullPath, characters[activeCharacterID])
+	// The watcher keeps working without a history, see refinder missing.
+	history, err := loadHistory(historyPath())
+	if err != nil {
+		log.Println("history:", err)
+	}
+
+	account, err := selectAccount(*saveDir)
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	printCharacter(characters[activeCharacterID], characterZones[activeCharacterID])
+	session := NewSession(account, *world)
+	session.OwnedBy = *ownedBy
+	err = session.RefreshProfile()
+	if err != nil {
+		log.Fatal(err)
+	}
 
-	// Create new watcher.
-	watcher, err := fsnotify.NewWatcher()
+	characterIDs, err := session.SelectCharacters(*characterFlag, *all)
 	if err != nil {
 		log.Fatal(err)
 	}
-	defer watcher.Close()
+	for _, characterID := range characterIDs {
+		_, err = session.RefreshCharacter(characterID)
+		if err != nil {
+			log.Fatal(err)
+		}
+		history.Record(session.Characters[characterID], session.Zones[characterID])
+	}
+	err = history.Save()
+	if err != nil {
+		log.Println("history:", err)
+	}
 
-	done := make(chan bool) // Create a channel to signal program termination
-	// Capture Ctrl+C signal (optional)
-	go func() {
-		sigint := make(chan os.Signal, 1)
-		signal.Notify(sigint, os.Interrupt)
-		<-sigint
-		done <- true
-	}()
-
-	// Start listening for events.
-	go func() {
-		for {
-			select {
-			case event, ok := <-watcher.Events:
-				if !ok {
-					return
-				}
-				if event.Has(fsnotify.Write) {
-					if !strings.HasSuffix(event.Name, ".sav") {
-						return
-					}
-					fullPath = path.Join(basePath, event.Name)
+	printCharacters(renderer, session, characterIDs, *all, only, nil, checkWishes(tracker, session, characterIDs))
+	if *once {
+		return
+	}
 
-					if event.Name == "profile.sav" {
-						characters, activeCharacterID, err = refreshProfile(fullPath)
-						if err != nil {
-							log.Fatal(err)
-						}
-						characterZones[activeCharacterID], err = refreshSaveFile(fullPath, characters[activeCharacterID])
-						if err != nil {
-							log.Fatal(err)
-						}
+	watcher, err := watchSaves(session.Account, func(name string) {
+		changes := map[int32]*WorldChanges{}
 
-						printCharacter(characters[activeCharacterID], characterZones[activeCharacterID])
-					} else if strings.HasPrefix(event.Name, "save_") {
-						characterID, err := strconv.ParseInt(strings.Split(strings.Split(event.Name, "_")[1], ".")[0], 10, 32)
-						if err != nil {
-							log.Fatal(err)
-						}
-						characterZones[int32(characterID)], err = refreshSaveFile(fullPath, characters[int32(characterID)])
-						if err != nil {
-							log.Fatal(err)
-						}
+		// The session is only used by this callback from here on.
+		name, err := session.changedFile(name)
+		if err != nil {
+			log.Println("error:", err)
+			return
+		}
+		if name == profileFileName {
+			err := session.RefreshProfile()
+			if err != nil {
+				log.Println("error:", err)
+				return
+			}
+			// Ownership lives in the profile, the worlds have to be
+			// matched against the new inventory. The active character
+			// may have changed as well.
+			characterIDs, err = session.SelectCharacters(*characterFlag, *all)
+			if err != nil {
+				log.Println("error:", err)
+				return
+			}
+		} else if characterID, ok := parseSaveFileName(name); !ok || !slices.Contains(characterIDs, characterID) {
+			return
+		}
 
-						if characterID == int64(activeCharacterID) {
-							printCharacter(characters[activeCharacterID], characterZones[activeCharacterID])
-						}
-					}
-				}
-			case err, ok := <-watcher.Errors:
-				if !ok {
-					return
-				}
+		for _, characterID := range characterIDs {
+			if name != profileFileName && name != saveFileName(characterID) {
+				continue
+			}
+			previous := session.Zones[characterID]
+			characterChanges, err := session.RefreshCharacter(characterID)
+			if err != nil {
 				log.Println("error:", err)
+				return
+			}
+			changes[characterID] = characterChanges
+			// Changes are nil the first time a world is read, there is no
+			// roll to compare with.
+			if characterChanges != nil {
+				notifier.Notify(session.Characters[characterID], previous, session.Zones[characterID])
 			}
+			history.Record(session.Characters[characterID], session.Zones[characterID])
+		}
+		err = history.Save()
+		if err != nil {
+			log.Println("history:", err)
 		}
-	}()
 
-	err = watcher.Add(basePath)
+		printCharacters(renderer, session, characterIDs, *all, only, changes, checkWishes(tracker, session, characterIDs))
+	})
 	if err != nil {
-		log.Print(err)
-		return
+		log.Fatal(err)
 	}
+	defer watcher.Close()
 
 	// Wait for a signal to terminate the program
-	<-done
+	sigint := make(chan os.Signal, 1)
+	signal.Notify(sigint, os.Interrupt)
+	<-sigint
 	fmt.Println("Closing application...")
 }
@@ -17,3 +17,7 @@ func ReadInt[T Int](r io.Reader) (T, error) {
 	}
 	return value, nil
 }
+
+func WriteInt[T Int](w io.Writer, value T) error {
+	return binary.Write(w, binary.LittleEndian, value)
+}
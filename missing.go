@@ -0,0 +1,228 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"slices"
+	"strings"
+)
+
+type missingItem struct {
+	Name        string `json:"name"`
+	DisplayName string `json:"display_name"`
+	// Source is the event that rewards the item, empty for world loot.
+	Source string `json:"source,omitempty"`
+}
+
+type missingZone struct {
+	Zone  string        `json:"zone"`
+	Items []missingItem `json:"items"`
+}
+
+// unseenBiome counts the cataloged items of a biome that no recorded roll
+// had. Items neither the catalog nor the history places in one biome are
+// counted under "".
+type unseenBiome struct {
+	Biome string   `json:"biome"`
+	Count int      `json:"count"`
+	Items []string `json:"items"`
+}
+
+type missingReport struct {
+	Character int32         `json:"character"`
+	World     string        `json:"world"`
+	Biome     string        `json:"biome"`
+	Hardcore  bool          `json:"hardcore"`
+	Zones     []missingZone `json:"zones"`
+	Unseen    []unseenBiome `json:"unseen"`
+}
+
+// collectMissing adds the zones of the tree that have unowned items or
+// rewards, in tree order.
+func collectMissing(zone *ZoneActor, zones *[]missingZone) {
+	if zone == nil {
+		return
+	}
+
+	entry := missingZone{Zone: zone.Label}
+	for _, item := range zone.Items {
+		if !isMaterial(item.Name) && !item.OwnedByCharacter {
+			entry.Items = append(entry.Items, missingItem{Name: item.Name, DisplayName: getPrintableName(item.Name)})
+		}
+	}
+	for _, event := range zone.Events {
+		for _, reward := range event.Rewards {
+			if !reward.OwnedByCharacter {
+				entry.Items = append(entry.Items, missingItem{Name: reward.ActorBP, DisplayName: getPrintableName(reward.ActorBP), Source: getPrintableName(event.Name)})
+			}
+		}
+	}
+	if len(entry.Items) > 0 {
+		*zones = append(*zones, entry)
+	}
+
+	for _, child := range zone.Children {
+		collectMissing(child, zones)
+	}
+}
+
+// collectUnseen counts the collectible catalog items the history of hardcore
+// or of standard characters never saw, per biome. The biome is that of the
+// catalog, or the one the other kind of characters saw the item in.
+func collectUnseen(history *seenHistory, hardcore bool) []unseenBiome {
+	biomes := map[string]*unseenBiome{}
+	for _, item := range itemCatalog.Items() {
+		if !isCollectible(item) || history.Seen(item.Class, hardcore) {
+			continue
+		}
+		biome := item.Biome
+		if biome == "" {
+			biome = history.Biome(item.Class)
+		}
+		entry, ok := biomes[biome]
+		if !ok {
+			entry = &unseenBiome{Biome: biome}
+			biomes[biome] = entry
+		}
+		entry.Count++
+		entry.Items = append(entry.Items, item.Class)
+	}
+
+	unseen := []unseenBiome{}
+	for _, entry := range biomes {
+		unseen = append(unseen, *entry)
+	}
+	// Items with an unknown biome come last.
+	slices.SortFunc(unseen, func(a, b unseenBiome) int {
+		if (a.Biome == "") != (b.Biome == "") {
+			if a.Biome == "" {
+				return 1
+			}
+			return -1
+		}
+		return strings.Compare(getBiomeName(a.Biome), getBiomeName(b.Biome))
+	})
+	return unseen
+}
+
+func printMissing(w io.Writer, report missingReport, verbose bool) {
+	fmt.Fprintf(w, "Missing in the %s (%s) of character %d:\n", report.World, report.Biome, report.Character)
+	if len(report.Zones) == 0 {
+		fmt.Fprintln(w, "  nothing, every item is owned")
+	}
+	for _, zone := range report.Zones {
+		fmt.Fprintf(w, "  %s\n", zone.Zone)
+		for _, item := range zone.Items {
+			source := "loot"
+			if item.Source != "" {
+				source = item.Source
+			}
+			fmt.Fprintf(w, "    %s (%s)\n", item.DisplayName, source)
+		}
+	}
+
+	if report.Hardcore {
+		fmt.Fprintln(w, "\nNever seen in a roll of a hardcore character:")
+	} else {
+		fmt.Fprintln(w, "\nNever seen in a roll:")
+	}
+	if len(report.Unseen) == 0 {
+		fmt.Fprintln(w, "  nothing, every cataloged item was seen")
+	}
+	for _, entry := range report.Unseen {
+		biome := "biome unknown"
+		if entry.Biome != "" {
+			biome = getBiomeName(entry.Biome)
+		}
+		fmt.Fprintf(w, "  %-13s %d\n", biome, entry.Count)
+		if verbose {
+			for _, class := range entry.Items {
+				fmt.Fprintf(w, "    %s\n", getPrintableName(class))
+			}
+		}
+	}
+}
+
+func runMissing(args []string) error {
+	flags := flag.NewFlagSet("missing", flag.ExitOnError)
+	format := flags.String("format", "text", "output format (text or json)")
+	characterFlag := flags.Int("character", -1, "ID of the character (default: the active character)")
+	verbose := flags.Bool("v", false, "list the items that were never seen")
+	saveDir := addSaveDirFlag(flags)
+	world := addWorldFlag(flags)
+	ownedBy := addOwnedByFlag(flags)
+	addLangFlag(flags)
+	positional, err := parseCommandArgs(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 0 || *format != "text" && *format != "json" {
+		return fmt.Errorf("usage: %s", commands["missing"].Usage)
+	}
+
+	history, err := loadHistory(historyPath())
+	if err != nil {
+		return err
+	}
+
+	account, err := selectAccount(*saveDir)
+	if err != nil {
+		return err
+	}
+	session := NewSession(account, *world)
+	session.OwnedBy = *ownedBy
+	err = session.RefreshProfile()
+	if err != nil {
+		return err
+	}
+	characterIDs, err := session.SelectCharacters(*characterFlag, false)
+	if err != nil {
+		return err
+	}
+	characterID := characterIDs[0]
+
+	// Every readable world goes into the history, not only the one shown.
+	// Characters without a world are skipped, other characters whose world
+	// can not be read are logged so their history does not stop unnoticed.
+	for _, id := range session.CharacterIDs() {
+		_, err := session.RefreshCharacter(id)
+		if err != nil {
+			if id == characterID {
+				return err
+			}
+			if !errors.Is(err, errNoWorld) {
+				log.Printf("history: character %d: %v\n", id, err)
+			}
+			continue
+		}
+		history.Record(session.Characters[id], session.Zones[id])
+	}
+	err = history.Save()
+	if err != nil {
+		return err
+	}
+
+	zoneInfo := session.Zones[characterID]
+	report := missingReport{
+		Character: characterID,
+		World:     zoneInfo.Mode,
+		Biome:     getBiomeName(zoneInfo.Biome),
+		Zones:     []missingZone{},
+		Hardcore:  isHardcore(session.Characters[characterID]),
+		Unseen:    collectUnseen(history, isHardcore(session.Characters[characterID])),
+	}
+	collectMissing(zoneInfo.ZoneActor, &report.Zones)
+
+	if *format == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	}
+	printMissing(os.Stdout, report, *verbose)
+	return nil
+}
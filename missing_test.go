@@ -0,0 +1,114 @@
+package main
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"path/filepath"
+	"refinder/remnant"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestCollectUnseen(t *testing.T) {
+	history := &seenHistory{Items: map[string]*seenItem{"Weapon_Nightfall_C": {}}, Hardcore: map[string]*seenItem{}}
+
+	counts := map[string]int{}
+	for _, entry := range collectUnseen(history, false) {
+		counts[entry.Biome] = entry.Count
+	}
+	fae, all := 0, 0
+	for _, category := range collectionCategories {
+		fae += countCatalog(category.Category, "Fae")
+		all += countCatalog(category.Category, "")
+	}
+	if counts["Fae"] != fae-1 {
+		t.Errorf("unseen in Fae = %d, want %d", counts["Fae"], fae-1)
+	}
+
+	total := 0
+	for _, count := range counts {
+		total += count
+	}
+	// Currencies and materials of the catalog are no collection category.
+	if total != all-1 {
+		t.Errorf("unseen = %d, want %d", total, all-1)
+	}
+	// The catalog places most items in a biome.
+	if counts[""] > total/2 {
+		t.Errorf("%d of %d unseen items have no biome", counts[""], total)
+	}
+}
+
+func TestCollectUnseenHistoryBiome(t *testing.T) {
+	if item := itemCatalog.Lookup("Weapon_CoachGun_C"); item.Biome != "" {
+		t.Fatalf("Weapon_CoachGun_C has the biome %s in the catalog", item.Biome)
+	}
+	history := &seenHistory{
+		Items:    map[string]*seenItem{"Weapon_CoachGun_C": {Biomes: []string{"Jungle"}}, "Weapon_ScrapHammer_C": {Biomes: []string{"Fae", "Jungle"}}},
+		Hardcore: map[string]*seenItem{},
+	}
+
+	biomes := map[string][]string{}
+	for _, entry := range collectUnseen(history, true) {
+		biomes[entry.Biome] = entry.Items
+	}
+	// Hardcore characters never saw them, standard ones saw the coach gun
+	// in one biome and the hammer in two.
+	if !slices.Contains(biomes["Jungle"], "Weapon_CoachGun_C") {
+		t.Errorf("Weapon_CoachGun_C is not unseen in Jungle")
+	}
+	if !slices.Contains(biomes[""], "Weapon_ScrapHammer_C") {
+		t.Errorf("Weapon_ScrapHammer_C is not unseen in an unknown biome")
+	}
+}
+
+func TestPrintMissingUnseen(t *testing.T) {
+	report := missingReport{Unseen: []unseenBiome{{Biome: "Fae", Count: 2}, {Count: 40}}}
+	var out strings.Builder
+	printMissing(&out, report, false)
+	for _, want := range []string{getBiomeName("Fae") + " ", "biome unknown 40"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output %q does not contain %q", out.String(), want)
+		}
+	}
+}
+
+func TestRunMissingLogsUnreadableWorlds(t *testing.T) {
+	t.Setenv(HistoryEnvVar, filepath.Join(t.TempDir(), "history.json"))
+	dir := t.TempDir()
+	writeCheckAccount(t, dir, "Nerud", nil)
+	broken := writeBrokenWorld(t)
+	err := os.Rename(filepath.Join(broken, saveFileName(0)), filepath.Join(dir, saveFileName(1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	characterBlob := remnant.PersistenceBlob{Archive: remnant.SaveData{PackageVersion: &remnant.PackageVersion{}, NamesTable: []string{"None"}}}
+	savedCharacter := func(id int32) remnant.UObject {
+		return testObject("SavedCharacter",
+			remnant.Property{Name: "ID", Type: "IntProperty", Value: id},
+			remnant.Property{Name: "Archetype", Type: "StrProperty", Value: "/Game/Archetype_Hunter_UI.Archetype_Hunter_UI_C"},
+			remnant.Property{Name: "SecondaryArchetype", Type: "StrProperty", Value: "/Game/Archetype_Medic_UI.Archetype_Medic_UI_C"},
+			remnant.Property{Name: "CharacterData", Type: "StructProperty", Value: remnant.StructProperty{Name: "PersistenceBlob", Value: characterBlob}},
+		)
+	}
+	writeTestArchive(t, filepath.Join(dir, profileFileName), testSaveArchive(remnant.REMNANT_SAVE_GAME_PROFILE, savedCharacter(0), savedCharacter(1)))
+
+	var logged bytes.Buffer
+	log.SetOutput(&logged)
+	defer log.SetOutput(os.Stderr)
+	stdout := os.Stdout
+	os.Stdout, _ = os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	defer func() { os.Stdout = stdout }()
+
+	// The world of character 1 can not be read, missing still answers for
+	// character 0 but says why the history of character 1 stops.
+	err = runMissing([]string{"--save-dir", dir, "--character", "0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(logged.String(), "character 1") {
+		t.Errorf("log %q does not name character 1", logged.String())
+	}
+}
@@ -0,0 +1,266 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"log"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// The overlay is meant for an OBS browser source: a transparent page with
+// the few facts about the current roll a stream wants to show. The layout is
+// picked with query parameters so every scene can use its own URL:
+//
+//	character  character ID, default the active character
+//	show       comma separated sections: biome, bloodmoon, bosses, dungeons, missing
+//	align      left or right
+//	size       font size in pixels
+//	color      text color as hex, like ffffff
+//	limit      maximum number of missing items
+
+var overlaySections = []string{"biome", "bloodmoon", "bosses", "dungeons", "missing"}
+
+type overlayEvent struct {
+	Name   string      `json:"name"`
+	Zone   string      `json:"zone"`
+	Status EventStatus `json:"status"`
+}
+
+type overlayItem struct {
+	Name   string `json:"name"`
+	Zone   string `json:"zone"`
+	Source string `json:"source,omitempty"`
+}
+
+type overlayData struct {
+	Character int32          `json:"character"`
+	Archetype string         `json:"archetype"`
+	Biome     string         `json:"biome"`
+	BloodMoon bool           `json:"blood_moon"`
+	Bosses    []overlayEvent `json:"bosses"`
+	Dungeons  []overlayEvent `json:"dungeons"`
+	Missing   []overlayItem  `json:"missing"`
+}
+
+func collectOverlay(zone *ZoneActor, data *overlayData) {
+	if zone == nil {
+		return
+	}
+
+	for _, item := range zone.Items {
+		if !isMaterial(item.Name) && !item.OwnedByCharacter {
+			data.Missing = append(data.Missing, overlayItem{Name: getPrintableName(item.Name), Zone: zone.Label})
+		}
+	}
+	for _, event := range zone.Events {
+		overlayEvent := overlayEvent{Name: getPrintableName(event.Name), Zone: zone.Label, Status: event.Status}
+		switch event.Kind {
+		case EventWorldBoss, EventOverworldBoss, EventBoss, EventMiniboss:
+			data.Bosses = append(data.Bosses, overlayEvent)
+		case EventDungeon:
+			data.Dungeons = append(data.Dungeons, overlayEvent)
+		}
+		for _, reward := range event.Rewards {
+			if !reward.OwnedByCharacter {
+				data.Missing = append(data.Missing, overlayItem{Name: getPrintableName(reward.ActorBP), Zone: zone.Label, Source: overlayEvent.Name})
+			}
+		}
+	}
+
+	for _, child := range zone.Children {
+		collectOverlay(child, data)
+	}
+}
+
+func newOverlayData(characterData CharacterData, zoneInfo ZoneInfo) overlayData {
+	data := overlayData{
+		Character: characterData.ID,
+		Archetype: characterData.Archetype,
+		Biome:     getBiomeName(zoneInfo.Biome),
+		BloodMoon: zoneInfo.BloodMoon,
+		Bosses:    []overlayEvent{},
+		Dungeons:  []overlayEvent{},
+		Missing:   []overlayItem{},
+	}
+	collectOverlay(zoneInfo.ZoneActor, &data)
+	return data
+}
+
+type overlayLayout struct {
+	Show  map[string]bool
+	Align string
+	Size  int
+	Color string
+	Limit int
+}
+
+var hexColorPattern = regexp.MustCompile(`^[0-9a-fA-F]{3}([0-9a-fA-F]{3})?$`)
+
+func parseOverlayLayout(r *http.Request) (overlayLayout, error) {
+	query := r.URL.Query()
+	layout := overlayLayout{
+		Show:  map[string]bool{},
+		Align: "left",
+		Size:  24,
+		Color: "ffffff",
+		Limit: 10,
+	}
+
+	sections := overlaySections
+	if show := query.Get("show"); show != "" {
+		sections = strings.Split(show, ",")
+	}
+	for _, section := range sections {
+		if !slices.Contains(overlaySections, section) {
+			return layout, fmt.Errorf("unknown section %q (supported: %s)", section, strings.Join(overlaySections, ", "))
+		}
+		layout.Show[section] = true
+	}
+
+	if align := query.Get("align"); align != "" {
+		if align != "left" && align != "right" {
+			return layout, fmt.Errorf("align must be left or right")
+		}
+		layout.Align = align
+	}
+	if color := query.Get("color"); color != "" {
+		if !hexColorPattern.MatchString(color) {
+			return layout, fmt.Errorf("color must be a hex color like ffffff")
+		}
+		layout.Color = color
+	}
+
+	var err error
+	if size := query.Get("size"); size != "" {
+		layout.Size, err = strconv.Atoi(size)
+		if err != nil || layout.Size <= 0 {
+			return layout, fmt.Errorf("size must be a positive number")
+		}
+	}
+	if limit := query.Get("limit"); limit != "" {
+		layout.Limit, err = strconv.Atoi(limit)
+		if err != nil || layout.Limit < 0 {
+			return layout, fmt.Errorf("limit must be a number")
+		}
+	}
+
+	return layout, nil
+}
+
+// overlayCharacter returns the overlay of the character given by the
+// character query parameter or the active one.
+func (s *server) overlayCharacter(r *http.Request) (overlayData, int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	characterID := s.session.ActiveCharacterID
+	if character := r.URL.Query().Get("character"); character != "" {
+		id, err := strconv.ParseInt(character, 10, 32)
+		if err != nil {
+			return overlayData{}, http.StatusBadRequest, fmt.Errorf("invalid character %q", character)
+		}
+		characterID = int32(id)
+	}
+
+	characterData, ok := s.session.Characters[characterID]
+	if !ok {
+		return overlayData{}, http.StatusNotFound, fmt.Errorf("character %d does not exist", characterID)
+	}
+	if err, ok := s.errors[characterID]; ok {
+		return overlayData{}, http.StatusServiceUnavailable, err
+	}
+	return newOverlayData(characterData, s.session.Zones[characterID]), http.StatusOK, nil
+}
+
+func (s *server) handleOverlayJSON(w http.ResponseWriter, r *http.Request) {
+	data, status, err := s.overlayCharacter(r)
+	if err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache")
+	json.NewEncoder(w).Encode(data)
+}
+
+var overlayTemplate = template.Must(template.New("overlay").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>ReFinder overlay</title>
+<style>
+html, body { background: transparent; margin: 0; }
+body {
+	font-family: sans-serif;
+	font-size: {{.Layout.Size}}px;
+	color: #{{.Layout.Color}};
+	text-align: {{.Layout.Align}};
+	text-shadow: 0 0 4px #000, 0 0 2px #000;
+	padding: 0.5em;
+}
+div { margin-bottom: 0.4em; }
+ul { list-style: none; margin: 0; padding: 0; }
+.label { opacity: 0.7; font-size: 0.7em; text-transform: uppercase; }
+.zone { opacity: 0.7; font-size: 0.8em; }
+</style>
+</head>
+<body>
+{{if .Layout.Show.biome}}<div class="biome">{{.Data.Biome}}</div>{{end}}
+{{if and .Layout.Show.bloodmoon .Data.BloodMoon}}<div class="bloodmoon">Blood Moon</div>{{end}}
+{{if and .Layout.Show.bosses .Data.Bosses}}<div class="bosses"><div class="label">Bosses</div><ul>
+{{range .Data.Bosses}}<li>{{.Name}} <span class="zone">{{.Zone}}</span></li>{{end}}
+</ul></div>{{end}}
+{{if and .Layout.Show.dungeons .Data.Dungeons}}<div class="dungeons"><div class="label">Dungeons</div><ul>
+{{range .Data.Dungeons}}<li>{{.Name}} <span class="zone">{{.Zone}}{{if eq .Status "completed"}}, cleared{{end}}</span></li>{{end}}
+</ul></div>{{end}}
+{{if and .Layout.Show.missing .Missing}}<div class="missing"><div class="label">Missing</div><ul>
+{{range .Missing}}<li>{{.Name}} <span class="zone">{{.Zone}}</span></li>{{end}}
+{{if .MoreMissing}}<li class="zone">and {{.MoreMissing}} more</li>{{end}}
+</ul></div>{{end}}
+<script>
+new EventSource("/events").addEventListener("update", () => location.reload());
+</script>
+</body>
+</html>
+`))
+
+func (s *server) handleOverlay(w http.ResponseWriter, r *http.Request) {
+	layout, err := parseOverlayLayout(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	data, status, err := s.overlayCharacter(r)
+	if err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	missing := data.Missing
+	if len(missing) > layout.Limit {
+		missing = missing[:layout.Limit]
+	}
+
+	var buf bytes.Buffer
+	err = overlayTemplate.Execute(&buf, struct {
+		Data        overlayData
+		Layout      overlayLayout
+		Missing     []overlayItem
+		MoreMissing int
+	}{data, layout, missing, len(data.Missing) - len(missing)})
+	if err != nil {
+		log.Println("error:", err)
+		http.Error(w, "could not render overlay", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	buf.WriteTo(w)
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestOverlayMissing(t *testing.T) {
+	tests := []struct {
+		name    string
+		item    ItemData
+		missing bool
+	}{
+		{"unowned weapon", ItemData{Name: "Weapon_Nightfall_C"}, true},
+		{"owned weapon", ItemData{Name: "Weapon_Nightfall_C", OwnedByCharacter: true}, false},
+		{"material", ItemData{Name: "Material_LumeniteCrystal_C"}, false},
+		{"scrap", ItemData{Name: "Material_Scrap_C"}, false},
+		{"relic fragment", ItemData{Name: "GemContainer_RedGems_C"}, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			data := newOverlayData(CharacterData{}, ZoneInfo{ZoneActor: &ZoneActor{Label: "Zone", Items: []ItemData{test.item}}})
+			missing := slices.ContainsFunc(data.Missing, func(item overlayItem) bool {
+				return item.Name == getPrintableName(test.item.Name)
+			})
+			if missing != test.missing {
+				t.Errorf("missing = %t, want %t (%+v)", missing, test.missing, data.Missing)
+			}
+		})
+	}
+}
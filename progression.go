@@ -0,0 +1,205 @@
+package main
+
+import (
+	"refinder/catalog"
+	"refinder/remnant"
+	"slices"
+	"strings"
+)
+
+// Progression is what a character built up: the levels of its archetypes,
+// its traits and the prime perk of its primary archetype. Archetypes,
+// traits and perks are inventory items of the character, the Traits
+// component adds the trait levels, the trait points and which traits are
+// assigned.
+type Progression struct {
+	Archetypes []ArchetypeProgress `json:"archetypes"`
+	Traits     []TraitProgress     `json:"traits"`
+	// TraitPoints are the points that are not spent on a trait yet.
+	TraitPoints int32      `json:"trait_points"`
+	PrimePerk   *PrimePerk `json:"prime_perk"`
+	// Missing lists the properties of the Traits component that are not in
+	// the save.
+	Missing []string `json:"missing,omitempty"`
+}
+
+type ArchetypeProgress struct {
+	// Name is the archetype without prefix and suffix, like Hunter.
+	Name       string `json:"name"`
+	Level      int32  `json:"level"`
+	Experience int32  `json:"experience"`
+	Primary    bool   `json:"primary"`
+	Secondary  bool   `json:"secondary"`
+}
+
+type TraitProgress struct {
+	Name     string `json:"name"`
+	Level    int32  `json:"level"`
+	Assigned bool   `json:"assigned"`
+}
+
+// PrimePerk is the prime perk of an archetype. Name is empty when the
+// catalog does not know the perk, Unlocked tells whether the character has
+// it with a level.
+type PrimePerk struct {
+	Archetype string `json:"archetype"`
+	Name      string `json:"name,omitempty"`
+	Unlocked  bool   `json:"unlocked"`
+	Level     int32  `json:"level"`
+}
+
+// primePerk returns the class of the prime perk of an archetype, the perk
+// of the Prime Perk subcategory the catalog relates to the archetype.
+func primePerk(archetype string) string {
+	for _, item := range itemCatalog.Items() {
+		if item.Category == catalog.Perk && item.Subcategory == "Prime Perk" && slices.Contains(item.Related, "Archetype_"+archetype+"_C") {
+			return item.Class
+		}
+	}
+	return ""
+}
+
+// archetypeKey returns the archetype of an archetype class, like Hunter for
+// Archetype_Hunter_C.
+func archetypeKey(class string) string {
+	return strings.TrimSuffix(strings.TrimSuffix(strings.TrimPrefix(class, "Archetype_"), "_C"), "_UI")
+}
+
+// className returns the class of an object reference, like Trait_Barkskin_C
+// for /Game/.../Trait_Barkskin.Trait_Barkskin_C.
+func className(property remnant.ObjectProperty) string {
+	parts := strings.Split(property.ClassName, ".")
+	return parts[len(parts)-1]
+}
+
+// structArray returns the structs of an array property of a component.
+func (r *componentReader) structArray(name string) []map[string]interface{} {
+	array, ok := r.properties[name].(remnant.ArrayStructProperty)
+	if !ok {
+		r.missing = append(r.missing, name)
+		return nil
+	}
+	structs := []map[string]interface{}{}
+	for _, item := range array.Items {
+		if properties, ok := item.Value.(map[string]interface{}); ok {
+			structs = append(structs, properties)
+		}
+	}
+	return structs
+}
+
+// objectArray returns the classes of an array of object references.
+func (r *componentReader) objectArray(name string) []string {
+	array, ok := r.properties[name].(remnant.ArrayProperty)
+	if !ok {
+		r.missing = append(r.missing, name)
+		return nil
+	}
+	classes := []string{}
+	for _, item := range array.Items {
+		if object, ok := item.(remnant.ObjectProperty); ok {
+			classes = append(classes, className(object))
+		}
+	}
+	return classes
+}
+
+func decodeProgression(primary, secondary string, inventory []InventoryItem, components []remnant.Component) Progression {
+	progression := Progression{Archetypes: []ArchetypeProgress{}, Traits: []TraitProgress{}}
+	traits := map[string]*TraitProgress{}
+	addTrait := func(name string, level int32) *TraitProgress {
+		trait, ok := traits[name]
+		if !ok {
+			trait = &TraitProgress{Name: name}
+			traits[name] = trait
+		}
+		trait.Level = max(trait.Level, level)
+		return trait
+	}
+
+	perks := map[string]InventoryItem{}
+	for _, item := range inventory {
+		switch {
+		case strings.HasPrefix(item.Name, "Archetype_"):
+			name := archetypeKey(item.Name)
+			progression.Archetypes = append(progression.Archetypes, ArchetypeProgress{
+				Name:       name,
+				Level:      item.Level,
+				Experience: item.Experience,
+				Primary:    name == primary,
+				Secondary:  name == secondary,
+			})
+		case itemCatalog.Lookup(item.Name).Category == catalog.Trait:
+			addTrait(item.Name, item.Level)
+		case strings.HasPrefix(item.Name, "Perk_"):
+			perks[item.Name] = item
+		}
+	}
+
+	r := componentReader{}
+	for _, component := range components {
+		if component.ComponentKey == "Traits" {
+			r.properties = component.Properties
+		}
+	}
+	if r.properties == nil {
+		progression.Missing = []string{"Traits"}
+	} else {
+		for _, entry := range r.structArray("Traits") {
+			trait := componentReader{properties: entry}
+			level := trait.int("Level")
+			if bp, ok := entry["TraitBP"].(remnant.ObjectProperty); ok {
+				addTrait(className(bp), level)
+			} else {
+				trait.missing = append(trait.missing, "TraitBP")
+			}
+			for _, name := range trait.missing {
+				r.missing = append(r.missing, "Traits."+name)
+			}
+		}
+		for _, class := range r.objectArray("AssignedTraits") {
+			addTrait(class, 0).Assigned = true
+		}
+		progression.TraitPoints = r.int("TraitPoints")
+		slices.Sort(r.missing)
+		progression.Missing = slices.Compact(r.missing)
+	}
+	for _, trait := range traits {
+		progression.Traits = append(progression.Traits, *trait)
+	}
+
+	// Primary first, then the secondary archetype, then by level.
+	slices.SortFunc(progression.Archetypes, func(a, b ArchetypeProgress) int {
+		if a.Primary != b.Primary {
+			if a.Primary {
+				return -1
+			}
+			return 1
+		}
+		if a.Secondary != b.Secondary {
+			if a.Secondary {
+				return -1
+			}
+			return 1
+		}
+		if a.Level != b.Level {
+			return int(b.Level - a.Level)
+		}
+		return strings.Compare(a.Name, b.Name)
+	})
+	slices.SortFunc(progression.Traits, func(a, b TraitProgress) int {
+		if a.Level != b.Level {
+			return int(b.Level - a.Level)
+		}
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	if primary != "" {
+		progression.PrimePerk = &PrimePerk{Archetype: primary, Name: primePerk(primary)}
+		if perk, ok := perks[progression.PrimePerk.Name]; ok {
+			progression.PrimePerk.Level = perk.Level
+			progression.PrimePerk.Unlocked = perk.Level > 0
+		}
+	}
+	return progression
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"refinder/remnant"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPrimePerk(t *testing.T) {
+	if perk := primePerk("Hunter"); perk != "Perk_DeadToRights_C" {
+		t.Errorf("prime perk of Hunter = %q, want Perk_DeadToRights_C", perk)
+	}
+	if perk := primePerk("Unknown"); perk != "" {
+		t.Errorf("prime perk of an unknown archetype = %q", perk)
+	}
+}
+
+func TestDecodeProgression(t *testing.T) {
+	object := func(class string) remnant.ObjectProperty {
+		return remnant.ObjectProperty{ClassName: "/Game/" + class + "." + class, Index: -1}
+	}
+	trait := func(class string, level int32) remnant.StructProperty {
+		return remnant.StructProperty{Name: "TraitEntry", Value: map[string]interface{}{"TraitBP": object(class), "Level": level}}
+	}
+	inventory := []InventoryItem{
+		{Name: "Archetype_Gunslinger_C", Level: 4, Experience: 900},
+		{Name: "Archetype_Hunter_C", Level: 10, Experience: 52000},
+		{Name: "Archetype_Medic_C", Level: 6},
+		{Name: "Trait_Amplify_C", Level: 1},
+		{Name: "Perk_DeadToRights_C", Level: 2},
+	}
+	traits := remnant.Component{ComponentKey: "Traits", Properties: map[string]interface{}{
+		"Traits": remnant.ArrayStructProperty{Items: []remnant.StructProperty{
+			trait("Trait_Vigor_C", 10), trait("Trait_Longshot_C", 3),
+		}},
+		"AssignedTraits": remnant.ArrayProperty{Items: []interface{}{object("Trait_Vigor_C")}},
+		"TraitPoints":    int32(5),
+	}}
+
+	progression := decodeProgression("Hunter", "Gunslinger", inventory, []remnant.Component{traits})
+	want := Progression{
+		Archetypes: []ArchetypeProgress{
+			{Name: "Hunter", Level: 10, Experience: 52000, Primary: true},
+			{Name: "Gunslinger", Level: 4, Experience: 900, Secondary: true},
+			{Name: "Medic", Level: 6},
+		},
+		// Levels alone do not assign a trait.
+		Traits: []TraitProgress{
+			{Name: "Trait_Vigor_C", Level: 10, Assigned: true},
+			{Name: "Trait_Longshot_C", Level: 3},
+			{Name: "Trait_Amplify_C", Level: 1},
+		},
+		TraitPoints: 5,
+		PrimePerk:   &PrimePerk{Archetype: "Hunter", Name: "Perk_DeadToRights_C", Unlocked: true, Level: 2},
+	}
+	if !reflect.DeepEqual(progression, want) {
+		t.Errorf("decodeProgression = %+v, want %+v", progression, want)
+	}
+}
+
+func TestDecodeProgressionMissing(t *testing.T) {
+	tests := []struct {
+		name       string
+		components []remnant.Component
+		missing    []string
+	}{
+		{"no component", nil, []string{"Traits"}},
+		{"other names", []remnant.Component{{ComponentKey: "Traits", Properties: map[string]interface{}{
+			"Traits": remnant.ArrayStructProperty{Items: []remnant.StructProperty{
+				{Value: map[string]interface{}{"Trait": remnant.ObjectProperty{}, "Rank": int32(1)}},
+				{Value: map[string]interface{}{"Trait": remnant.ObjectProperty{}, "Rank": int32(2)}},
+			}},
+			"EquippedTraits":  remnant.ArrayProperty{},
+			"AvailablePoints": int32(5),
+		}}}, []string{"AssignedTraits", "TraitPoints", "Traits.Level", "Traits.TraitBP"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			progression := decodeProgression("Medic", "", []InventoryItem{{Name: "Archetype_Medic_C", Level: 1}}, test.components)
+			if !reflect.DeepEqual(progression.Missing, test.missing) {
+				t.Errorf("missing = %q, want %q", progression.Missing, test.missing)
+			}
+			if len(progression.Traits) != 0 || progression.TraitPoints != 0 {
+				t.Errorf("traits %+v and %d points read from unknown properties", progression.Traits, progression.TraitPoints)
+			}
+			// Owning the archetype does not unlock its prime perk.
+			if perk := progression.PrimePerk; perk == nil || perk.Name != "Perk_Regenerator_C" || perk.Unlocked {
+				t.Errorf("prime perk = %+v, want a locked Perk_Regenerator_C", perk)
+			}
+		})
+	}
+}
+
+func TestPrintBuild(t *testing.T) {
+	report := buildReport{Character: 1, Archetype: "Hunter", Progression: Progression{
+		PrimePerk: &PrimePerk{Archetype: "Hunter", Name: "Perk_DeadToRights_C"},
+		Missing:   []string{"TraitPoints"},
+	}}
+	var out strings.Builder
+	err := printBuild(&out, report)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"Prime perk of Hunter: " + getItemName("Perk_DeadToRights_C") + ", locked", "Not in the save: TraitPoints"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output %q does not contain %q", out.String(), want)
+		}
+	}
+}
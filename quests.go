@@ -0,0 +1,142 @@
+package main
+
+import (
+	"refinder/remnant"
+	"strings"
+)
+
+// Quest objectives and points of interest keep their progress in components
+// of the quest actor. Fields a component does not have are listed in Missing
+// instead of being guessed, so a blueprint that stores them differently shows
+// up in the output.
+
+// EventStatus is how far a character got with an event.
+type EventStatus string
+
+const (
+	EventNotStarted EventStatus = "not started"
+	EventInProgress EventStatus = "in progress"
+	EventCompleted  EventStatus = "completed"
+)
+
+type QuestObjective struct {
+	// Key is the component key without the QuestObjective_ prefix.
+	Key       string `json:"key"`
+	State     string `json:"state"`
+	Progress  int32  `json:"progress"`
+	Required  int32  `json:"required"`
+	Completed bool   `json:"completed"`
+	// Missing lists the properties the component does not have.
+	Missing []string `json:"missing,omitempty"`
+}
+
+type POIComponent struct {
+	Kind       string   `json:"kind"`
+	Discovered bool     `json:"discovered"`
+	Completed  bool     `json:"completed"`
+	Missing    []string `json:"missing,omitempty"`
+}
+
+// Values of EQuestObjectiveState without the type prefix.
+const (
+	objectiveActive    = "Active"
+	objectiveCompleted = "Completed"
+)
+
+// componentReader reads properties of a component and notes the ones that
+// are missing or of another type.
+type componentReader struct {
+	properties map[string]interface{}
+	missing    []string
+}
+
+func (r *componentReader) bool(name string) bool {
+	value, ok := r.properties[name].(bool)
+	if !ok {
+		r.missing = append(r.missing, name)
+	}
+	return value
+}
+
+func (r *componentReader) int(name string) int32 {
+	switch value := r.properties[name].(type) {
+	case int32:
+		return value
+	case uint8:
+		return int32(value)
+	}
+	r.missing = append(r.missing, name)
+	return 0
+}
+
+// enum returns an enum value without its type prefix, like Completed for
+// EQuestObjectiveState::Completed. Byte enums are read as names.
+func (r *componentReader) enum(name string) string {
+	var value string
+	switch property := r.properties[name].(type) {
+	case remnant.EnumProperty:
+		value = property.EnumValue
+	case string:
+		value = property
+	default:
+		r.missing = append(r.missing, name)
+		return ""
+	}
+	if i := strings.LastIndex(value, "::"); i >= 0 {
+		value = value[i+2:]
+	}
+	return value
+}
+
+func decodeQuestObjective(key string, properties map[string]interface{}) QuestObjective {
+	r := componentReader{properties: properties}
+	objective := QuestObjective{
+		Key:      strings.TrimPrefix(key, "QuestObjective_"),
+		State:    r.enum("State"),
+		Progress: r.int("Progress"),
+		Required: r.int("Required"),
+	}
+	objective.Missing = r.missing
+
+	objective.Completed = objective.State == objectiveCompleted ||
+		objective.Required > 0 && objective.Progress >= objective.Required
+	return objective
+}
+
+// started reports whether an objective got any progress.
+func (objective QuestObjective) started() bool {
+	return objective.Completed || objective.Progress > 0 || objective.State == objectiveActive
+}
+
+func decodePOI(properties map[string]interface{}) *POIComponent {
+	r := componentReader{properties: properties}
+	poi := &POIComponent{
+		Kind:       r.enum("Type"),
+		Discovered: r.bool("Discovered"),
+		Completed:  r.bool("Completed"),
+	}
+	poi.Missing = r.missing
+	return poi
+}
+
+// getEventStatus tells how far an event got from its objectives and POI.
+func getEventStatus(objectives []QuestObjective, poi *POIComponent) EventStatus {
+	if poi != nil && poi.Completed {
+		return EventCompleted
+	}
+
+	completed, started := 0, false
+	for _, objective := range objectives {
+		if objective.Completed {
+			completed++
+		}
+		started = started || objective.started()
+	}
+	if len(objectives) > 0 && completed == len(objectives) {
+		return EventCompleted
+	}
+	if started || poi != nil && poi.Discovered {
+		return EventInProgress
+	}
+	return EventNotStarted
+}
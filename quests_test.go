@@ -0,0 +1,79 @@
+package main
+
+import (
+	"refinder/remnant"
+	"reflect"
+	"testing"
+)
+
+func TestDecodeQuestObjective(t *testing.T) {
+	state := func(value string) remnant.EnumProperty {
+		return remnant.EnumProperty{EnumType: "EQuestObjectiveState", EnumValue: "EQuestObjectiveState::" + value}
+	}
+	tests := []struct {
+		name       string
+		properties map[string]interface{}
+		want       QuestObjective
+	}{
+		{"completed state", map[string]interface{}{"State": state("Completed"), "Progress": int32(0), "Required": int32(0)},
+			QuestObjective{Key: "Kill", State: "Completed", Completed: true}},
+		{"active", map[string]interface{}{"State": state("Active"), "Progress": int32(1), "Required": int32(3)},
+			QuestObjective{Key: "Kill", State: "Active", Progress: 1, Required: 3}},
+		{"progress reached", map[string]interface{}{"State": state("Active"), "Progress": uint8(3), "Required": int32(3)},
+			QuestObjective{Key: "Kill", State: "Active", Progress: 3, Required: 3, Completed: true}},
+		{"byte enum", map[string]interface{}{"State": "EQuestObjectiveState::Inactive", "Progress": int32(0), "Required": int32(1)},
+			QuestObjective{Key: "Kill", State: "Inactive", Required: 1}},
+		// Other names or types are not read but reported.
+		{"missing fields", map[string]interface{}{"Status": state("Completed"), "Progress": "1", "Count": int32(3)},
+			QuestObjective{Key: "Kill", Missing: []string{"State", "Progress", "Required"}}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			objective := decodeQuestObjective("QuestObjective_Kill", test.properties)
+			if !reflect.DeepEqual(objective, test.want) {
+				t.Errorf("decodeQuestObjective = %+v, want %+v", objective, test.want)
+			}
+		})
+	}
+}
+
+func TestDecodePOI(t *testing.T) {
+	poi := decodePOI(map[string]interface{}{
+		"Type":       remnant.EnumProperty{EnumType: "EPOIType", EnumValue: "EPOIType::Dungeon"},
+		"Discovered": true,
+		"Completed":  false,
+	})
+	if want := (&POIComponent{Kind: "Dungeon", Discovered: true}); !reflect.DeepEqual(poi, want) {
+		t.Errorf("decodePOI = %+v, want %+v", poi, want)
+	}
+
+	poi = decodePOI(map[string]interface{}{"bDiscovered": true, "Cleared": true})
+	if want := (&POIComponent{Missing: []string{"Type", "Discovered", "Completed"}}); !reflect.DeepEqual(poi, want) {
+		t.Errorf("decodePOI = %+v, want %+v", poi, want)
+	}
+}
+
+func TestGetEventStatus(t *testing.T) {
+	done := QuestObjective{Completed: true}
+	active := QuestObjective{State: objectiveActive}
+	tests := []struct {
+		name       string
+		objectives []QuestObjective
+		poi        *POIComponent
+		want       EventStatus
+	}{
+		{"nothing", nil, nil, EventNotStarted},
+		{"untouched objective", []QuestObjective{{Required: 1}}, nil, EventNotStarted},
+		{"active objective", []QuestObjective{done, active}, nil, EventInProgress},
+		{"all objectives", []QuestObjective{done, done}, nil, EventCompleted},
+		{"discovered", nil, &POIComponent{Discovered: true}, EventInProgress},
+		{"cleared", []QuestObjective{active}, &POIComponent{Completed: true}, EventCompleted},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if status := getEventStatus(test.objectives, test.poi); status != test.want {
+				t.Errorf("getEventStatus = %s, want %s", status, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,399 @@
+package remnant
+
+import (
+	"fmt"
+	"refinder/ue"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+type DifferenceKind int
+
+const (
+	Added DifferenceKind = iota
+	Removed
+	Changed
+)
+
+func (kind DifferenceKind) String() string {
+	switch kind {
+	case Added:
+		return "+"
+	case Removed:
+		return "-"
+	default:
+		return "~"
+	}
+}
+
+// Difference is a single added, removed or changed value between two
+// archives. Path names every step from the archive down to the value:
+// objects and actors in [], components in {}, properties after a dot and
+// array items and map entries by [index] or [key].
+type Difference struct {
+	Kind DifferenceKind
+	Path string
+	Old  interface{}
+	New  interface{}
+}
+
+func (difference Difference) String() string {
+	switch difference.Kind {
+	case Added:
+		return fmt.Sprintf("+ %s = %s", difference.Path, FormatValue(difference.New))
+	case Removed:
+		return fmt.Sprintf("- %s = %s", difference.Path, FormatValue(difference.Old))
+	default:
+		return fmt.Sprintf("~ %s: %s -> %s", difference.Path, FormatValue(difference.Old), FormatValue(difference.New))
+	}
+}
+
+// FormatValue returns a short, single line representation of a decoded value.
+func FormatValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "<none>"
+	case string:
+		return fmt.Sprintf("%q", v)
+	case []byte:
+		return fmt.Sprintf("<%d raw bytes>", len(v))
+	case EnumProperty:
+		return v.EnumValue
+	case ObjectProperty:
+		if v.ClassName == "" {
+			return "<no object>"
+		}
+		return v.ClassName
+	case TextProperty:
+		switch data := v.Data.(type) {
+		case TextData:
+			return fmt.Sprintf("%q", data.Data)
+		case TextPropertyData:
+			return fmt.Sprintf("%q", data.SourceString)
+		default:
+			return `""`
+		}
+	case StructProperty:
+		if v.PropertyList != nil {
+			return fmt.Sprintf("{%s, %d properties}", v.Name, len(v.PropertyList))
+		}
+		return FormatValue(v.Value)
+	case ArrayStructProperty:
+		return fmt.Sprintf("[%d %s]", len(v.Items), v.ElementType)
+	case ArrayProperty:
+		return fmt.Sprintf("[%d %s]", len(v.Items), v.ElementType)
+	case MapProperty:
+		return fmt.Sprintf("{%d %s: %s}", len(v.Values), v.KeyType, v.ValueType)
+	case Variables:
+		return fmt.Sprintf("{%s, %d variables}", v.Name, len(v.PropertyList))
+	case PersistenceBlob:
+		return fmt.Sprintf("<archive, %d objects>", len(v.Archive.Objects))
+	case PersistenceContainer:
+		return fmt.Sprintf("<container, %d actors>", len(v.Actors))
+	case Actor:
+		if v.DynamicData != nil {
+			return v.DynamicData.ClassPath.Name
+		}
+		return fmt.Sprintf("<actor, %d objects>", len(v.Archive.Objects))
+	case Property:
+		return FormatValue(v.Value)
+	case DynamicActor:
+		return v.ClassPath.Name
+	case ue.FTopLevelAssetPath:
+		return v.Path + "." + v.Name
+	case *ue.FTransform:
+		if v == nil {
+			return "<no transform>"
+		}
+		return fmt.Sprintf("{position %v, rotation %v, scale %v}", v.Position, v.Rotation, v.Scale)
+	case UObject:
+		return fmt.Sprintf("<object, %d properties>", len(v.PropertyList))
+	case Component:
+		return fmt.Sprintf("<component, %d properties>", len(v.PropertyList))
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+// DiffArchives compares two decoded archives down to single property values.
+// Offsets, sizes, checksums, the names tables and the indices of object
+// references are not compared, they change whenever anything else does.
+func DiffArchives(a, b *SaveArchive) []Difference {
+	var differences []Difference
+	diff := func(kind DifferenceKind, path string, oldValue, newValue interface{}) {
+		differences = append(differences, Difference{Kind: kind, Path: path, Old: oldValue, New: newValue})
+	}
+
+	if a.Header.SaveGameFileVersion != b.Header.SaveGameFileVersion {
+		diff(Changed, "Header.SaveGameFileVersion", a.Header.SaveGameFileVersion, b.Header.SaveGameFileVersion)
+	}
+	if a.Header.BuildNumber != b.Header.BuildNumber {
+		diff(Changed, "Header.BuildNumber", a.Header.BuildNumber, b.Header.BuildNumber)
+	}
+
+	diffSaveData(diff, "", a.Data, b.Data)
+
+	return differences
+}
+
+type diffFunc func(kind DifferenceKind, path string, oldValue, newValue interface{})
+
+// keyed gives every element a unique key, duplicated keys are numbered in
+// the order they appear.
+func keyed[T any](items []T, key func(T) string) ([]string, map[string]T) {
+	keys := make([]string, 0, len(items))
+	result := make(map[string]T, len(items))
+	seen := map[string]int{}
+	for _, item := range items {
+		itemKey := key(item)
+		seen[itemKey]++
+		if seen[itemKey] > 1 {
+			itemKey = fmt.Sprintf("%s#%d", itemKey, seen[itemKey])
+		}
+		keys = append(keys, itemKey)
+		result[itemKey] = item
+	}
+	return keys, result
+}
+
+// diffKeyed reports elements that only exist on one side and calls
+// diffItem for the ones that exist on both, in the order of b then a.
+func diffKeyed[T any](diff diffFunc, path string, a, b []T, key func(T) string, diffItem func(path string, a, b T)) {
+	keysA, itemsA := keyed(a, key)
+	keysB, itemsB := keyed(b, key)
+
+	for _, itemKey := range keysB {
+		itemB := itemsB[itemKey]
+		itemA, ok := itemsA[itemKey]
+		if !ok {
+			diff(Added, path+itemKey, nil, itemB)
+			continue
+		}
+		diffItem(path+itemKey, itemA, itemB)
+	}
+	for _, itemKey := range keysA {
+		if _, ok := itemsB[itemKey]; !ok {
+			diff(Removed, path+itemKey, itemsA[itemKey], nil)
+		}
+	}
+}
+
+func objectKey(object UObject) string {
+	if object.LoadedData != nil && object.LoadedData.Name != "" {
+		return fmt.Sprintf("[%s %s]", object.ObjectPath, object.LoadedData.Name)
+	}
+	return fmt.Sprintf("[%s]", object.ObjectPath)
+}
+
+func diffSaveData(diff diffFunc, path string, a, b SaveData) {
+	diffKeyed(diff, path, a.Objects, b.Objects, objectKey, func(path string, a, b UObject) {
+		diffProperties(diff, path, a.PropertyList, b.PropertyList)
+		diffRaw(diff, path+".ExtraData", a.ExtraData, b.ExtraData)
+
+		diffKeyed(diff, path, a.Components, b.Components, func(component Component) string {
+			return "{" + component.ComponentKey + "}"
+		}, func(path string, a, b Component) {
+			if IsVariablesComponent(a.ComponentKey) {
+				diffValue(diff, path, a.Properties[a.ComponentKey], b.Properties[b.ComponentKey])
+			} else {
+				diffProperties(diff, path, a.PropertyList, b.PropertyList)
+			}
+			diffRaw(diff, path+".ExtraData", a.ExtraData, b.ExtraData)
+		})
+	})
+}
+
+func diffRaw(diff diffFunc, path string, a, b []byte) {
+	if !slices.Equal(a, b) {
+		diff(Changed, path, a, b)
+	}
+}
+
+func propertyKey(property Property) string {
+	if property.Index != 0 {
+		return fmt.Sprintf(".%s[%d]", property.Name, property.Index)
+	}
+	return "." + property.Name
+}
+
+func diffProperties(diff diffFunc, path string, a, b []Property) {
+	diffKeyed(diff, path, a, b, propertyKey, func(path string, a, b Property) {
+		if a.Type != b.Type {
+			diff(Changed, path, a.Value, b.Value)
+			return
+		}
+		diffValue(diff, path, a.Value, b.Value)
+	})
+}
+
+func diffItems[T any](diff diffFunc, path string, a, b []T, diffItem func(path string, a, b T)) {
+	for i := 0; i < max(len(a), len(b)); i++ {
+		itemPath := fmt.Sprintf("%s[%d]", path, i)
+		switch {
+		case i >= len(a):
+			diff(Added, itemPath, nil, b[i])
+		case i >= len(b):
+			diff(Removed, itemPath, a[i], nil)
+		default:
+			diffItem(itemPath, a[i], b[i])
+		}
+	}
+}
+
+func diffValue(diff diffFunc, path string, a, b interface{}) {
+	if reflect.TypeOf(a) != reflect.TypeOf(b) {
+		diff(Changed, path, a, b)
+		return
+	}
+
+	switch valueA := a.(type) {
+	case StructProperty:
+		valueB := b.(StructProperty)
+		if valueA.Name != valueB.Name {
+			diff(Changed, path, a, b)
+			return
+		}
+		if valueA.PropertyList != nil || valueB.PropertyList != nil {
+			diffProperties(diff, path, valueA.PropertyList, valueB.PropertyList)
+			return
+		}
+		diffValue(diff, path, valueA.Value, valueB.Value)
+
+	case ArrayStructProperty:
+		valueB := b.(ArrayStructProperty)
+		diffItems(diff, path, valueA.Items, valueB.Items, func(path string, a, b StructProperty) {
+			diffValue(diff, path, a, b)
+		})
+
+	case ArrayProperty:
+		valueB := b.(ArrayProperty)
+		diffItems(diff, path, valueA.Items, valueB.Items, func(path string, a, b interface{}) {
+			diffValue(diff, path, a, b)
+		})
+
+	case MapProperty:
+		valueB := b.(MapProperty)
+		mapKey := func(value MapPropertyValue) string {
+			return "[" + FormatValue(value.Key) + "]"
+		}
+		diffKeyed(diff, path, valueA.Values, valueB.Values, mapKey, func(path string, a, b MapPropertyValue) {
+			diffValue(diff, path, a.Value, b.Value)
+		})
+
+	case ObjectProperty:
+		valueB := b.(ObjectProperty)
+		if valueA.ClassName != valueB.ClassName || valueA.ClassName == "" && valueA.Index != valueB.Index {
+			diff(Changed, path, a, b)
+		}
+
+	case Variables:
+		valueB := b.(Variables)
+		diffProperties(diff, path, valueA.PropertyList, valueB.PropertyList)
+
+	case PersistenceBlob:
+		diffSaveData(diff, path, valueA.Archive, b.(PersistenceBlob).Archive)
+
+	case PersistenceContainer:
+		diffContainers(diff, path, valueA, b.(PersistenceContainer))
+
+	default:
+		if !reflect.DeepEqual(a, b) {
+			diff(Changed, path, a, b)
+		}
+	}
+}
+
+func actorClassName(actor Actor) string {
+	if actor.DynamicData == nil {
+		return ""
+	}
+	return actor.DynamicData.ClassPath.Name
+}
+
+// actorKey labels an actor with its class when it has one. An actor whose
+// class differs between the archives is labelled by its ID only.
+func actorKey(uniqueID uint64, a, b Actor) string {
+	if className := actorClassName(b); className != "" && className == actorClassName(a) {
+		return fmt.Sprintf(".Actors[%d %s]", uniqueID, className)
+	}
+	return fmt.Sprintf(".Actors[%d]", uniqueID)
+}
+
+func diffDynamicActors(diff diffFunc, path string, a, b *DynamicActor) {
+	switch {
+	case a == nil && b == nil:
+	case a == nil:
+		diff(Added, path, nil, *b)
+	case b == nil:
+		diff(Removed, path, *a, nil)
+	default:
+		if a.ClassPath != b.ClassPath {
+			diff(Changed, path+".ClassPath", a.ClassPath, b.ClassPath)
+		}
+		if !reflect.DeepEqual(a.Transform, b.Transform) {
+			diff(Changed, path+".Transform", a.Transform, b.Transform)
+		}
+	}
+}
+
+func diffContainers(diff diffFunc, path string, a, b PersistenceContainer) {
+	uniqueIDs := []uint64{}
+	for uniqueID := range b.Actors {
+		uniqueIDs = append(uniqueIDs, uniqueID)
+	}
+	for uniqueID := range a.Actors {
+		if _, ok := b.Actors[uniqueID]; !ok {
+			uniqueIDs = append(uniqueIDs, uniqueID)
+		}
+	}
+	slices.Sort(uniqueIDs)
+
+	for _, uniqueID := range uniqueIDs {
+		actorA, okA := a.Actors[uniqueID]
+		actorB, okB := b.Actors[uniqueID]
+		switch {
+		case !okA:
+			diff(Added, path+actorKey(uniqueID, actorB, actorB), nil, actorB)
+		case !okB:
+			diff(Removed, path+actorKey(uniqueID, actorA, actorA), actorA, nil)
+		default:
+			actorPath := path + actorKey(uniqueID, actorA, actorB)
+			if !reflect.DeepEqual(actorA.Transform, actorB.Transform) {
+				diff(Changed, actorPath+".Transform", actorA.Transform, actorB.Transform)
+			}
+			diffDynamicActors(diff, actorPath+".DynamicData", actorA.DynamicData, actorB.DynamicData)
+			diffSaveData(diff, actorPath, actorA.Archive, actorB.Archive)
+		}
+	}
+
+	for _, destroyed := range b.Destroyed {
+		if !slices.Contains(a.Destroyed, destroyed) {
+			diff(Added, fmt.Sprintf("%s.Destroyed[%d]", path, destroyed), nil, destroyed)
+		}
+	}
+	for _, destroyed := range a.Destroyed {
+		if !slices.Contains(b.Destroyed, destroyed) {
+			diff(Removed, fmt.Sprintf("%s.Destroyed[%d]", path, destroyed), destroyed, nil)
+		}
+	}
+}
+
+// FilterDifferences keeps the differences whose path contains any of the
+// given substrings.
+func FilterDifferences(differences []Difference, substrings []string) []Difference {
+	if len(substrings) == 0 {
+		return differences
+	}
+
+	result := []Difference{}
+	for _, difference := range differences {
+		for _, substring := range substrings {
+			if strings.Contains(difference.Path, substring) {
+				result = append(result, difference)
+				break
+			}
+		}
+	}
+	return result
+}
@@ -0,0 +1,130 @@
+package remnant
+
+import (
+	"refinder/ue"
+	"slices"
+	"testing"
+)
+
+func diffArchive(properties []Property, actors map[uint64]Actor, destroyed []uint64) *SaveArchive {
+	return &SaveArchive{
+		Header: SaveHeader{SaveGameFileVersion: 9},
+		Data: SaveData{
+			NamesTable: []string{"None"},
+			Objects: []UObject{
+				{ObjectPath: REMNANT_SAVE_GAME, PropertyList: properties},
+				{ObjectPath: "/Game/Level", PropertyList: []Property{{
+					Name: "Blob",
+					Type: "StructProperty",
+					Value: StructProperty{Name: "PersistenceBlob", Value: PersistenceContainer{
+						Actors:    actors,
+						Destroyed: destroyed,
+					}},
+				}}},
+			},
+		},
+	}
+}
+
+func dynamicActor(className string, x float64) Actor {
+	return Actor{
+		Archive: SaveData{NamesTable: []string{"None"}},
+		DynamicData: &DynamicActor{
+			UniqueID:  1,
+			Transform: &ue.FTransform{Position: ue.FVector{X: x}},
+			ClassPath: ue.FTopLevelAssetPath{Path: "/Game/" + className, Name: className + "_C"},
+		},
+	}
+}
+
+func TestDiffArchives(t *testing.T) {
+	level := func(value int32) []Property {
+		return []Property{{Name: "Level", Type: "IntProperty", Value: value}}
+	}
+	item := func(index int32) []Property {
+		return []Property{{Name: "Item", Type: "ObjectProperty", Value: ObjectProperty{ClassName: "/Game/Item.Item_C", Index: index}}}
+	}
+	actors := func(actors ...Actor) map[uint64]Actor {
+		result := map[uint64]Actor{}
+		for i, actor := range actors {
+			result[uint64(i+1)] = actor
+		}
+		return result
+	}
+	blob := "[/Game/Level].Blob"
+
+	tests := []struct {
+		name        string
+		a, b        *SaveArchive
+		differences []string
+	}{
+		{"same", diffArchive(level(1), nil, nil), diffArchive(level(1), nil, nil), nil},
+		{"changed property", diffArchive(level(1), nil, nil), diffArchive(level(2), nil, nil), []string{
+			"~ [" + REMNANT_SAVE_GAME + "].Level: 1 -> 2",
+		}},
+		{"added property", diffArchive(nil, nil, nil), diffArchive(level(2), nil, nil), []string{
+			"+ [" + REMNANT_SAVE_GAME + "].Level = 2",
+		}},
+		{"removed property", diffArchive(level(1), nil, nil), diffArchive(nil, nil, nil), []string{
+			"- [" + REMNANT_SAVE_GAME + "].Level = 1",
+		}},
+		{"object reference index", diffArchive(item(3), nil, nil), diffArchive(item(5), nil, nil), nil},
+		{"added actor", diffArchive(nil, nil, nil), diffArchive(nil, actors(dynamicActor("Chest", 0)), nil), []string{
+			"+ " + blob + ".Actors[1 Chest_C] = Chest_C",
+		}},
+		{"removed actor", diffArchive(nil, actors(dynamicActor("Chest", 0)), nil), diffArchive(nil, nil, nil), []string{
+			"- " + blob + ".Actors[1 Chest_C] = Chest_C",
+		}},
+		{"changed class", diffArchive(nil, actors(dynamicActor("Chest", 0)), nil), diffArchive(nil, actors(dynamicActor("Door", 0)), nil), []string{
+			"~ " + blob + ".Actors[1].DynamicData.ClassPath: /Game/Chest.Chest_C -> /Game/Door.Door_C",
+		}},
+		{"moved dynamic actor", diffArchive(nil, actors(dynamicActor("Chest", 0)), nil), diffArchive(nil, actors(dynamicActor("Chest", 2)), nil), []string{
+			"~ " + blob + ".Actors[1 Chest_C].DynamicData.Transform: " +
+				"{position {0 0 0}, rotation {0 0 0 0}, scale {0 0 0}} -> {position {2 0 0}, rotation {0 0 0 0}, scale {0 0 0}}",
+		}},
+		{"added dynamic data", diffArchive(nil, actors(Actor{Archive: SaveData{NamesTable: []string{"None"}}}), nil), diffArchive(nil, actors(dynamicActor("Chest", 0)), nil), []string{
+			"+ " + blob + ".Actors[1].DynamicData = Chest_C",
+		}},
+		{"destroyed actor", diffArchive(nil, nil, []uint64{4}), diffArchive(nil, nil, []uint64{5}), []string{
+			"+ " + blob + ".Destroyed[5] = 5",
+			"- " + blob + ".Destroyed[4] = 4",
+		}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var differences []string
+			for _, difference := range DiffArchives(test.a, test.b) {
+				differences = append(differences, difference.String())
+			}
+			if !slices.Equal(differences, test.differences) {
+				t.Errorf("differences =\n%q\nwant\n%q", differences, test.differences)
+			}
+		})
+	}
+}
+
+func TestFilterDifferences(t *testing.T) {
+	differences := []Difference{{Path: "[a].Level"}, {Path: "[b].Name"}, {Path: "[c].Level"}}
+
+	tests := []struct {
+		name       string
+		substrings []string
+		paths      []string
+	}{
+		{"no filter", nil, []string{"[a].Level", "[b].Name", "[c].Level"}},
+		{"one", []string{"Level"}, []string{"[a].Level", "[c].Level"}},
+		{"several", []string{"[b]", "[c]"}, []string{"[b].Name", "[c].Level"}},
+		{"none", []string{"Missing"}, nil},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var paths []string
+			for _, difference := range FilterDifferences(differences, test.substrings) {
+				paths = append(paths, difference.Path)
+			}
+			if !slices.Equal(paths, test.paths) {
+				t.Errorf("paths = %q, want %q", paths, test.paths)
+			}
+		})
+	}
+}
@@ -0,0 +1,461 @@
+package remnant
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"refinder/ue"
+)
+
+// The JSON form of a save keeps every decoded value next to its Unreal type:
+// properties carry Type, array items their ElementType, map entries their
+// KeyType/ValueType and struct values their struct Name. Property maps are
+// written as ordered property lists so duplicated names (static arrays) and
+// the on-disk order survive. The same type information is used to decode the
+// JSON back into the values the reader produces, see decodePropertyValue.
+
+func (property Property) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Name    string
+		Type    string
+		Index   uint32
+		Size    uint32
+		Value   interface{}
+		TagFlag uint8 `json:",omitempty"`
+	}{
+		Name:    property.Name,
+		Type:    property.Type,
+		Index:   property.Index,
+		Size:    property.Size,
+		Value:   property.Value,
+		TagFlag: property.TagFlag,
+	})
+}
+
+func (structProperty StructProperty) MarshalJSON() ([]byte, error) {
+	value := structProperty.Value
+	if structProperty.PropertyList != nil {
+		value = structProperty.PropertyList
+	}
+
+	return json.Marshal(struct {
+		Name  string
+		GUID  ue.FGuid
+		Size  uint32
+		Value interface{}
+	}{
+		Name:  structProperty.Name,
+		GUID:  structProperty.GUID,
+		Size:  structProperty.Size,
+		Value: value,
+	})
+}
+
+func (variables Variables) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Name       string
+		Properties []Property
+		Reserved   uint64 `json:",omitempty"`
+	}{
+		Name:       variables.Name,
+		Properties: variables.PropertyList,
+		Reserved:   variables.Reserved,
+	})
+}
+
+func (component Component) MarshalJSON() ([]byte, error) {
+	if IsVariablesComponent(component.ComponentKey) {
+		return json.Marshal(struct {
+			ComponentKey string
+			Variables    interface{}
+			ExtraData    []byte
+		}{
+			ComponentKey: component.ComponentKey,
+			Variables:    component.Properties[component.ComponentKey],
+			ExtraData:    component.ExtraData,
+		})
+	}
+
+	return json.Marshal(struct {
+		ComponentKey string
+		Properties   []Property
+		ExtraData    []byte
+	}{
+		ComponentKey: component.ComponentKey,
+		Properties:   component.PropertyList,
+		ExtraData:    component.ExtraData,
+	})
+}
+
+func (object UObject) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		ObjectID   uint32
+		WasLoaded  bool
+		ObjectPath string
+		LoadedData *UObjectLoadedData
+		Properties []Property
+		ExtraData  []byte
+		Components []Component
+	}{
+		ObjectID:   object.ObjectID,
+		WasLoaded:  object.WasLoaded,
+		ObjectPath: object.ObjectPath,
+		LoadedData: object.LoadedData,
+		Properties: object.PropertyList,
+		ExtraData:  object.ExtraData,
+		Components: object.Components,
+	})
+}
+
+func (property *Property) UnmarshalJSON(data []byte) error {
+	var jsonProperty struct {
+		Name    string
+		Type    string
+		Index   uint32
+		Size    uint32
+		Value   json.RawMessage
+		TagFlag uint8
+	}
+	err := json.Unmarshal(data, &jsonProperty)
+	if err != nil {
+		return err
+	}
+
+	value, err := decodePropertyValue(jsonProperty.Type, jsonProperty.Value, false)
+	if err != nil {
+		return fmt.Errorf("property %s: %w", jsonProperty.Name, err)
+	}
+
+	*property = Property{
+		Name:    jsonProperty.Name,
+		Type:    jsonProperty.Type,
+		Index:   jsonProperty.Index,
+		Size:    jsonProperty.Size,
+		Value:   value,
+		TagFlag: jsonProperty.TagFlag,
+	}
+	return nil
+}
+
+func (structProperty *StructProperty) UnmarshalJSON(data []byte) error {
+	var jsonStruct struct {
+		Name  string
+		GUID  ue.FGuid
+		Size  uint32
+		Value json.RawMessage
+	}
+	err := json.Unmarshal(data, &jsonStruct)
+	if err != nil {
+		return err
+	}
+
+	*structProperty = StructProperty{
+		Name: jsonStruct.Name,
+		GUID: jsonStruct.GUID,
+		Size: jsonStruct.Size,
+	}
+
+	switch jsonStruct.Name {
+	case "SoftClassPath", "SoftObjectPath":
+		structProperty.Value, err = decodeJSON[string](jsonStruct.Value)
+	case "Timespan", "DateTime":
+		structProperty.Value, err = decodeJSON[int64](jsonStruct.Value)
+	case "Guid":
+		structProperty.Value, err = decodeJSON[ue.FGuid](jsonStruct.Value)
+	case "Vector":
+		structProperty.Value, err = decodeJSON[ue.FVector](jsonStruct.Value)
+	case "PersistenceBlob":
+		if hasJSONField(jsonStruct.Value, "Actors") {
+			structProperty.Value, err = decodeJSON[PersistenceContainer](jsonStruct.Value)
+		} else {
+			structProperty.Value, err = decodeJSON[PersistenceBlob](jsonStruct.Value)
+		}
+	default:
+		structProperty.PropertyList, err = decodeJSON[[]Property](jsonStruct.Value)
+		structProperty.Value = propertiesMap(structProperty.PropertyList)
+	}
+	if err != nil {
+		return fmt.Errorf("struct %s: %w", jsonStruct.Name, err)
+	}
+
+	return nil
+}
+
+func (variables *Variables) UnmarshalJSON(data []byte) error {
+	var jsonVariables struct {
+		Name       string
+		Properties []Property
+		Reserved   uint64
+	}
+	err := json.Unmarshal(data, &jsonVariables)
+	if err != nil {
+		return err
+	}
+
+	*variables = Variables{
+		Name:         jsonVariables.Name,
+		Properties:   propertiesMap(jsonVariables.Properties),
+		PropertyList: jsonVariables.Properties,
+		Reserved:     jsonVariables.Reserved,
+	}
+	return nil
+}
+
+func (component *Component) UnmarshalJSON(data []byte) error {
+	var jsonComponent struct {
+		ComponentKey string
+		Variables    *Variables
+		Properties   []Property
+		ExtraData    []byte
+	}
+	err := json.Unmarshal(data, &jsonComponent)
+	if err != nil {
+		return err
+	}
+
+	*component = Component{
+		ComponentKey: jsonComponent.ComponentKey,
+		ExtraData:    jsonComponent.ExtraData,
+	}
+	if IsVariablesComponent(jsonComponent.ComponentKey) {
+		if jsonComponent.Variables == nil {
+			return fmt.Errorf("component %s: missing Variables", jsonComponent.ComponentKey)
+		}
+		component.Properties = map[string]interface{}{
+			jsonComponent.ComponentKey: *jsonComponent.Variables,
+		}
+		return nil
+	}
+
+	component.PropertyList = jsonComponent.Properties
+	component.Properties = propertiesMap(jsonComponent.Properties)
+	return nil
+}
+
+func (object *UObject) UnmarshalJSON(data []byte) error {
+	var jsonObject struct {
+		ObjectID   uint32
+		WasLoaded  bool
+		ObjectPath string
+		LoadedData *UObjectLoadedData
+		Properties []Property
+		ExtraData  []byte
+		Components []Component
+	}
+	err := json.Unmarshal(data, &jsonObject)
+	if err != nil {
+		return fmt.Errorf("object %s: %w", jsonObject.ObjectPath, err)
+	}
+
+	*object = UObject{
+		ObjectID:     jsonObject.ObjectID,
+		WasLoaded:    jsonObject.WasLoaded,
+		ObjectPath:   jsonObject.ObjectPath,
+		LoadedData:   jsonObject.LoadedData,
+		Properties:   propertiesMap(jsonObject.Properties),
+		PropertyList: jsonObject.Properties,
+		ExtraData:    jsonObject.ExtraData,
+		Components:   jsonObject.Components,
+	}
+	return nil
+}
+
+func (textProperty *TextProperty) UnmarshalJSON(data []byte) error {
+	var jsonText struct {
+		Flags       uint32
+		HistoryType uint8
+		Data        json.RawMessage
+	}
+	err := json.Unmarshal(data, &jsonText)
+	if err != nil {
+		return err
+	}
+
+	*textProperty = TextProperty{
+		Flags:       jsonText.Flags,
+		HistoryType: jsonText.HistoryType,
+	}
+	if isJSONNull(jsonText.Data) {
+		return nil
+	}
+
+	if jsonText.HistoryType == 255 {
+		textProperty.Data, err = decodeJSON[TextData](jsonText.Data)
+	} else {
+		textProperty.Data, err = decodeJSON[TextPropertyData](jsonText.Data)
+	}
+	return err
+}
+
+func decodeJSON[T any](data json.RawMessage) (T, error) {
+	var value T
+	err := json.Unmarshal(data, &value)
+	return value, err
+}
+
+func isJSONNull(data json.RawMessage) bool {
+	data = bytes.TrimSpace(data)
+	return len(data) == 0 || bytes.Equal(data, []byte("null"))
+}
+
+func hasJSONField(data json.RawMessage, name string) bool {
+	var fields map[string]json.RawMessage
+	if json.Unmarshal(data, &fields) != nil {
+		return false
+	}
+	_, ok := fields[name]
+	return ok
+}
+
+// decodePropertyValue is the JSON counterpart of getPropertyValue: it
+// returns the same Go types the reader produces for varType.
+func decodePropertyValue(varType string, data json.RawMessage, raw bool) (interface{}, error) {
+	if isJSONNull(data) {
+		return nil, nil
+	}
+
+	data = bytes.TrimSpace(data)
+	isString := data[0] == '"'
+
+	switch varType {
+	case "IntProperty", "Int16Property", "Int64Property", "UInt64Property", "FloatProperty",
+		"DoubleProperty", "UInt16Property", "UInt32Property", "BoolProperty",
+		"ArrayProperty", "StructProperty", "MapProperty", "EnumProperty", "TextProperty", "ObjectProperty":
+		// Properties the reader skips are kept as raw bytes (base64 in JSON).
+		if isString && !raw {
+			return decodeJSON[[]byte](data)
+		}
+	}
+
+	switch varType {
+	case "IntProperty":
+		return decodeJSON[int32](data)
+
+	case "Int16Property":
+		return decodeJSON[int16](data)
+
+	case "Int64Property":
+		return decodeJSON[int64](data)
+
+	case "UInt64Property":
+		return decodeJSON[uint64](data)
+
+	case "FloatProperty":
+		return decodeJSON[float32](data)
+
+	case "DoubleProperty":
+		return decodeJSON[float64](data)
+
+	case "UInt16Property":
+		return decodeJSON[uint16](data)
+
+	case "UInt32Property":
+		return decodeJSON[uint32](data)
+
+	case "SoftClassPath", "SoftObjectProperty", "StrProperty", "NameProperty":
+		return decodeJSON[string](data)
+
+	case "BoolProperty":
+		return decodeJSON[bool](data)
+
+	case "MapProperty":
+		if raw {
+			return nil, fmt.Errorf("raw map property is not supported")
+		}
+		return decodeMapProperty(data)
+
+	case "EnumProperty":
+		return decodeJSON[EnumProperty](data)
+
+	case "TextProperty":
+		return decodeJSON[TextProperty](data)
+
+	case "ArrayProperty":
+		if hasJSONField(data, "GUID") {
+			return decodeJSON[ArrayStructProperty](data)
+		}
+		return decodeArrayProperty(data)
+
+	case "StructProperty":
+		if raw {
+			return decodeJSON[StructReference](data)
+		}
+		return decodeJSON[StructProperty](data)
+
+	case "ObjectProperty":
+		return decodeJSON[ObjectProperty](data)
+
+	case "ByteProperty":
+		if raw || data[0] != '{' {
+			return decodeJSON[uint8](data)
+		}
+		return decodeJSON[EnumProperty](data)
+
+	case "None":
+		return nil, nil
+
+	default:
+		return nil, fmt.Errorf("property type is not supported yet: %s", varType)
+	}
+}
+
+func decodeArrayProperty(data json.RawMessage) (ArrayProperty, error) {
+	var jsonArray struct {
+		Count       uint32
+		Items       []json.RawMessage
+		ElementType string
+	}
+	err := json.Unmarshal(data, &jsonArray)
+	if err != nil {
+		return ArrayProperty{}, err
+	}
+
+	result := ArrayProperty{
+		ElementType: jsonArray.ElementType,
+		Count:       uint32(len(jsonArray.Items)),
+		Items:       make([]interface{}, len(jsonArray.Items)),
+	}
+	for i, item := range jsonArray.Items {
+		result.Items[i], err = decodePropertyValue(jsonArray.ElementType, item, true)
+		if err != nil {
+			return ArrayProperty{}, fmt.Errorf("item %d: %w", i, err)
+		}
+	}
+
+	return result, nil
+}
+
+func decodeMapProperty(data json.RawMessage) (MapProperty, error) {
+	var jsonMap struct {
+		KeyType      string
+		ValueType    string
+		KeysToRemove uint32
+		Values       []struct {
+			Key   json.RawMessage
+			Value json.RawMessage
+		}
+	}
+	err := json.Unmarshal(data, &jsonMap)
+	if err != nil {
+		return MapProperty{}, err
+	}
+
+	result := MapProperty{
+		KeyType:      jsonMap.KeyType,
+		ValueType:    jsonMap.ValueType,
+		KeysToRemove: jsonMap.KeysToRemove,
+		Values:       make([]MapPropertyValue, len(jsonMap.Values)),
+	}
+	for i, value := range jsonMap.Values {
+		result.Values[i].Key, err = decodePropertyValue(jsonMap.KeyType, value.Key, true)
+		if err != nil {
+			return MapProperty{}, fmt.Errorf("key %d: %w", i, err)
+		}
+		result.Values[i].Value, err = decodePropertyValue(jsonMap.ValueType, value.Value, true)
+		if err != nil {
+			return MapProperty{}, fmt.Errorf("value %d: %w", i, err)
+		}
+	}
+
+	return result, nil
+}
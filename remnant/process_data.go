@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"math"
 	"refinder/config"
 	"refinder/memory"
 	"refinder/ue"
@@ -17,11 +18,15 @@ type OffsetInfo struct {
 }
 
 type UObject struct {
-	ObjectID   uint32
-	WasLoaded  bool
-	ObjectPath string
-	LoadedData *UObjectLoadedData
-	Properties map[string]interface{}
+	ObjectID     uint32
+	WasLoaded    bool
+	ObjectPath   string
+	LoadedData   *UObjectLoadedData
+	Properties   map[string]interface{}
+	PropertyList []Property
+	// ExtraData holds bytes after the properties that the reader does not
+	// understand.
+	ExtraData  []byte
 	Components []Component
 }
 
@@ -33,6 +38,8 @@ type UObjectLoadedData struct {
 type Component struct {
 	ComponentKey string
 	Properties   map[string]interface{}
+	PropertyList []Property
+	ExtraData    []byte
 }
 
 type ArrayStructProperty struct {
@@ -41,6 +48,13 @@ type ArrayStructProperty struct {
 	Items       []StructProperty
 	ElementType string
 	GUID        ue.FGuid
+	// TagName, TagType, TagIndex and TagFlag are the rest of the property tag
+	// in front of the elements, see readArrayStructHeader. Empty names are
+	// written as the name of the array and StructProperty.
+	TagName  string
+	TagType  string
+	TagIndex uint32
+	TagFlag  uint8
 }
 
 type StructReference struct {
@@ -60,6 +74,8 @@ type SaveData struct {
 	ObjectsOffset     uint64
 	Objects           []UObject
 	Version           uint32
+	// nameNumbers keeps the numbers of names, see readName.
+	nameNumbers bool
 }
 
 type SaveHeader struct {
@@ -75,8 +91,11 @@ type SaveArchive struct {
 }
 
 type Variables struct {
-	Name       string
-	Properties map[string]interface{}
+	Name         string
+	Properties   map[string]interface{}
+	PropertyList []Property
+	// Reserved are the 8 bytes after the name, 0 in saves.
+	Reserved uint64
 }
 
 const (
@@ -91,7 +110,7 @@ var VarTypeNames = map[uint8]string{
 	VarTypeNone:  "None",
 	VarTypeBool:  "BoolProperty",
 	VarTypeInt:   "IntProperty",
-	VarTypeFloat: "FloatProeprty",
+	VarTypeFloat: "FloatProperty",
 	VarTypeName:  "NameProperty",
 }
 
@@ -117,8 +136,8 @@ func readPackageVersion(r io.Reader) (PackageVersion, error) {
 	return packageVersion, nil
 }
 
-func readSaveData(r io.ReadSeeker, hasPackageVersion bool, hasTopLevelAssetPath bool) (SaveData, error) {
-	result := SaveData{}
+func readSaveData(r io.ReadSeeker, hasPackageVersion bool, hasTopLevelAssetPath bool, nameNumbers bool) (SaveData, error) {
+	result := SaveData{nameNumbers: nameNumbers}
 	var err error
 
 	if hasPackageVersion {
@@ -163,13 +182,26 @@ func readSaveData(r io.ReadSeeker, hasPackageVersion bool, hasTopLevelAssetPath
 	return result, nil
 }
 
+// ReadSaveArchive reads a save for looking at it. Names are read without
+// their number, Unreal's Name_2 is read as Name.
 func ReadSaveArchive(r io.ReadSeeker) (SaveArchive, error) {
+	return readSaveArchive(r, false)
+}
+
+// ReadExactSaveArchive reads a save with the numbers of names, Name_2 stays
+// Name_2, so WriteSaveArchive writes it back unchanged. The JSON dump and
+// import use it.
+func ReadExactSaveArchive(r io.ReadSeeker) (SaveArchive, error) {
+	return readSaveArchive(r, true)
+}
+
+func readSaveArchive(r io.ReadSeeker, nameNumbers bool) (SaveArchive, error) {
 	header, err := readSaveHeader(r)
 	if err != nil {
 		return SaveArchive{}, err
 	}
 
-	data, err := readSaveData(r, true, true)
+	data, err := readSaveData(r, true, true, nameNumbers)
 	if err != nil {
 		return SaveArchive{}, err
 	}
@@ -299,7 +331,7 @@ func readVariable(r io.Reader, saveData *SaveData) (*Property, error) {
 			return nil, fmt.Errorf("failed to read variable value: %w", err)
 		}
 
-		varValue = float32(value)
+		varValue = math.Float32frombits(value)
 
 	case VarTypeName:
 		value, err := readName(r, saveData)
@@ -327,7 +359,7 @@ func readVariables(r io.Reader, saveData *SaveData) (Variables, error) {
 		return Variables{}, fmt.Errorf("failed to read variable name index: %w", err)
 	}
 
-	_, err = memory.ReadInt[uint64](r)
+	reserved, err := memory.ReadInt[uint64](r)
 	if err != nil {
 		return Variables{}, fmt.Errorf("failed to read empty value: %w", err)
 	}
@@ -337,22 +369,35 @@ func readVariables(r io.Reader, saveData *SaveData) (Variables, error) {
 		return Variables{}, fmt.Errorf("failed to read array length: %w", err)
 	}
 
-	properties := map[string]interface{}{}
+	properties := []Property{}
 
 	for i := 0; i < int(arrayLength); i++ {
 		property, err := readVariable(r, saveData)
 		if err != nil {
 			return Variables{}, fmt.Errorf("failed to read property: %w", err)
 		}
-		properties[property.Name] = property.Value
+		properties = append(properties, *property)
 	}
 
 	return Variables{
-		Name:       name,
-		Properties: properties,
+		Name:         name,
+		Properties:   propertiesMap(properties),
+		PropertyList: properties,
+		Reserved:     reserved,
 	}, nil
 }
 
+// IsVariablesComponent reports whether a component stores a Variables table
+// instead of tagged properties.
+func IsVariablesComponent(componentKey string) bool {
+	switch componentKey {
+	case "GlobalVariables", "Variables", "Variable", "PersistenceKeys", "PersistanceKeys1", "PersistenceKeys1":
+		return true
+	default:
+		return false
+	}
+}
+
 func readComponents(r io.ReadSeeker, saveData *SaveData) ([]Component, error) {
 	componentCount, err := memory.ReadInt[uint32](r)
 	if err != nil {
@@ -378,45 +423,15 @@ func readComponents(r io.ReadSeeker, saveData *SaveData) ([]Component, error) {
 		}
 
 		properties := map[string]interface{}{}
-		switch componentKey {
-		case "GlobalVariables":
-			variables, err := readVariables(r, saveData)
-			if err != nil {
-				return nil, err
-			}
-			properties[componentKey] = variables
-		case "Variables":
-			variables, err := readVariables(r, saveData)
-			if err != nil {
-				return nil, err
-			}
-			properties[componentKey] = variables
-		case "Variable":
-			variables, err := readVariables(r, saveData)
-			if err != nil {
-				return nil, err
-			}
-			properties[componentKey] = variables
-		case "PersistenceKeys":
-			variables, err := readVariables(r, saveData)
-			if err != nil {
-				return nil, err
-			}
-			properties[componentKey] = variables
-		case "PersistanceKeys1":
+		var propertyList []Property
+		if IsVariablesComponent(componentKey) {
 			variables, err := readVariables(r, saveData)
 			if err != nil {
 				return nil, err
 			}
 			properties[componentKey] = variables
-		case "PersistenceKeys1":
-			variables, err := readVariables(r, saveData)
-			if err != nil {
-				return nil, err
-			}
-			properties[componentKey] = variables
-		default:
-			properties, err = readProperties(r, saveData)
+		} else {
+			properties, propertyList, err = readProperties(r, saveData)
 			if err != nil {
 				return nil, err
 			}
@@ -427,6 +442,7 @@ func readComponents(r io.ReadSeeker, saveData *SaveData) ([]Component, error) {
 			return nil, err
 		}
 
+		var extraData []byte
 		if currentPos-startPos != int64(objectLength) {
 			bytes := make([]byte, startPos+int64(objectLength)-currentPos)
 			_, err := r.Read(bytes)
@@ -439,11 +455,14 @@ func readComponents(r io.ReadSeeker, saveData *SaveData) ([]Component, error) {
 					currentPos-startPos, objectLength, startPos, componentKey, bytes,
 				)
 			}
+			extraData = bytes
 		}
 
 		components[i] = Component{
 			ComponentKey: componentKey,
 			Properties:   properties,
+			PropertyList: propertyList,
+			ExtraData:    extraData,
 		}
 	}
 
@@ -515,7 +534,7 @@ func readObjectData(r io.ReadSeeker, object *UObject, saveData *SaveData) error
 	}
 
 	if length > 0 {
-		properties, err := readProperties(r, saveData)
+		properties, propertyList, err := readProperties(r, saveData)
 		if err != nil {
 			return err
 		}
@@ -537,9 +556,11 @@ func readObjectData(r io.ReadSeeker, object *UObject, saveData *SaveData) error
 					currentPos-startPos, length, startPos, object.ObjectPath, bytes,
 				)
 			}
+			object.ExtraData = bytes
 		}
 
 		object.Properties = properties
+		object.PropertyList = propertyList
 	}
 
 	return nil
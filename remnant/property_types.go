@@ -5,9 +5,9 @@ import (
 	"encoding/binary"
 	"fmt"
 	"io"
-	"log"
 	"refinder/memory"
 	"refinder/ue"
+	"strconv"
 )
 
 const (
@@ -21,18 +21,37 @@ type Property struct {
 	Type  string
 	Size  uint32
 	Value interface{}
+	// TagFlag is the byte that ends the type information of a property,
+	// Unreal's HasPropertyGuid. Saves have 0, it is kept as read so the
+	// property is written back unchanged.
+	TagFlag uint8
+}
+
+// readTagFlag reads the byte that ends the type information of a property
+// into tag. Raw values, like array items and map entries, have no type
+// information and pass a nil tag.
+func readTagFlag(r io.Reader, tag *uint8) error {
+	if tag == nil {
+		return nil
+	}
+	value, err := memory.ReadInt[uint8](r)
+	if err != nil {
+		return err
+	}
+	*tag = value
+	return nil
 }
 
 type ObjectProperty struct {
 	ClassName string
+	// Index points into the objects table of the archive, -1 for no object.
+	Index int32
 }
 
-func readObjectProperty(r io.ReadSeeker, saveData *SaveData, raw bool) (ObjectProperty, error) {
-	if !raw {
-		_, err := r.Seek(1, io.SeekCurrent)
-		if err != nil {
-			return ObjectProperty{}, err
-		}
+func readObjectProperty(r io.ReadSeeker, saveData *SaveData, tag *uint8) (ObjectProperty, error) {
+	err := readTagFlag(r, tag)
+	if err != nil {
+		return ObjectProperty{}, err
 	}
 
 	objectIndex, err := memory.ReadInt[int32](r)
@@ -41,16 +60,17 @@ func readObjectProperty(r io.ReadSeeker, saveData *SaveData, raw bool) (ObjectPr
 	}
 
 	if objectIndex == -1 {
-		return ObjectProperty{}, nil
+		return ObjectProperty{Index: -1}, nil
 	}
 
 	return ObjectProperty{
 		ClassName: saveData.Objects[objectIndex].ObjectPath,
+		Index:     objectIndex,
 	}, nil
 }
 
-func readByteProperty(r io.ReadSeeker, saveData *SaveData, raw bool) (interface{}, error) {
-	if raw {
+func readByteProperty(r io.ReadSeeker, saveData *SaveData, tag *uint8) (interface{}, error) {
+	if tag == nil {
 		value, err := memory.ReadInt[uint8](r)
 		if err != nil {
 			return 0, err
@@ -62,7 +82,7 @@ func readByteProperty(r io.ReadSeeker, saveData *SaveData, raw bool) (interface{
 	if err != nil {
 		return 0, err
 	}
-	_, err = r.Seek(1, io.SeekCurrent)
+	err = readTagFlag(r, tag)
 	if err != nil {
 		return 0, err
 	}
@@ -80,7 +100,10 @@ func readByteProperty(r io.ReadSeeker, saveData *SaveData, raw bool) (interface{
 	if err != nil {
 		return 0, err
 	}
-	return enumName, nil
+	return EnumProperty{
+		EnumType:  name,
+		EnumValue: enumName,
+	}, nil
 }
 
 type ArrayProperty struct {
@@ -89,13 +112,13 @@ type ArrayProperty struct {
 	ElementType string
 }
 
-func readArrayProperty(r io.ReadSeeker, saveData *SaveData, varSize uint32) (interface{}, error) {
+func readArrayProperty(r io.ReadSeeker, saveData *SaveData, varSize uint32, tag *uint8) (interface{}, error) {
 	elementsType, err := readName(r, saveData)
 	if err != nil {
 		return ArrayProperty{}, err
 	}
 
-	_, err = r.Seek(1, io.SeekCurrent)
+	err = readTagFlag(r, tag)
 	if err != nil {
 		return ArrayProperty{}, err
 	}
@@ -114,15 +137,16 @@ func readArrayProperty(r io.ReadSeeker, saveData *SaveData, varSize uint32) (int
 
 		items := make([]StructProperty, arrayLength)
 		for i := 0; i < int(arrayLength); i++ {
-			value, err := readStructPropertyData(r, arrayStructProperty.ElementType, saveData)
+			value, properties, err := readStructPropertyData(r, arrayStructProperty.ElementType, saveData)
 			if err != nil {
 				return ArrayProperty{}, err
 			}
 			items[i] = StructProperty{
-				Name:  arrayStructProperty.ElementType,
-				Value: value,
-				GUID:  arrayStructProperty.GUID,
-				Size:  varSize,
+				Name:         arrayStructProperty.ElementType,
+				Value:        value,
+				PropertyList: properties,
+				GUID:         arrayStructProperty.GUID,
+				Size:         varSize,
 			}
 
 		}
@@ -136,7 +160,7 @@ func readArrayProperty(r io.ReadSeeker, saveData *SaveData, varSize uint32) (int
 		Items:       make([]interface{}, arrayLength),
 	}
 	for i := 0; i < int(arrayLength); i++ {
-		elementValue, err := getPropertyValue(r, elementsType, varSize, saveData, true)
+		elementValue, err := getPropertyValue(r, elementsType, varSize, saveData, nil)
 		if err != nil {
 			return ArrayProperty{}, err
 		}
@@ -146,51 +170,50 @@ func readArrayProperty(r io.ReadSeeker, saveData *SaveData, varSize uint32) (int
 	return result, nil
 }
 
+// readArrayStructHeader reads the property tag that arrays of structs repeat
+// for their elements. It names the array and the StructProperty type again,
+// everything but its size is kept so the tag is written back as it was.
 func readArrayStructHeader(r io.ReadSeeker, saveData *SaveData) (ArrayStructProperty, error) {
-	// skip first 2 bytes - variable name again
-	_, err := r.Seek(2, io.SeekCurrent)
+	result := ArrayStructProperty{}
+	var err error
+
+	result.TagName, err = readName(r, saveData)
 	if err != nil {
 		return ArrayStructProperty{}, err
 	}
 
-	// skip 2 more bytes - type again (StructProperty)
-	_, err = r.Seek(2, io.SeekCurrent)
+	result.TagType, err = readName(r, saveData)
 	if err != nil {
 		return ArrayStructProperty{}, err
 	}
 
-	// skip 4 bytes (array size in bytes)
-	size, err := memory.ReadInt[uint32](r)
+	// array size in bytes
+	result.Size, err = memory.ReadInt[uint32](r)
 	if err != nil {
 		return ArrayStructProperty{}, err
 	}
 
-	// skip 4 bytes - index
-	_, err = r.Seek(4, io.SeekCurrent)
+	result.TagIndex, err = memory.ReadInt[uint32](r)
 	if err != nil {
 		return ArrayStructProperty{}, err
 	}
 
-	elementType, err := readName(r, saveData)
+	result.ElementType, err = readName(r, saveData)
 	if err != nil {
 		return ArrayStructProperty{}, err
 	}
 
-	guid, err := ue.ReadGuid(r)
+	result.GUID, err = ue.ReadGuid(r)
 	if err != nil {
 		return ArrayStructProperty{}, err
 	}
 
-	_, err = r.Seek(1, io.SeekCurrent)
+	err = readTagFlag(r, &result.TagFlag)
 	if err != nil {
 		return ArrayStructProperty{}, err
 	}
 
-	return ArrayStructProperty{
-		ElementType: elementType,
-		GUID:        guid,
-		Size:        size,
-	}, nil
+	return result, nil
 }
 
 type StructProperty struct {
@@ -198,15 +221,30 @@ type StructProperty struct {
 	GUID  ue.FGuid
 	Value interface{}
 	Size  uint32
+	// PropertyList holds the ordered, typed properties of structs that are
+	// stored as a property map in Value.
+	PropertyList []Property
 }
 
-func readStructPropertyData(r io.ReadSeeker, structName string, saveData *SaveData) (interface{}, error) {
+func readStructPropertyData(r io.ReadSeeker, structName string, saveData *SaveData) (interface{}, []Property, error) {
+	value, err := readStructPropertyValue(r, structName, saveData)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if properties, ok := value.([]Property); ok {
+		return propertiesMap(properties), properties, nil
+	}
+	return value, nil, nil
+}
+
+func readStructPropertyValue(r io.ReadSeeker, structName string, saveData *SaveData) (interface{}, error) {
 	switch structName {
 	case "SoftClassPath":
-		return readStrProperty(r, true)
+		return readStrProperty(r, nil)
 
 	case "SoftObjectPath":
-		return readStrProperty(r, true)
+		return readStrProperty(r, nil)
 
 	case "Timespan":
 		return memory.ReadInt[int64](r)
@@ -238,7 +276,7 @@ func readStructPropertyData(r io.ReadSeeker, structName string, saveData *SaveDa
 		persistenceReader := bytes.NewReader(persistenceBytes)
 
 		if saveData.SaveGameClassPath.Path == REMNANT_SAVE_GAME_PROFILE {
-			archive, err := readSaveData(persistenceReader, true, false)
+			archive, err := readSaveData(persistenceReader, true, false, saveData.nameNumbers)
 			if err != nil {
 				return nil, err
 			}
@@ -295,7 +333,10 @@ func readStructPropertyData(r io.ReadSeeker, structName string, saveData *SaveDa
 		}
 
 		actors := make(map[uint64]Actor)
-		for _, info := range actorInfo {
+		actorOrder := make([]uint64, len(actorInfo))
+		for i, info := range actorInfo {
+			actorOrder[i] = info.UniqueID
+
 			_, err = persistenceReader.Seek(int64(info.Offset), io.SeekStart)
 			if err != nil {
 				return nil, err
@@ -309,7 +350,7 @@ func readStructPropertyData(r io.ReadSeeker, structName string, saveData *SaveDa
 
 			actorReader := bytes.NewReader(actorBytes)
 
-			actors[info.UniqueID], err = readActor(actorReader)
+			actors[info.UniqueID], err = readActor(actorReader, saveData.nameNumbers)
 			if err != nil {
 				return nil, err
 			}
@@ -325,11 +366,13 @@ func readStructPropertyData(r io.ReadSeeker, structName string, saveData *SaveDa
 			return nil, err
 		}
 
+		dynamicOrder := make([]uint64, dynamicCount)
 		for i := uint32(0); i < dynamicCount; i++ {
 			dynamicActor, err := readDynamicActor(persistenceReader)
 			if err != nil {
 				return nil, err
 			}
+			dynamicOrder[i] = dynamicActor.UniqueID
 
 			actor := actors[dynamicActor.UniqueID]
 			actor.DynamicData = &dynamicActor
@@ -337,18 +380,21 @@ func readStructPropertyData(r io.ReadSeeker, structName string, saveData *SaveDa
 		}
 
 		return PersistenceContainer{
-			Version:   version,
-			Destroyed: destroyed,
-			Actors:    actors,
+			Version:      version,
+			Destroyed:    destroyed,
+			Actors:       actors,
+			ActorOrder:   actorOrder,
+			DynamicOrder: dynamicOrder,
 		}, nil
 
 	default:
-		return readProperties(r, saveData)
+		_, properties, err := readProperties(r, saveData)
+		return properties, err
 	}
 }
 
-func readStructProperty(r io.ReadSeeker, saveData *SaveData, varSize uint32, raw bool) (interface{}, error) {
-	if raw {
+func readStructProperty(r io.ReadSeeker, saveData *SaveData, varSize uint32, tag *uint8) (interface{}, error) {
+	if tag == nil {
 		guid, err := ue.ReadGuid(r)
 		if err != nil {
 			return StructReference{}, err
@@ -364,26 +410,26 @@ func readStructProperty(r io.ReadSeeker, saveData *SaveData, varSize uint32, raw
 		return StructProperty{}, err
 	}
 
-	// 17 bytes, 16 GUID + padding?
 	guid, err := ue.ReadGuid(r)
 	if err != nil {
 		return StructProperty{}, err
 	}
-	_, err = r.Seek(1, io.SeekCurrent)
+	err = readTagFlag(r, tag)
 	if err != nil {
 		return StructProperty{}, err
 	}
 
-	result, err := readStructPropertyData(r, structName, saveData)
+	result, properties, err := readStructPropertyData(r, structName, saveData)
 	if err != nil {
 		return StructProperty{}, err
 	}
 
 	return StructProperty{
-		Name:  structName,
-		GUID:  guid,
-		Value: result,
-		Size:  varSize,
+		Name:         structName,
+		GUID:         guid,
+		Value:        result,
+		PropertyList: properties,
+		Size:         varSize,
 	}, nil
 }
 
@@ -392,13 +438,13 @@ type EnumProperty struct {
 	EnumValue string
 }
 
-func readEnumProperty(r io.ReadSeeker, saveData *SaveData) (EnumProperty, error) {
+func readEnumProperty(r io.ReadSeeker, saveData *SaveData, tag *uint8) (EnumProperty, error) {
 	enumType, err := readName(r, saveData)
 	if err != nil {
 		return EnumProperty{}, fmt.Errorf("readEnumProperty: %w", err)
 	}
 
-	_, err = r.Seek(1, io.SeekCurrent)
+	err = readTagFlag(r, tag)
 	if err != nil {
 		return EnumProperty{}, fmt.Errorf("readEnumProperty: %w", err)
 	}
@@ -430,12 +476,10 @@ type TextProperty struct {
 	Data        interface{}
 }
 
-func readTextProperty(r io.ReadSeeker, raw bool) (TextProperty, error) {
-	if !raw {
-		_, err := r.Seek(1, io.SeekCurrent)
-		if err != nil {
-			return TextProperty{}, err
-		}
+func readTextProperty(r io.ReadSeeker, tag *uint8) (TextProperty, error) {
+	err := readTagFlag(r, tag)
+	if err != nil {
+		return TextProperty{}, err
 	}
 
 	flags, err := memory.ReadInt[uint32](r)
@@ -508,50 +552,58 @@ type MapPropertyValue struct {
 type MapProperty struct {
 	KeyType   string
 	ValueType string
-	Values    []MapPropertyValue
+	// KeysToRemove is the number of keys removed from the default value of
+	// the map, 0 in saves.
+	KeysToRemove uint32
+	Values       []MapPropertyValue
 }
 
-func readMapProperty(r io.ReadSeeker, saveData *SaveData) ([]MapPropertyValue, error) {
+func readMapProperty(r io.ReadSeeker, saveData *SaveData, tag *uint8) (MapProperty, error) {
 	result := MapProperty{}
 
 	var err error
 
 	result.KeyType, err = readName(r, saveData)
 	if err != nil {
-		return nil, fmt.Errorf("readMapProperty: %w", err)
+		return MapProperty{}, fmt.Errorf("readMapProperty: %w", err)
 	}
 
 	result.ValueType, err = readName(r, saveData)
 	if err != nil {
-		return nil, fmt.Errorf("readMapProperty: %w", err)
+		return MapProperty{}, fmt.Errorf("readMapProperty: %w", err)
+	}
+
+	err = readTagFlag(r, tag)
+	if err != nil {
+		return MapProperty{}, fmt.Errorf("readMapProperty: %w", err)
 	}
 
-	_, err = r.Seek(5, io.SeekCurrent)
+	result.KeysToRemove, err = memory.ReadInt[uint32](r)
 	if err != nil {
-		return nil, fmt.Errorf("readMapProperty: %w", err)
+		return MapProperty{}, fmt.Errorf("readMapProperty: %w", err)
 	}
 
 	mapLength, err := memory.ReadInt[int32](r)
 	if err != nil {
-		return nil, fmt.Errorf("readMapProperty: %w", err)
+		return MapProperty{}, fmt.Errorf("readMapProperty: %w", err)
 	}
 
 	values := make([]MapPropertyValue, mapLength)
 	for i := 0; i < int(mapLength); i++ {
-		key, err := getPropertyValue(r, result.KeyType, 0, saveData, true)
+		key, err := getPropertyValue(r, result.KeyType, 0, saveData, nil)
 		if err != nil {
-			return nil, fmt.Errorf("readMapProperty: %w", err)
+			return MapProperty{}, fmt.Errorf("readMapProperty: %w", err)
 		}
-		value, err := getPropertyValue(r, result.ValueType, 0, saveData, true)
+		value, err := getPropertyValue(r, result.ValueType, 0, saveData, nil)
 		if err != nil {
-			return nil, fmt.Errorf("readMapProperty: %w", err)
+			return MapProperty{}, fmt.Errorf("readMapProperty: %w", err)
 		}
 
-		values[i] = struct{ Key, Value interface{} }{key, value}
+		values[i] = MapPropertyValue{Key: key, Value: value}
 	}
 	result.Values = values
 
-	return values, nil
+	return result, nil
 }
 
 type PersistenceBlob struct {
@@ -562,6 +614,10 @@ type PersistenceContainer struct {
 	Version   uint32
 	Destroyed []uint64
 	Actors    map[uint64]Actor
+	// ActorOrder and DynamicOrder are the UniqueIDs of the actors in the
+	// order of the index and of the dynamic actors, the writer keeps it.
+	ActorOrder   []uint64
+	DynamicOrder []uint64
 }
 
 type Actor struct {
@@ -570,27 +626,28 @@ type Actor struct {
 	DynamicData *DynamicActor
 }
 
-func readActor(r io.ReadSeeker) (Actor, error) {
+func readActor(r io.ReadSeeker, nameNumbers bool) (Actor, error) {
 	hasTransform, err := memory.ReadInt[uint32](r)
 	if err != nil {
 		return Actor{}, fmt.Errorf("readActor: %w", err)
 	}
 
-	var transform ue.FTransform
+	var transform *ue.FTransform
 	if hasTransform != 0 {
-		transform, err = ue.ReadFTransform(r)
+		actorTransform, err := ue.ReadFTransform(r)
 		if err != nil {
 			return Actor{}, fmt.Errorf("readActor: %w", err)
 		}
+		transform = &actorTransform
 	}
 
-	archive, err := readSaveData(r, false, false)
+	archive, err := readSaveData(r, false, false, nameNumbers)
 	if err != nil {
 		return Actor{}, fmt.Errorf("readActor: %w", err)
 	}
 
 	return Actor{
-		Transform: &transform,
+		Transform: transform,
 		Archive:   archive,
 	}, nil
 }
@@ -628,16 +685,14 @@ type Number interface {
 	memory.Int | float64 | float32
 }
 
-func readNumProperty[T Number](r io.ReadSeeker, raw bool) (T, error) {
-	if !raw {
-		_, err := r.Seek(1, io.SeekCurrent)
-		if err != nil {
-			return 0, fmt.Errorf("readIntProperty: %w", err)
-		}
+func readNumProperty[T Number](r io.ReadSeeker, tag *uint8) (T, error) {
+	err := readTagFlag(r, tag)
+	if err != nil {
+		return 0, fmt.Errorf("readIntProperty: %w", err)
 	}
 
 	var varData T
-	err := binary.Read(r, binary.LittleEndian, &varData)
+	err = binary.Read(r, binary.LittleEndian, &varData)
 	if err != nil {
 		return 0, fmt.Errorf("readIntProperty: %w", err)
 	}
@@ -645,6 +700,9 @@ func readNumProperty[T Number](r io.ReadSeeker, raw bool) (T, error) {
 	return varData, nil
 }
 
+// readName reads a name from the names table. Names with a number are
+// returned the way Unreal prints them, Name_N for the number N+1, when the
+// save is read exactly and as the plain name otherwise.
 func readName(r io.Reader, saveData *SaveData) (string, error) {
 	fName, err := ue.ReadFName(r)
 	if err != nil {
@@ -655,135 +713,126 @@ func readName(r io.Reader, saveData *SaveData) (string, error) {
 		return "", fmt.Errorf("readNameProperty: invalid index %d", fName.Index)
 	}
 
-	return saveData.NamesTable[fName.Index], nil
+	name := saveData.NamesTable[fName.Index]
+	if fName.Number != 0 && saveData.nameNumbers {
+		return name + "_" + strconv.Itoa(int(fName.Number)-1), nil
+	}
+	return name, nil
 }
 
-func readBoolProperty(r io.ReadSeeker, raw bool) (bool, error) {
+func readBoolProperty(r io.ReadSeeker, tag *uint8) (bool, error) {
 	varData, err := memory.ReadInt[uint8](r)
 	if err != nil {
 		return false, fmt.Errorf("readBoolProperty: %w", err)
 	}
-	if !raw {
-		_, err = r.Seek(1, io.SeekCurrent)
-		if err != nil {
-			return false, fmt.Errorf("readBoolProperty: %w", err)
-		}
+	err = readTagFlag(r, tag)
+	if err != nil {
+		return false, fmt.Errorf("readBoolProperty: %w", err)
 	}
 	return varData == 1, nil
 }
 
-func readStrProperty(r io.ReadSeeker, raw bool) (string, error) {
-	if !raw {
-		_, err := r.Seek(1, io.SeekCurrent)
-		if err != nil {
-			return "", fmt.Errorf("readStrProperty: %w", err)
-		}
-	}
-
-	strLength, err := memory.ReadInt[int32](r)
+func readStrProperty(r io.ReadSeeker, tag *uint8) (string, error) {
+	err := readTagFlag(r, tag)
 	if err != nil {
 		return "", fmt.Errorf("readStrProperty: %w", err)
 	}
 
-	if strLength == 0 {
-		return "", nil
-	}
-	strData := make([]byte, strLength)
-	_, err = r.Read(strData)
+	value, err := ue.ReadFString(r)
 	if err != nil {
 		return "", fmt.Errorf("readStrProperty: %w", err)
 	}
-
-	return string(bytes.Trim(strData, "\x00")), nil
+	return value, nil
 }
 
-func readNameProperty(r io.ReadSeeker, saveData *SaveData, raw bool) (string, error) {
-	if !raw {
-		_, err := r.Seek(1, io.SeekCurrent)
-		if err != nil {
-			return "", err
-		}
+func readNameProperty(r io.ReadSeeker, saveData *SaveData, tag *uint8) (string, error) {
+	err := readTagFlag(r, tag)
+	if err != nil {
+		return "", err
 	}
 
 	return readName(r, saveData)
 }
 
-func getPropertyValue(r io.ReadSeeker, varType string, varSize uint32, saveData *SaveData, raw bool) (interface{}, error) {
+func getPropertyValue(r io.ReadSeeker, varType string, varSize uint32, saveData *SaveData, tag *uint8) (interface{}, error) {
 	switch varType {
 	case "IntProperty":
-		return readNumProperty[int32](r, raw)
+		return readNumProperty[int32](r, tag)
 
 	case "Int16Property":
-		return readNumProperty[int16](r, raw)
+		return readNumProperty[int16](r, tag)
 
 	case "Int64Property":
-		return readNumProperty[int64](r, raw)
+		return readNumProperty[int64](r, tag)
 
 	case "UInt64Property":
-		return readNumProperty[uint64](r, raw)
+		return readNumProperty[uint64](r, tag)
 
 	case "FloatProperty":
-		return readNumProperty[float32](r, raw)
+		return readNumProperty[float32](r, tag)
 
 	case "DoubleProperty":
-		return readNumProperty[float64](r, raw)
+		return readNumProperty[float64](r, tag)
 
 	case "UInt16Property":
-		return readNumProperty[uint16](r, raw)
+		return readNumProperty[uint16](r, tag)
 
 	case "UInt32Property":
-		return readNumProperty[uint32](r, raw)
+		return readNumProperty[uint32](r, tag)
 
 	case "SoftClassPath":
-		if !raw {
-			_, err := r.Seek(1, io.SeekCurrent)
-			if err != nil {
-				return "", err
-			}
+		err := readTagFlag(r, tag)
+		if err != nil {
+			return "", err
 		}
 		return ue.ReadFString(r)
 
 	case "SoftObjectProperty":
-		if !raw {
-			_, err := r.Seek(1, io.SeekCurrent)
-			if err != nil {
-				return "", err
-			}
+		err := readTagFlag(r, tag)
+		if err != nil {
+			return "", err
 		}
 		return ue.ReadFString(r)
 
 	case "BoolProperty":
-		return readBoolProperty(r, raw)
+		return readBoolProperty(r, tag)
 
 	case "MapProperty":
-		if raw {
-			log.Fatal("Raw map property is not supported yet")
+		if tag == nil {
+			return nil, fmt.Errorf("raw map property is not supported yet")
 		}
-		return readMapProperty(r, saveData)
+		return readMapProperty(r, saveData, tag)
 
 	case "EnumProperty":
-		return readEnumProperty(r, saveData)
+		// Raw enums are read with a tag byte as well, it is not kept.
+		if tag == nil {
+			tag = new(uint8)
+		}
+		return readEnumProperty(r, saveData, tag)
 
 	case "StrProperty":
-		return readStrProperty(r, raw)
+		return readStrProperty(r, tag)
 
 	case "TextProperty":
-		return readTextProperty(r, raw)
+		return readTextProperty(r, tag)
 
 	case "NameProperty":
-		return readNameProperty(r, saveData, raw)
+		return readNameProperty(r, saveData, tag)
 
 	case "ArrayProperty":
-		return readArrayProperty(r, saveData, varSize)
+		if tag == nil {
+			tag = new(uint8)
+		}
+		return readArrayProperty(r, saveData, varSize, tag)
 
 	case "StructProperty":
-		return readStructProperty(r, saveData, varSize, raw)
+		return readStructProperty(r, saveData, varSize, tag)
 
 	case "ObjectProperty":
-		return readObjectProperty(r, saveData, raw)
+		return readObjectProperty(r, saveData, tag)
 
 	case "ByteProperty":
-		return readByteProperty(r, saveData, raw)
+		return readByteProperty(r, saveData, tag)
 
 	case "None":
 		return nil, nil
@@ -819,39 +868,55 @@ func readProperty(r io.ReadSeeker, saveData *SaveData) (*Property, error) {
 	}
 
 	var value interface{}
+	var tagFlag uint8
 	if varName == "FowVisitedCoordinates" || varName == "StartingRotation" {
-		_, err := r.Seek(int64(varSize+19), io.SeekCurrent)
+		// Not decoded, the raw bytes are kept so the property can be written
+		// back unchanged.
+		rawValue := make([]byte, varSize+19)
+		_, err := io.ReadFull(r, rawValue)
 		if err != nil {
 			return nil, err
 		}
+		value = rawValue
 	} else {
-		value, err = getPropertyValue(r, varType, varSize, saveData, false)
+		value, err = getPropertyValue(r, varType, varSize, saveData, &tagFlag)
 		if err != nil {
 			return nil, fmt.Errorf("failed to read variable data (%s %s %d): %w", varName, varType, varSize, err)
 		}
 	}
 
 	return &Property{
-		Name:  varName,
-		Type:  varType,
-		Index: index,
-		Size:  varSize,
-		Value: value,
+		Name:    varName,
+		Type:    varType,
+		Index:   index,
+		Size:    varSize,
+		Value:   value,
+		TagFlag: tagFlag,
 	}, nil
 }
 
-func readProperties(r io.ReadSeeker, saveData *SaveData) (map[string]interface{}, error) {
-	result := map[string]interface{}{}
+// readProperties reads tagged properties up to the "None" terminator. The
+// returned list keeps their order and types, the map is a lookup by name.
+func readProperties(r io.ReadSeeker, saveData *SaveData) (map[string]interface{}, []Property, error) {
+	result := []Property{}
 	for {
 		property, err := readProperty(r, saveData)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 		if property == nil {
 			break
 		}
-		result[property.Name] = property.Value
+		result = append(result, *property)
 	}
 
-	return result, nil
+	return propertiesMap(result), result, nil
+}
+
+func propertiesMap(properties []Property) map[string]interface{} {
+	result := make(map[string]interface{}, len(properties))
+	for _, property := range properties {
+		result[property.Name] = property.Value
+	}
+	return result
 }
@@ -0,0 +1,527 @@
+package remnant
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"hash/crc32"
+	"path/filepath"
+	"testing"
+	"unicode/utf16"
+)
+
+// saveBuilder writes the save layout byte by byte, independent of the
+// writer, so the tests can tell whether the writer reproduces a file.
+type saveBuilder struct {
+	bytes.Buffer
+}
+
+func build(write func(b *saveBuilder)) []byte {
+	var b saveBuilder
+	write(&b)
+	return b.Bytes()
+}
+
+func (b *saveBuilder) int(value interface{}) {
+	binary.Write(b, binary.LittleEndian, value)
+}
+
+func (b *saveBuilder) str(value string) {
+	b.int(int32(len(value) + 1))
+	b.WriteString(value)
+	b.WriteByte(0)
+}
+
+func (b *saveBuilder) wide(value string) {
+	chars := append(utf16.Encode([]rune(value)), 0)
+	b.int(-int32(len(chars)))
+	b.int(chars)
+}
+
+func (b *saveBuilder) name(index uint16) {
+	b.int(index)
+}
+
+func (b *saveBuilder) numberedName(index uint16, number int32) {
+	b.int(index | 1<<15)
+	b.int(number)
+}
+
+// property writes a property tag followed by its value, the size covers
+// the value only.
+func (b *saveBuilder) property(name, varType uint16, tag, value []byte) {
+	b.name(name)
+	b.name(varType)
+	b.int(uint32(len(value)))
+	b.int(uint32(0))
+	b.Write(tag)
+	b.Write(value)
+}
+
+func (b *saveBuilder) sized(data []byte) {
+	b.int(uint32(len(data)))
+	b.Write(data)
+}
+
+// archive writes the offsets, objects data and tables of an archive. The
+// offsets are relative to the start of b.
+func (b *saveBuilder) archive(names []string, namesFirst bool, objectsTable, objectsData []byte) {
+	offsetsPos := b.Len()
+	b.int(OffsetInfo{})
+	b.Write(objectsData)
+
+	var namesOffset, objectsOffset int
+	writeNames := func() {
+		namesOffset = b.Len()
+		b.int(int32(len(names)))
+		for _, name := range names {
+			if isASCII(name) {
+				b.str(name)
+			} else {
+				b.wide(name)
+			}
+		}
+	}
+	writeObjects := func() {
+		objectsOffset = b.Len()
+		b.Write(objectsTable)
+	}
+	if namesFirst {
+		writeNames()
+		writeObjects()
+	} else {
+		writeObjects()
+		writeNames()
+	}
+
+	offsets := build(func(o *saveBuilder) {
+		o.int(OffsetInfo{Names: uint64(namesOffset), Version: 3, Objects: uint64(objectsOffset)})
+	})
+	copy(b.Bytes()[offsetsPos:], offsets)
+}
+
+func isASCII(value string) bool {
+	for _, char := range value {
+		if char > 0x7f {
+			return false
+		}
+	}
+	return true
+}
+
+const (
+	nameNone = iota
+	nameIntProperty
+	nameHealth
+	nameStrProperty
+	nameTitle
+	nameArrayProperty
+	nameSlots
+	nameStructProperty
+	nameSlotInfo
+	nameMapProperty
+	nameCounts
+	nameNameProperty
+	nameBoolProperty
+	nameAlive
+	nameEnumProperty
+	nameKind
+	nameEKind
+	nameEKindGreen
+	nameItem
+	nameBlob
+	namePersistenceBlob
+	nameVars
+)
+
+var fixtureNames = []string{
+	"None", "IntProperty", "Health", "StrProperty", "Title", "ArrayProperty", "Slots", "StructProperty",
+	"SlotInfo", "MapProperty", "Counts", "NameProperty", "BoolProperty", "Alive", "EnumProperty", "Kind",
+	"EKind", "EKind::Grün", "Item", "Blob", "PersistenceBlob", "Vars",
+}
+
+func fixtureTransform(b *saveBuilder, value float64) {
+	for i := 0; i < 10; i++ {
+		b.int(value + float64(i))
+	}
+}
+
+// fixtureActor is an actor with its own archive, with the names table in
+// front of the objects table.
+func fixtureActor(hasTransform bool, level int32) []byte {
+	return build(func(b *saveBuilder) {
+		if hasTransform {
+			b.int(uint32(1))
+			fixtureTransform(b, 1)
+		} else {
+			b.int(uint32(0))
+		}
+		objectsTable := build(func(b *saveBuilder) {
+			b.int(int32(1))
+			b.int(uint8(1))
+			b.str("/Game/World/Actor.Actor_C")
+		})
+		objectsData := build(func(b *saveBuilder) {
+			b.int(uint32(0))
+			b.sized(build(func(b *saveBuilder) {
+				b.property(2, 1, []byte{0}, build(func(b *saveBuilder) { b.int(level) }))
+				b.name(0)
+			}))
+			b.int(uint8(0))
+		})
+		b.archive([]string{"None", "IntProperty", "Level"}, true, objectsTable, objectsData)
+	})
+}
+
+// fixtureContainer has its actors out of UniqueID order.
+func fixtureContainer() []byte {
+	actors := [][]byte{fixtureActor(true, 3), fixtureActor(false, 5)}
+	uniqueIDs := []uint64{9, 3}
+
+	return build(func(b *saveBuilder) {
+		b.int(uint32(2))
+		indexOffset := 12
+		for _, actor := range actors {
+			indexOffset += len(actor)
+		}
+		dynamicOffset := indexOffset + 4 + len(actors)*16 + 4 + 8
+		b.int(uint32(indexOffset))
+		b.int(uint32(dynamicOffset))
+
+		offset := 12
+		for _, actor := range actors {
+			b.Write(actor)
+		}
+		b.int(uint32(len(actors)))
+		for i, actor := range actors {
+			b.int(uniqueIDs[i])
+			b.int(uint32(offset))
+			b.int(uint32(len(actor)))
+			offset += len(actor)
+		}
+		b.int(uint32(1))
+		b.int(uint64(42))
+
+		b.int(uint32(1))
+		b.int(uint64(3))
+		fixtureTransform(b, 10)
+		b.str("/Game/World/Dynamic")
+		b.str("Dynamic_C")
+	})
+}
+
+// fixtureSave returns a decompressed save with the parts the reader does
+// not interpret set: numbered names, UTF-16 strings, the index of the tag
+// in front of struct array elements, a tag byte and the reserved bytes of
+// variables.
+func fixtureSave() []byte {
+	properties := build(func(b *saveBuilder) {
+		b.property(nameHealth, nameIntProperty, []byte{0}, build(func(b *saveBuilder) { b.int(int32(100)) }))
+		b.property(nameTitle, nameStrProperty, []byte{0}, build(func(b *saveBuilder) { b.wide("Grüße") }))
+
+		slot := build(func(b *saveBuilder) {
+			b.property(nameKind, nameNameProperty, []byte{0}, build(func(b *saveBuilder) { b.numberedName(nameItem, 3) }))
+			b.name(nameNone)
+		})
+		b.property(nameSlots, nameArrayProperty,
+			build(func(b *saveBuilder) {
+				b.name(nameStructProperty)
+				b.int(uint8(0))
+			}),
+			build(func(b *saveBuilder) {
+				b.int(uint32(1))
+				b.name(nameSlots)
+				b.name(nameStructProperty)
+				b.int(uint32(len(slot)))
+				b.int(uint32(7))
+				b.name(nameSlotInfo)
+				b.int([4]uint32{1, 2, 3, 4})
+				b.int(uint8(0))
+				b.Write(slot)
+			}))
+
+		b.property(nameCounts, nameMapProperty,
+			build(func(b *saveBuilder) {
+				b.name(nameNameProperty)
+				b.name(nameIntProperty)
+				b.int(uint8(0))
+			}),
+			build(func(b *saveBuilder) {
+				b.int(uint32(0))
+				b.int(int32(1))
+				b.numberedName(nameItem, 1)
+				b.int(int32(5))
+			}))
+
+		// The value of a bool is in its tag, followed by a tag byte that is
+		// not 0.
+		b.property(nameAlive, nameBoolProperty, []byte{1, 2}, nil)
+
+		b.property(nameKind, nameEnumProperty,
+			build(func(b *saveBuilder) {
+				b.name(nameEKind)
+				b.int(uint8(0))
+			}),
+			build(func(b *saveBuilder) { b.name(nameEKindGreen) }))
+
+		container := fixtureContainer()
+		b.property(nameBlob, nameStructProperty,
+			build(func(b *saveBuilder) {
+				b.name(namePersistenceBlob)
+				b.int([4]uint32{})
+				b.int(uint8(0))
+			}),
+			build(func(b *saveBuilder) { b.sized(container) }))
+
+		b.name(nameNone)
+	})
+
+	variables := build(func(b *saveBuilder) {
+		b.name(nameVars)
+		b.int(uint64(0x1122))
+		b.int(uint32(2))
+		b.name(nameAlive)
+		b.int(uint8(VarTypeBool))
+		b.int(uint32(1))
+		b.name(nameHealth)
+		b.int(uint8(VarTypeInt))
+		b.int(int32(-7))
+	})
+
+	objectsData := build(func(b *saveBuilder) {
+		b.int(uint32(0))
+		b.sized(properties)
+		b.int(uint8(1))
+		b.int(uint32(1))
+		b.str("GlobalVariables")
+		b.sized(variables)
+
+		b.int(uint32(1))
+		b.int(uint32(0))
+		b.int(uint8(0))
+	})
+
+	objectsTable := build(func(b *saveBuilder) {
+		b.int(int32(2))
+		b.int(uint8(1))
+		b.int(uint8(0))
+		b.str("/Script/Remnant.Thing")
+		b.name(nameItem)
+		b.int(uint32(0))
+	})
+
+	data := build(func(b *saveBuilder) {
+		b.int(SaveHeader{SaveGameFileVersion: 9, BuildNumber: 1234})
+		b.int(PackageVersion{UE4Version: 522, UE5Version: 1009})
+		b.str(REMNANT_SAVE_GAME)
+		b.str("BP_RemnantSaveGame_C")
+		b.archive(fixtureNames, false, objectsTable, objectsData)
+	})
+
+	binary.LittleEndian.PutUint32(data[4:], uint32(len(data)))
+	binary.LittleEndian.PutUint32(data[0:], crc32.ChecksumIEEE(data[4:]))
+	return data
+}
+
+func TestReadFixture(t *testing.T) {
+	archive, err := ReadExactSaveArchive(bytes.NewReader(fixtureSave()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	object := archive.Data.Objects[0]
+	slots := object.Properties["Slots"].(ArrayStructProperty)
+	variables := object.Components[0].Properties["GlobalVariables"].(Variables)
+	container := object.Properties["Blob"].(StructProperty).Value.(PersistenceContainer)
+
+	tests := []struct {
+		name string
+		got  interface{}
+		want interface{}
+	}{
+		{"utf-16 string", object.Properties["Title"], "Grüße"},
+		{"utf-16 name", object.Properties["Kind"].(EnumProperty).EnumValue, "EKind::Grün"},
+		{"numbered name", slots.Items[0].Value.(map[string]interface{})["Kind"], "Item_2"},
+		{"numbered map key", object.Properties["Counts"].(MapProperty).Values[0].Key, "Item_0"},
+		{"array tag index", slots.TagIndex, uint32(7)},
+		{"bool tag flag", object.PropertyList[4].TagFlag, uint8(2)},
+		{"variables reserved", variables.Reserved, uint64(0x1122)},
+		{"actor order", container.ActorOrder, []uint64{9, 3}},
+		{"dynamic order", container.DynamicOrder, []uint64{3}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, _ := json.Marshal(test.got)
+			want, _ := json.Marshal(test.want)
+			if !bytes.Equal(got, want) {
+				t.Errorf("got %s, want %s", got, want)
+			}
+		})
+	}
+}
+
+// TestReadPlainNames checks that saves read for looking at them keep names
+// without their number, the way the item and event lookups compare them.
+func TestReadPlainNames(t *testing.T) {
+	archive, err := ReadSaveArchive(bytes.NewReader(fixtureSave()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	object := archive.Data.Objects[0]
+	slots := object.Properties["Slots"].(ArrayStructProperty)
+	if kind := slots.Items[0].Value.(map[string]interface{})["Kind"]; kind != "Item" {
+		t.Errorf("numbered name = %v, want Item", kind)
+	}
+	if key := object.Properties["Counts"].(MapProperty).Values[0].Key; key != "Item" {
+		t.Errorf("numbered map key = %v, want Item", key)
+	}
+}
+
+// TestRoundTrip dumps the fixture to JSON, imports the JSON again and checks
+// that the written save is the same, byte for byte.
+func TestRoundTrip(t *testing.T) {
+	original := fixtureSave()
+
+	archive, err := ReadExactSaveArchive(bytes.NewReader(original))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dump, err := json.Marshal(&archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var imported SaveArchive
+	err = json.Unmarshal(dump, &imported)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	written, err := WriteSaveArchive(&imported)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(written, original) {
+		t.Fatalf("written save differs from the original at byte %d of %d", firstDifference(written, original), len(original))
+	}
+
+	path := filepath.Join(t.TempDir(), "save_0.sav")
+	_, err = WriteData(path, written)
+	if err != nil {
+		t.Fatal(err)
+	}
+	read, err := ReadData(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(read, original) {
+		t.Fatalf("decompressed save differs from the original at byte %d of %d", firstDifference(read, original), len(original))
+	}
+}
+
+func firstDifference(a, b []byte) int {
+	for i := range min(len(a), len(b)) {
+		if a[i] != b[i] {
+			return i
+		}
+	}
+	return min(len(a), len(b))
+}
+
+func TestWriteSaveArchiveChanges(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(object *UObject)
+		check  func(t *testing.T, object UObject)
+	}{
+		{
+			name: "changed value",
+			change: func(object *UObject) {
+				object.PropertyList[0].Value = int32(50)
+			},
+			check: func(t *testing.T, object UObject) {
+				if got := object.Properties["Health"]; got != int32(50) {
+					t.Errorf("Health = %v, want 50", got)
+				}
+			},
+		},
+		{
+			name: "new numbered name",
+			change: func(object *UObject) {
+				object.PropertyList[0] = Property{Name: "Health_4", Type: "IntProperty", Value: int32(1)}
+			},
+			check: func(t *testing.T, object UObject) {
+				if got := object.Properties["Health_4"]; got != int32(1) {
+					t.Errorf("Health_4 = %v, want 1", got)
+				}
+			},
+		},
+		{
+			name: "new name",
+			change: func(object *UObject) {
+				object.PropertyList[0] = Property{Name: "Armor_01", Type: "StrProperty", Value: "Ünïcode"}
+			},
+			check: func(t *testing.T, object UObject) {
+				if got := object.Properties["Armor_01"]; got != "Ünïcode" {
+					t.Errorf("Armor_01 = %v, want Ünïcode", got)
+				}
+			},
+		},
+		{
+			name: "new object reference",
+			change: func(object *UObject) {
+				object.PropertyList[0] = Property{Name: "Health", Type: "ObjectProperty", Value: ObjectProperty{ClassName: "/Game/New.New_C", Index: -1}}
+			},
+			check: func(t *testing.T, object UObject) {
+				if got := object.Properties["Health"].(ObjectProperty); got.ClassName != "/Game/New.New_C" || got.Index != 2 {
+					t.Errorf("Health = %+v, want /Game/New.New_C at 2", got)
+				}
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			archive, err := ReadExactSaveArchive(bytes.NewReader(fixtureSave()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			test.change(&archive.Data.Objects[0])
+
+			written, err := WriteSaveArchive(&archive)
+			if err != nil {
+				t.Fatal(err)
+			}
+			read, err := ReadExactSaveArchive(bytes.NewReader(written))
+			if err != nil {
+				t.Fatal(err)
+			}
+			test.check(t, read.Data.Objects[0])
+		})
+	}
+}
+
+func TestSplitNameNumber(t *testing.T) {
+	tests := []struct {
+		name   string
+		base   string
+		number int32
+		ok     bool
+	}{
+		{"Item_0", "Item", 1, true},
+		{"Item_2", "Item", 3, true},
+		{"Item_Key_12", "Item_Key", 13, true},
+		{"Item", "", 0, false},
+		{"Item_", "", 0, false},
+		{"Item_01", "", 0, false},
+		{"Item_-1", "", 0, false},
+		{"Item_2147483647", "", 0, false},
+		{"Item_x", "", 0, false},
+	}
+	for _, test := range tests {
+		base, number, ok := splitNameNumber(test.name)
+		if base != test.base || number != test.number || ok != test.ok {
+			t.Errorf("splitNameNumber(%q) = %q, %d, %t, want %q, %d, %t", test.name, base, number, ok, test.base, test.number, test.ok)
+		}
+	}
+}
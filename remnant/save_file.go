@@ -73,7 +73,7 @@ func decompressData(data []byte) ([]byte, error) {
 func readSave(filePath string) (*SaveFile, error) {
 	file, err := os.Open(filePath)
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
 	defer file.Close()
 
@@ -178,3 +178,116 @@ func ReadData(filePath string) ([]byte, error) {
 
 	return decompressChunks(saveFile)
 }
+
+func compressData(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+
+	zw := zlib.NewWriter(&buf)
+	_, err := zw.Write(data)
+	if err != nil {
+		return nil, err
+	}
+
+	err = zw.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// compressChunks is the reverse of decompressChunks. data is the decompressed
+// save starting with the checksum and the size, the size and the checksum
+// are updated in place.
+func compressChunks(data []byte) (*SaveFile, error) {
+	if len(data) < 12 {
+		return nil, fmt.Errorf("save data is too short")
+	}
+
+	binary.LittleEndian.PutUint32(data[4:], uint32(len(data)))
+	crc := crc32.Checksum(data[4:], crc32.MakeTable(crc32.IEEE))
+	binary.LittleEndian.PutUint32(data[0:], crc)
+
+	chunks := []CompressedSaveChunk{}
+	for offset := 8; offset < len(data); offset += LOADING_COMPRESSION_CHUNK_SIZE {
+		end := min(offset+LOADING_COMPRESSION_CHUNK_SIZE, len(data))
+
+		compressed, err := compressData(data[offset:end])
+		if err != nil {
+			return nil, fmt.Errorf("failed to compress chunk: %w", err)
+		}
+
+		chunks = append(chunks, CompressedSaveChunk{
+			Header: CompressedChunkHeader{
+				PackageFileTag:               ARCHIVE_V2_HEADER_TAG,
+				LoadingCompressionChunkSize:  LOADING_COMPRESSION_CHUNK_SIZE,
+				Compressor:                   CompressorZlib,
+				CompressedSize:               uint64(len(compressed)),
+				LoadingCompressionChunkSize2: uint64(end - offset),
+				CompressedSize2:              uint64(len(compressed)),
+				LoadingCompressionChunkSize3: uint64(end - offset),
+			},
+			Data: compressed,
+		})
+	}
+
+	return &SaveFile{
+		Crc32:       crc,
+		ContentSize: uint32(len(data)),
+		Version:     binary.LittleEndian.Uint32(data[8:]),
+		Chunks:      chunks,
+	}, nil
+}
+
+func writeSave(w io.Writer, saveFile *SaveFile) error {
+	err := binary.Write(w, binary.LittleEndian, saveFile.Crc32)
+	if err != nil {
+		return err
+	}
+
+	err = binary.Write(w, binary.LittleEndian, saveFile.ContentSize)
+	if err != nil {
+		return err
+	}
+
+	err = binary.Write(w, binary.LittleEndian, saveFile.Version)
+	if err != nil {
+		return err
+	}
+
+	for _, chunk := range saveFile.Chunks {
+		err = binary.Write(w, binary.LittleEndian, chunk.Header)
+		if err != nil {
+			return err
+		}
+
+		_, err = w.Write(chunk.Data)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// WriteData compresses decompressed save data, as returned by ReadData or
+// WriteSaveArchive, into a save file and returns the checksum it wrote.
+func WriteData(filePath string, data []byte) (uint32, error) {
+	saveFile, err := compressChunks(data)
+	if err != nil {
+		return 0, err
+	}
+
+	file, err := os.Create(filePath)
+	if err != nil {
+		return 0, err
+	}
+
+	err = writeSave(file, saveFile)
+	if err != nil {
+		file.Close()
+		return 0, err
+	}
+
+	return saveFile.Crc32, file.Close()
+}
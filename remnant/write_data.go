@@ -0,0 +1,469 @@
+package remnant
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"refinder/memory"
+	"refinder/ue"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// The writer mirrors the reader in process_data.go and property_types.go:
+// every value is written in the same layout it is read from, so a decoded
+// archive can be encoded again and read back without changes. Offsets are
+// relative to the start of the buffer that holds the archive, just like the
+// reader seeks relative to the start of its reader.
+
+type nameTable struct {
+	names   []string
+	indices map[string]int
+}
+
+func newNameTable(names []string) *nameTable {
+	table := &nameTable{
+		names:   slices.Clone(names),
+		indices: make(map[string]int, len(names)),
+	}
+	for i, name := range names {
+		if _, ok := table.indices[name]; !ok {
+			table.indices[name] = i
+		}
+	}
+	return table
+}
+
+// index returns the index of name, adding it to the table if needed.
+func (t *nameTable) index(name string) (uint16, error) {
+	if i, ok := t.indices[name]; ok {
+		return uint16(i), nil
+	}
+
+	// The highest bit of the index marks a name with a number.
+	if len(t.names) >= 1<<15 {
+		return 0, fmt.Errorf("names table is full, can not add %q", name)
+	}
+	t.names = append(t.names, name)
+	t.indices[name] = len(t.names) - 1
+	return uint16(len(t.names) - 1), nil
+}
+
+type archiveWriter struct {
+	buf      *bytes.Buffer
+	saveData *SaveData
+	names    *nameTable
+}
+
+// splitNameNumber splits a name the way readName prints names with a
+// number, Name_N is Name with the number N+1.
+func splitNameNumber(name string) (string, int32, bool) {
+	i := strings.LastIndexByte(name, '_')
+	if i < 0 {
+		return "", 0, false
+	}
+	suffix := name[i+1:]
+	number, err := strconv.ParseInt(suffix, 10, 32)
+	if err != nil || number < 0 || number == math.MaxInt32 || strconv.FormatInt(number, 10) != suffix {
+		return "", 0, false
+	}
+	return name[:i], int32(number) + 1, true
+}
+
+func (w *archiveWriter) writeName(name string) error {
+	// A name in the table is written as is, Name_N only falls back to Name
+	// with a number when it is not.
+	var number int32
+	if _, ok := w.names.indices[name]; !ok {
+		if base, baseNumber, ok := splitNameNumber(name); ok {
+			if _, ok := w.names.indices[base]; ok {
+				name, number = base, baseNumber
+			}
+		}
+	}
+
+	index, err := w.names.index(name)
+	if err != nil {
+		return err
+	}
+	if number == 0 {
+		return memory.WriteInt(w.buf, index)
+	}
+
+	err = memory.WriteInt(w.buf, index|1<<15)
+	if err != nil {
+		return err
+	}
+	return memory.WriteInt(w.buf, number)
+}
+
+// objectIndex resolves an object reference. The stored index is used while it
+// still points at the same path, otherwise the object is looked up by path
+// and added as a loaded object if the archive does not reference it yet.
+func (w *archiveWriter) objectIndex(object ObjectProperty) int32 {
+	objects := w.saveData.Objects
+	if object.Index >= 0 && int(object.Index) < len(objects) && objects[object.Index].ObjectPath == object.ClassName {
+		return object.Index
+	}
+	if object.ClassName == "" {
+		return -1
+	}
+
+	for i, obj := range objects {
+		if obj.ObjectPath == object.ClassName {
+			return int32(i)
+		}
+	}
+
+	w.saveData.Objects = append(w.saveData.Objects, UObject{
+		ObjectID:   uint32(len(objects)),
+		WasLoaded:  true,
+		ObjectPath: object.ClassName,
+		LoadedData: &UObjectLoadedData{},
+		Properties: map[string]interface{}{},
+	})
+	return int32(len(objects))
+}
+
+// WriteSaveArchive encodes an archive into the uncompressed save layout that
+// ReadSaveArchive reads. The header is written as is, WriteData fills in the
+// size and checksum.
+func WriteSaveArchive(archive *SaveArchive) ([]byte, error) {
+	var buf bytes.Buffer
+
+	err := binary.Write(&buf, binary.LittleEndian, archive.Header)
+	if err != nil {
+		return nil, err
+	}
+
+	err = writeSaveData(&buf, archive.Data, true, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func writeSaveData(buf *bytes.Buffer, saveData SaveData, hasPackageVersion bool, hasTopLevelAssetPath bool) error {
+	// Objects may be appended while resolving object references, work on a
+	// copy so the caller's archive is left untouched.
+	saveData.Objects = slices.Clone(saveData.Objects)
+
+	w := &archiveWriter{
+		buf:      buf,
+		saveData: &saveData,
+		names:    newNameTable(saveData.NamesTable),
+	}
+
+	if hasPackageVersion {
+		packageVersion := PackageVersion{}
+		if saveData.PackageVersion != nil {
+			packageVersion = *saveData.PackageVersion
+		}
+		err := binary.Write(buf, binary.LittleEndian, packageVersion)
+		if err != nil {
+			return fmt.Errorf("failed to write package version: %w", err)
+		}
+	}
+	if hasTopLevelAssetPath {
+		if saveData.SaveGameClassPath == nil {
+			return fmt.Errorf("save game class path is missing")
+		}
+		err := ue.WriteFTopLevelAssetPath(buf, *saveData.SaveGameClassPath)
+		if err != nil {
+			return fmt.Errorf("failed to write top level asset path: %w", err)
+		}
+	}
+
+	offsetsPos := buf.Len()
+	err := binary.Write(buf, binary.LittleEndian, OffsetInfo{})
+	if err != nil {
+		return err
+	}
+
+	// The objects table and the names table go after the objects data: the
+	// data may still add objects and names. They keep the order they were
+	// read in, new archives get the objects table first.
+	err = w.writeObjectsData()
+	if err != nil {
+		return fmt.Errorf("failed to write objects: %w", err)
+	}
+
+	var objectsOffset, namesOffset int
+	writeObjectsTable := func() error {
+		objectsOffset = buf.Len()
+		err := w.writeObjectsTable()
+		if err != nil {
+			return fmt.Errorf("failed to write objects table: %w", err)
+		}
+		return nil
+	}
+	writeNamesTable := func() error {
+		namesOffset = buf.Len()
+		err := w.writeNamesTable()
+		if err != nil {
+			return fmt.Errorf("failed to write names table: %w", err)
+		}
+		return nil
+	}
+	tables := []func() error{writeObjectsTable, writeNamesTable}
+	if saveData.NameTableOffset != 0 && saveData.NameTableOffset < saveData.ObjectsOffset {
+		tables = []func() error{writeNamesTable, writeObjectsTable}
+	}
+	for _, writeTable := range tables {
+		err = writeTable()
+		if err != nil {
+			return err
+		}
+	}
+
+	var offsets bytes.Buffer
+	err = binary.Write(&offsets, binary.LittleEndian, OffsetInfo{
+		Names:   uint64(namesOffset),
+		Version: saveData.Version,
+		Objects: uint64(objectsOffset),
+	})
+	if err != nil {
+		return err
+	}
+	copy(buf.Bytes()[offsetsPos:], offsets.Bytes())
+
+	return nil
+}
+
+func (w *archiveWriter) writeNamesTable() error {
+	err := memory.WriteInt(w.buf, int32(len(w.names.names)))
+	if err != nil {
+		return err
+	}
+
+	for _, name := range w.names.names {
+		err = ue.WriteFString(w.buf, name)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (w *archiveWriter) writeObjectsTable() error {
+	err := memory.WriteInt(w.buf, int32(len(w.saveData.Objects)))
+	if err != nil {
+		return err
+	}
+
+	for i, object := range w.saveData.Objects {
+		var wasLoaded uint8
+		if object.WasLoaded {
+			wasLoaded = 1
+		}
+		err = memory.WriteInt(w.buf, wasLoaded)
+		if err != nil {
+			return err
+		}
+
+		if !object.WasLoaded || i != 0 || w.saveData.SaveGameClassPath == nil {
+			err = ue.WriteFString(w.buf, object.ObjectPath)
+			if err != nil {
+				return err
+			}
+		}
+
+		if !object.WasLoaded {
+			loadedData := UObjectLoadedData{}
+			if object.LoadedData != nil {
+				loadedData = *object.LoadedData
+			}
+
+			err = w.writeName(loadedData.Name)
+			if err != nil {
+				return err
+			}
+			err = memory.WriteInt(w.buf, loadedData.OuterID)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (w *archiveWriter) writeObjectsData() error {
+	// Objects added by object references are written as well, they are
+	// appended to the end of the list.
+	for i := 0; i < len(w.saveData.Objects); i++ {
+		object := w.saveData.Objects[i]
+
+		err := memory.WriteInt(w.buf, uint32(i))
+		if err != nil {
+			return fmt.Errorf("failed to write object id: %w", err)
+		}
+
+		err = w.writeSized(func() error {
+			if object.PropertyList == nil {
+				return nil
+			}
+			return w.writeProperties(object.PropertyList, object.ExtraData)
+		})
+		if err != nil {
+			return fmt.Errorf("failed to write object data: %w", err)
+		}
+
+		var isActor uint8
+		if object.Components != nil {
+			isActor = 1
+		}
+		err = memory.WriteInt(w.buf, isActor)
+		if err != nil {
+			return fmt.Errorf("failed to write isActor: %w", err)
+		}
+		if object.Components != nil {
+			err = w.writeComponents(object.Components)
+			if err != nil {
+				return fmt.Errorf("failed to write components: %w", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// writeSized writes a uint32 length followed by whatever write produces.
+func (w *archiveWriter) writeSized(write func() error) error {
+	lengthPos := w.buf.Len()
+	err := memory.WriteInt[uint32](w.buf, 0)
+	if err != nil {
+		return err
+	}
+
+	err = write()
+	if err != nil {
+		return err
+	}
+
+	binary.LittleEndian.PutUint32(w.buf.Bytes()[lengthPos:], uint32(w.buf.Len()-lengthPos-4))
+	return nil
+}
+
+func (w *archiveWriter) writeComponents(components []Component) error {
+	err := memory.WriteInt(w.buf, uint32(len(components)))
+	if err != nil {
+		return err
+	}
+
+	for _, component := range components {
+		err = ue.WriteFString(w.buf, component.ComponentKey)
+		if err != nil {
+			return err
+		}
+
+		err = w.writeSized(func() error {
+			if IsVariablesComponent(component.ComponentKey) {
+				variables, ok := component.Properties[component.ComponentKey].(Variables)
+				if !ok {
+					return fmt.Errorf("component %s has no variables", component.ComponentKey)
+				}
+				err := w.writeVariables(variables)
+				if err != nil {
+					return err
+				}
+				_, err = w.buf.Write(component.ExtraData)
+				return err
+			}
+
+			return w.writeProperties(component.PropertyList, component.ExtraData)
+		})
+		if err != nil {
+			return fmt.Errorf("failed to write component %s: %w", component.ComponentKey, err)
+		}
+	}
+
+	return nil
+}
+
+func (w *archiveWriter) writeVariables(variables Variables) error {
+	err := w.writeName(variables.Name)
+	if err != nil {
+		return fmt.Errorf("failed to write variable name index: %w", err)
+	}
+
+	err = memory.WriteInt(w.buf, variables.Reserved)
+	if err != nil {
+		return fmt.Errorf("failed to write empty value: %w", err)
+	}
+
+	err = memory.WriteInt(w.buf, uint32(len(variables.PropertyList)))
+	if err != nil {
+		return fmt.Errorf("failed to write array length: %w", err)
+	}
+
+	for _, property := range variables.PropertyList {
+		err = w.writeVariable(property)
+		if err != nil {
+			return fmt.Errorf("failed to write variable %s: %w", property.Name, err)
+		}
+	}
+
+	return nil
+}
+
+func (w *archiveWriter) writeVariable(property Property) error {
+	err := w.writeName(property.Name)
+	if err != nil {
+		return err
+	}
+
+	varType := -1
+	for varTypeEnumValue, varTypeName := range VarTypeNames {
+		if varTypeName == property.Type {
+			varType = int(varTypeEnumValue)
+		}
+	}
+	if varType == -1 {
+		return fmt.Errorf("unknown variable type: %s", property.Type)
+	}
+	err = memory.WriteInt(w.buf, uint8(varType))
+	if err != nil {
+		return err
+	}
+
+	switch varType {
+	case VarTypeBool:
+		value, ok := property.Value.(bool)
+		if !ok {
+			return fmt.Errorf("expected bool, got %T", property.Value)
+		}
+		var intValue uint32
+		if value {
+			intValue = 1
+		}
+		return memory.WriteInt(w.buf, intValue)
+
+	case VarTypeInt:
+		value, ok := property.Value.(int32)
+		if !ok {
+			return fmt.Errorf("expected int32, got %T", property.Value)
+		}
+		return memory.WriteInt(w.buf, value)
+
+	case VarTypeFloat:
+		value, ok := property.Value.(float32)
+		if !ok {
+			return fmt.Errorf("expected float32, got %T", property.Value)
+		}
+		return binary.Write(w.buf, binary.LittleEndian, value)
+
+	case VarTypeName:
+		value, ok := property.Value.(string)
+		if !ok {
+			return fmt.Errorf("expected string, got %T", property.Value)
+		}
+		return w.writeName(value)
+	}
+
+	return nil
+}
@@ -0,0 +1,726 @@
+package remnant
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"refinder/memory"
+	"refinder/ue"
+	"slices"
+)
+
+func (w *archiveWriter) writeProperties(properties []Property, extraData []byte) error {
+	for _, property := range properties {
+		err := w.writeProperty(property)
+		if err != nil {
+			return fmt.Errorf("failed to write property %s: %w", property.Name, err)
+		}
+	}
+
+	err := w.writeName("None")
+	if err != nil {
+		return err
+	}
+
+	_, err = w.buf.Write(extraData)
+	return err
+}
+
+func (w *archiveWriter) writeProperty(property Property) error {
+	err := w.writeName(property.Name)
+	if err != nil {
+		return fmt.Errorf("failed to write variable name index: %w", err)
+	}
+
+	err = w.writeName(property.Type)
+	if err != nil {
+		return fmt.Errorf("failed to write variable type index: %w", err)
+	}
+
+	sizePos := w.buf.Len()
+	err = memory.WriteInt[uint32](w.buf, 0)
+	if err != nil {
+		return err
+	}
+
+	err = memory.WriteInt(w.buf, property.Index)
+	if err != nil {
+		return err
+	}
+
+	// Properties the reader skips keep their raw bytes, which include the
+	// 19 bytes of the struct header that are not part of the size.
+	if rawValue, ok := property.Value.([]byte); ok {
+		_, err = w.buf.Write(rawValue)
+		if err != nil {
+			return err
+		}
+		binary.LittleEndian.PutUint32(w.buf.Bytes()[sizePos:], uint32(len(rawValue)-19))
+		return nil
+	}
+
+	// The size only covers the value, not the type information in front of
+	// it.
+	valueStart := w.buf.Len()
+	tagSize, err := w.writePropertyValue(property.Type, property.Name, property.Value, &property.TagFlag)
+	if err != nil {
+		return fmt.Errorf("failed to write variable data (%s %s): %w", property.Name, property.Type, err)
+	}
+	binary.LittleEndian.PutUint32(w.buf.Bytes()[sizePos:], uint32(w.buf.Len()-valueStart-tagSize))
+
+	return nil
+}
+
+// writeTagFlag writes the byte that readTagFlag reads, nothing for raw
+// values.
+func (w *archiveWriter) writeTagFlag(tag *uint8) (int, error) {
+	if tag == nil {
+		return 0, nil
+	}
+	return 1, w.buf.WriteByte(*tag)
+}
+
+// tagFlag returns the tag byte to write for values that always have one.
+func tagFlag(tag *uint8) byte {
+	if tag == nil {
+		return 0
+	}
+	return *tag
+}
+
+func writeNumProperty[T Number](w *archiveWriter, value interface{}, tag *uint8) (int, error) {
+	number, ok := value.(T)
+	if !ok {
+		var expected T
+		return 0, fmt.Errorf("expected %T, got %T", expected, value)
+	}
+
+	tagSize, err := w.writeTagFlag(tag)
+	if err != nil {
+		return 0, err
+	}
+	return tagSize, binary.Write(w.buf, binary.LittleEndian, number)
+}
+
+func expectString(value interface{}) (string, error) {
+	stringValue, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("expected string, got %T", value)
+	}
+	return stringValue, nil
+}
+
+// writePropertyValue writes a value the way getPropertyValue reads it and
+// returns how many of the written bytes are type information rather than the
+// value itself.
+func (w *archiveWriter) writePropertyValue(varType string, name string, value interface{}, tag *uint8) (int, error) {
+	switch varType {
+	case "IntProperty":
+		return writeNumProperty[int32](w, value, tag)
+
+	case "Int16Property":
+		return writeNumProperty[int16](w, value, tag)
+
+	case "Int64Property":
+		return writeNumProperty[int64](w, value, tag)
+
+	case "UInt64Property":
+		return writeNumProperty[uint64](w, value, tag)
+
+	case "FloatProperty":
+		return writeNumProperty[float32](w, value, tag)
+
+	case "DoubleProperty":
+		return writeNumProperty[float64](w, value, tag)
+
+	case "UInt16Property":
+		return writeNumProperty[uint16](w, value, tag)
+
+	case "UInt32Property":
+		return writeNumProperty[uint32](w, value, tag)
+
+	case "SoftClassPath", "SoftObjectProperty", "StrProperty":
+		stringValue, err := expectString(value)
+		if err != nil {
+			return 0, err
+		}
+		tagSize, err := w.writeTagFlag(tag)
+		if err != nil {
+			return 0, err
+		}
+		return tagSize, ue.WriteFString(w.buf, stringValue)
+
+	case "BoolProperty":
+		boolValue, ok := value.(bool)
+		if !ok {
+			return 0, fmt.Errorf("expected bool, got %T", value)
+		}
+		var byteValue byte
+		if boolValue {
+			byteValue = 1
+		}
+		err := w.buf.WriteByte(byteValue)
+		if err != nil {
+			return 0, err
+		}
+		// The value of a bool is part of its type information, its size is 0.
+		tagSize, err := w.writeTagFlag(tag)
+		return tagSize + 1, err
+
+	case "MapProperty":
+		if tag == nil {
+			return 0, fmt.Errorf("raw map property is not supported")
+		}
+		mapProperty, ok := value.(MapProperty)
+		if !ok {
+			return 0, fmt.Errorf("expected MapProperty, got %T", value)
+		}
+		return w.writeMapProperty(mapProperty, tag)
+
+	case "EnumProperty":
+		enumProperty, ok := value.(EnumProperty)
+		if !ok {
+			return 0, fmt.Errorf("expected EnumProperty, got %T", value)
+		}
+		return w.writeEnumProperty(enumProperty, tag)
+
+	case "TextProperty":
+		textProperty, ok := value.(TextProperty)
+		if !ok {
+			return 0, fmt.Errorf("expected TextProperty, got %T", value)
+		}
+		tagSize, err := w.writeTagFlag(tag)
+		if err != nil {
+			return 0, err
+		}
+		return tagSize, w.writeTextProperty(textProperty)
+
+	case "NameProperty":
+		stringValue, err := expectString(value)
+		if err != nil {
+			return 0, err
+		}
+		tagSize, err := w.writeTagFlag(tag)
+		if err != nil {
+			return 0, err
+		}
+		return tagSize, w.writeName(stringValue)
+
+	case "ArrayProperty":
+		return w.writeArrayProperty(name, value, tag)
+
+	case "StructProperty":
+		if tag == nil {
+			structReference, ok := value.(StructReference)
+			if !ok {
+				return 0, fmt.Errorf("expected StructReference, got %T", value)
+			}
+			return 0, ue.WriteGuid(w.buf, structReference.GUID)
+		}
+		structProperty, ok := value.(StructProperty)
+		if !ok {
+			return 0, fmt.Errorf("expected StructProperty, got %T", value)
+		}
+		return w.writeStructProperty(structProperty, tag)
+
+	case "ObjectProperty":
+		objectProperty, ok := value.(ObjectProperty)
+		if !ok {
+			return 0, fmt.Errorf("expected ObjectProperty, got %T", value)
+		}
+		tagSize, err := w.writeTagFlag(tag)
+		if err != nil {
+			return 0, err
+		}
+		return tagSize, memory.WriteInt(w.buf, w.objectIndex(objectProperty))
+
+	case "ByteProperty":
+		return w.writeByteProperty(value, tag)
+
+	case "None":
+		return 0, nil
+
+	default:
+		return 0, fmt.Errorf("property type is not supported yet: %s", varType)
+	}
+}
+
+func (w *archiveWriter) writeByteProperty(value interface{}, tag *uint8) (int, error) {
+	if tag == nil {
+		byteValue, ok := value.(uint8)
+		if !ok {
+			return 0, fmt.Errorf("expected uint8, got %T", value)
+		}
+		return 0, w.buf.WriteByte(byteValue)
+	}
+
+	switch byteValue := value.(type) {
+	case uint8:
+		tagStart := w.buf.Len()
+		err := w.writeName("None")
+		if err != nil {
+			return 0, err
+		}
+		err = w.buf.WriteByte(*tag)
+		if err != nil {
+			return 0, err
+		}
+		tagSize := w.buf.Len() - tagStart
+		return tagSize, w.buf.WriteByte(byteValue)
+
+	case EnumProperty:
+		return w.writeEnumProperty(byteValue, tag)
+
+	default:
+		return 0, fmt.Errorf("expected uint8 or EnumProperty, got %T", value)
+	}
+}
+
+func (w *archiveWriter) writeEnumProperty(enumProperty EnumProperty, tag *uint8) (int, error) {
+	tagStart := w.buf.Len()
+	err := w.writeName(enumProperty.EnumType)
+	if err != nil {
+		return 0, fmt.Errorf("writeEnumProperty: %w", err)
+	}
+
+	err = w.buf.WriteByte(tagFlag(tag))
+	if err != nil {
+		return 0, fmt.Errorf("writeEnumProperty: %w", err)
+	}
+	tagSize := w.buf.Len() - tagStart
+
+	err = w.writeName(enumProperty.EnumValue)
+	if err != nil {
+		return 0, fmt.Errorf("writeEnumProperty: %w", err)
+	}
+
+	return tagSize, nil
+}
+
+func (w *archiveWriter) writeTextProperty(textProperty TextProperty) error {
+	err := memory.WriteInt(w.buf, textProperty.Flags)
+	if err != nil {
+		return err
+	}
+
+	err = memory.WriteInt(w.buf, textProperty.HistoryType)
+	if err != nil {
+		return err
+	}
+
+	switch textProperty.HistoryType {
+	case 0:
+		textData, ok := textProperty.Data.(TextPropertyData)
+		if !ok {
+			return fmt.Errorf("expected TextPropertyData, got %T", textProperty.Data)
+		}
+		for _, value := range []string{textData.Namespace, textData.Key, textData.SourceString} {
+			err = ue.WriteFString(w.buf, value)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case 255:
+		textData, ok := textProperty.Data.(TextData)
+		if !ok {
+			return memory.WriteInt[uint32](w.buf, 0)
+		}
+		err = memory.WriteInt[uint32](w.buf, 1)
+		if err != nil {
+			return err
+		}
+		return ue.WriteFString(w.buf, textData.Data)
+
+	default:
+		return fmt.Errorf("text history type %d is not supported", textProperty.HistoryType)
+	}
+}
+
+func (w *archiveWriter) writeMapProperty(mapProperty MapProperty, tag *uint8) (int, error) {
+	tagStart := w.buf.Len()
+
+	err := w.writeName(mapProperty.KeyType)
+	if err != nil {
+		return 0, fmt.Errorf("writeMapProperty: %w", err)
+	}
+
+	err = w.writeName(mapProperty.ValueType)
+	if err != nil {
+		return 0, fmt.Errorf("writeMapProperty: %w", err)
+	}
+
+	err = w.buf.WriteByte(*tag)
+	if err != nil {
+		return 0, fmt.Errorf("writeMapProperty: %w", err)
+	}
+	tagSize := w.buf.Len() - tagStart
+
+	err = memory.WriteInt(w.buf, mapProperty.KeysToRemove)
+	if err != nil {
+		return 0, fmt.Errorf("writeMapProperty: %w", err)
+	}
+
+	err = memory.WriteInt(w.buf, int32(len(mapProperty.Values)))
+	if err != nil {
+		return 0, fmt.Errorf("writeMapProperty: %w", err)
+	}
+
+	for _, value := range mapProperty.Values {
+		_, err = w.writePropertyValue(mapProperty.KeyType, "", value.Key, nil)
+		if err != nil {
+			return 0, fmt.Errorf("writeMapProperty: %w", err)
+		}
+		_, err = w.writePropertyValue(mapProperty.ValueType, "", value.Value, nil)
+		if err != nil {
+			return 0, fmt.Errorf("writeMapProperty: %w", err)
+		}
+	}
+
+	return tagSize, nil
+}
+
+func (w *archiveWriter) writeArrayProperty(name string, value interface{}, tag *uint8) (int, error) {
+	tagStart := w.buf.Len()
+
+	switch arrayProperty := value.(type) {
+	case ArrayStructProperty:
+		err := w.writeName("StructProperty")
+		if err != nil {
+			return 0, err
+		}
+		err = w.buf.WriteByte(tagFlag(tag))
+		if err != nil {
+			return 0, err
+		}
+		tagSize := w.buf.Len() - tagStart
+
+		err = memory.WriteInt(w.buf, uint32(len(arrayProperty.Items)))
+		if err != nil {
+			return 0, err
+		}
+
+		return tagSize, w.writeArrayStructProperty(name, arrayProperty)
+
+	case ArrayProperty:
+		err := w.writeName(arrayProperty.ElementType)
+		if err != nil {
+			return 0, err
+		}
+		err = w.buf.WriteByte(tagFlag(tag))
+		if err != nil {
+			return 0, err
+		}
+		tagSize := w.buf.Len() - tagStart
+
+		err = memory.WriteInt(w.buf, uint32(len(arrayProperty.Items)))
+		if err != nil {
+			return 0, err
+		}
+
+		for _, item := range arrayProperty.Items {
+			_, err = w.writePropertyValue(arrayProperty.ElementType, name, item, nil)
+			if err != nil {
+				return 0, err
+			}
+		}
+
+		return tagSize, nil
+
+	default:
+		return 0, fmt.Errorf("expected ArrayProperty or ArrayStructProperty, got %T", value)
+	}
+}
+
+// writeArrayStructProperty writes the inner property header that
+// readArrayStructHeader reads, followed by the elements.
+func (w *archiveWriter) writeArrayStructProperty(name string, arrayProperty ArrayStructProperty) error {
+	tagName, tagType := arrayProperty.TagName, arrayProperty.TagType
+	if tagName == "" {
+		tagName = name
+	}
+	if tagType == "" {
+		tagType = "StructProperty"
+	}
+
+	err := w.writeName(tagName)
+	if err != nil {
+		return err
+	}
+
+	err = w.writeName(tagType)
+	if err != nil {
+		return err
+	}
+
+	sizePos := w.buf.Len()
+	err = memory.WriteInt[uint32](w.buf, 0)
+	if err != nil {
+		return err
+	}
+
+	err = memory.WriteInt(w.buf, arrayProperty.TagIndex)
+	if err != nil {
+		return err
+	}
+
+	err = w.writeName(arrayProperty.ElementType)
+	if err != nil {
+		return err
+	}
+
+	err = ue.WriteGuid(w.buf, arrayProperty.GUID)
+	if err != nil {
+		return err
+	}
+
+	err = w.buf.WriteByte(arrayProperty.TagFlag)
+	if err != nil {
+		return err
+	}
+
+	itemsStart := w.buf.Len()
+	for _, item := range arrayProperty.Items {
+		err = w.writeStructPropertyData(item)
+		if err != nil {
+			return err
+		}
+	}
+	binary.LittleEndian.PutUint32(w.buf.Bytes()[sizePos:], uint32(w.buf.Len()-itemsStart))
+
+	return nil
+}
+
+func (w *archiveWriter) writeStructProperty(structProperty StructProperty, tag *uint8) (int, error) {
+	tagStart := w.buf.Len()
+
+	err := w.writeName(structProperty.Name)
+	if err != nil {
+		return 0, err
+	}
+
+	err = ue.WriteGuid(w.buf, structProperty.GUID)
+	if err != nil {
+		return 0, err
+	}
+
+	err = w.buf.WriteByte(*tag)
+	if err != nil {
+		return 0, err
+	}
+	tagSize := w.buf.Len() - tagStart
+
+	return tagSize, w.writeStructPropertyData(structProperty)
+}
+
+func (w *archiveWriter) writeStructPropertyData(structProperty StructProperty) error {
+	switch structProperty.Name {
+	case "SoftClassPath", "SoftObjectPath":
+		stringValue, err := expectString(structProperty.Value)
+		if err != nil {
+			return err
+		}
+		return ue.WriteFString(w.buf, stringValue)
+
+	case "Timespan", "DateTime":
+		_, err := writeNumProperty[int64](w, structProperty.Value, nil)
+		return err
+
+	case "Guid":
+		guid, ok := structProperty.Value.(ue.FGuid)
+		if !ok {
+			return fmt.Errorf("expected FGuid, got %T", structProperty.Value)
+		}
+		return ue.WriteGuid(w.buf, guid)
+
+	case "Vector":
+		vector, ok := structProperty.Value.(ue.FVector)
+		if !ok {
+			return fmt.Errorf("expected FVector, got %T", structProperty.Value)
+		}
+		return ue.WriteFVector(w.buf, vector)
+
+	case "PersistenceBlob":
+		var blob bytes.Buffer
+		switch persistence := structProperty.Value.(type) {
+		case PersistenceBlob:
+			err := writeSaveData(&blob, persistence.Archive, true, false)
+			if err != nil {
+				return err
+			}
+		case PersistenceContainer:
+			err := writePersistenceContainer(&blob, persistence)
+			if err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("expected PersistenceBlob or PersistenceContainer, got %T", structProperty.Value)
+		}
+
+		err := memory.WriteInt(w.buf, uint32(blob.Len()))
+		if err != nil {
+			return err
+		}
+		_, err = w.buf.Write(blob.Bytes())
+		return err
+
+	default:
+		return w.writeProperties(structProperty.PropertyList, nil)
+	}
+}
+
+func writePersistenceContainer(buf *bytes.Buffer, container PersistenceContainer) error {
+	err := memory.WriteInt(buf, container.Version)
+	if err != nil {
+		return err
+	}
+
+	// index and dynamic offsets
+	offsetsPos := buf.Len()
+	err = binary.Write(buf, binary.LittleEndian, [2]uint32{})
+	if err != nil {
+		return err
+	}
+
+	uniqueIDs := actorOrder(container.Actors, container.ActorOrder)
+
+	actorInfo := []ue.FInfo{}
+	for _, uniqueID := range uniqueIDs {
+		actor := container.Actors[uniqueID]
+		// Dynamic actors that are not in the index have no archive.
+		if actor.Archive.NamesTable == nil {
+			continue
+		}
+
+		offset := buf.Len()
+		err = writeActor(buf, actor)
+		if err != nil {
+			return fmt.Errorf("writeActor %d: %w", uniqueID, err)
+		}
+		actorInfo = append(actorInfo, ue.FInfo{
+			UniqueID: uniqueID,
+			Offset:   uint32(offset),
+			Size:     uint32(buf.Len() - offset),
+		})
+	}
+
+	indexOffset := buf.Len()
+	err = memory.WriteInt(buf, uint32(len(actorInfo)))
+	if err != nil {
+		return err
+	}
+	for _, info := range actorInfo {
+		err = ue.WriteFInfo(buf, info)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = memory.WriteInt(buf, uint32(len(container.Destroyed)))
+	if err != nil {
+		return err
+	}
+	for _, destroyed := range container.Destroyed {
+		err = memory.WriteInt(buf, destroyed)
+		if err != nil {
+			return err
+		}
+	}
+
+	dynamicOffset := buf.Len()
+	dynamicActors := []*DynamicActor{}
+	for _, uniqueID := range actorOrder(container.Actors, container.DynamicOrder) {
+		if dynamicActor := container.Actors[uniqueID].DynamicData; dynamicActor != nil {
+			dynamicActors = append(dynamicActors, dynamicActor)
+		}
+	}
+	err = memory.WriteInt(buf, uint32(len(dynamicActors)))
+	if err != nil {
+		return err
+	}
+	for _, dynamicActor := range dynamicActors {
+		err = writeDynamicActor(buf, *dynamicActor)
+		if err != nil {
+			return err
+		}
+	}
+
+	binary.LittleEndian.PutUint32(buf.Bytes()[offsetsPos:], uint32(indexOffset))
+	binary.LittleEndian.PutUint32(buf.Bytes()[offsetsPos+4:], uint32(dynamicOffset))
+
+	return nil
+}
+
+// actorOrder returns the UniqueIDs of the actors in the given order, followed
+// by the actors that are not in it sorted by UniqueID.
+func actorOrder(actors map[uint64]Actor, order []uint64) []uint64 {
+	uniqueIDs := make([]uint64, 0, len(actors))
+	seen := make(map[uint64]bool, len(actors))
+	for _, uniqueID := range order {
+		if _, ok := actors[uniqueID]; ok && !seen[uniqueID] {
+			uniqueIDs = append(uniqueIDs, uniqueID)
+			seen[uniqueID] = true
+		}
+	}
+	rest := []uint64{}
+	for uniqueID := range actors {
+		if !seen[uniqueID] {
+			rest = append(rest, uniqueID)
+		}
+	}
+	slices.Sort(rest)
+	return append(uniqueIDs, rest...)
+}
+
+// writeActor writes an actor into its own buffer: the offsets inside the
+// actor archive are relative to the start of the actor.
+func writeActor(buf *bytes.Buffer, actor Actor) error {
+	var actorBuf bytes.Buffer
+
+	var hasTransform uint32
+	if actor.Transform != nil {
+		hasTransform = 1
+	}
+	err := memory.WriteInt(&actorBuf, hasTransform)
+	if err != nil {
+		return err
+	}
+	if actor.Transform != nil {
+		err = ue.WriteFTransform(&actorBuf, *actor.Transform)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = writeSaveData(&actorBuf, actor.Archive, false, false)
+	if err != nil {
+		return err
+	}
+
+	_, err = buf.Write(actorBuf.Bytes())
+	return err
+}
+
+func writeDynamicActor(buf *bytes.Buffer, dynamicActor DynamicActor) error {
+	err := memory.WriteInt(buf, dynamicActor.UniqueID)
+	if err != nil {
+		return fmt.Errorf("writeDynamicActor: %w", err)
+	}
+
+	transform := ue.FTransform{}
+	if dynamicActor.Transform != nil {
+		transform = *dynamicActor.Transform
+	}
+	err = ue.WriteFTransform(buf, transform)
+	if err != nil {
+		return fmt.Errorf("writeDynamicActor: %w", err)
+	}
+
+	err = ue.WriteFTopLevelAssetPath(buf, dynamicActor.ClassPath)
+	if err != nil {
+		return fmt.Errorf("writeDynamicActor: %w", err)
+	}
+
+	return nil
+}
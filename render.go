@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"refinder/catalog"
+	"refinder/remnant"
+	"sort"
+	"strings"
+)
+
+// Renderer writes a character's world in a specific output format.
+type Renderer interface {
+	Render(w io.Writer, characterData CharacterData, zoneInfo ZoneInfo) error
+}
+
+// multiRenderer is a Renderer that writes several characters as one
+// document, so --all gives one JSON array or one CSV table instead of one
+// document per character.
+type multiRenderer interface {
+	RenderAll(w io.Writer, documents []worldDocument) error
+}
+
+// worldDocument is the top-level document produced by the structured
+// renderers (JSON, YAML).
+type worldDocument struct {
+	Character CharacterData `json:"character"`
+	World     ZoneInfo      `json:"world"`
+}
+
+var renderers = map[string]func() Renderer{
+	"text":     func() Renderer { return NewTextRenderer() },
+	"json":     func() Renderer { return JSONRenderer{} },
+	"yaml":     func() Renderer { return YAMLRenderer{} },
+	"markdown": func() Renderer { return MarkdownRenderer{} },
+	"csv":      func() Renderer { return CSVRenderer{} },
+}
+
+func rendererFormats() []string {
+	formats := make([]string, 0, len(renderers))
+	for format := range renderers {
+		formats = append(formats, format)
+	}
+	sort.Strings(formats)
+	return formats
+}
+
+func newRenderer(format string) (Renderer, error) {
+	newFunc, ok := renderers[strings.ToLower(format)]
+	if !ok {
+		return nil, fmt.Errorf("unknown output format %q (supported: %s)", format, strings.Join(rendererFormats(), ", "))
+	}
+	return newFunc(), nil
+}
+
+func getCharacterTypeName(characterType string) string {
+	return strings.TrimPrefix(characterType, "ERemnantCharacterType::")
+}
+
+// isMaterial reports whether an item is a material or a currency, like
+// Scrap. They are picked up in stacks and never owned the way gear is.
+func isMaterial(name string) bool {
+	category := itemCatalog.Lookup(name).Category
+	return category == catalog.Material || category == catalog.Currency
+}
+
+// getBiomeName returns the in-game name of a biome, unknown biomes (like the
+// campaign quest) keep their class name.
+func getBiomeName(biome string) string {
+	if name, ok := remnant.BiomeNames[biome]; ok {
+		return language.Biome(biome, name)
+	}
+	return language.Biome(biome, biome)
+}
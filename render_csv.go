@@ -0,0 +1,100 @@
+package main
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+)
+
+// CSVRenderer writes one row per waypoint, item, event and reward. Rows keep
+// both the blueprint name and the display name so spreadsheets can match on
+// either.
+type CSVRenderer struct{}
+
+var csvHeader = []string{
+	"character_id", "biome", "blood_moon", "zone_id", "zone", "kind", "event", "name", "display_name", "quantity", "owned", "event_kind", "status",
+}
+
+func (renderer CSVRenderer) Render(w io.Writer, characterData CharacterData, zoneInfo ZoneInfo) error {
+	return renderer.RenderAll(w, []worldDocument{{Character: characterData, World: zoneInfo}})
+}
+
+// RenderAll writes the worlds as one table with a single header, the
+// character_id column tells the characters apart.
+func (CSVRenderer) RenderAll(w io.Writer, documents []worldDocument) error {
+	writer := csv.NewWriter(w)
+
+	err := writer.Write(csvHeader)
+	if err != nil {
+		return err
+	}
+
+	for _, document := range documents {
+		if document.World.ZoneActor == nil {
+			continue
+		}
+		err = writeCSVZone(writer, []string{
+			strconv.Itoa(int(document.Character.ID)),
+			document.World.Biome,
+			strconv.FormatBool(document.World.BloodMoon),
+		}, document.World.ZoneActor)
+		if err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+func writeCSVZone(writer *csv.Writer, prefix []string, zone *ZoneActor) error {
+	// eventKind and status are set for events and their rewards.
+	var eventKind EventKind
+	var status EventStatus
+	write := func(kind, event, name, displayName, quantity, owned string) error {
+		row := append([]string{}, prefix...)
+		row = append(row, strconv.Itoa(int(zone.ID)), zone.Label, kind, event, name, displayName, quantity, owned, string(eventKind), string(status))
+		return writer.Write(row)
+	}
+
+	for _, link := range zone.ZoneLinks {
+		if link.Type == "EZoneLinkType::Waypoint" {
+			if err := write("waypoint", "", link.NameID, link.Label, "", ""); err != nil {
+				return err
+			}
+		}
+	}
+	for _, item := range zone.Items {
+		quantity := strconv.Itoa(int(item.Quantity))
+		var err error
+		if isMaterial(item.Name) {
+			err = write("material", "", item.Name, getPrintableName(item.Name), quantity, "")
+		} else {
+			err = write("item", "", item.Name, getPrintableName(item.Name), quantity, strconv.FormatBool(item.OwnedByCharacter))
+		}
+		if err != nil {
+			return err
+		}
+	}
+	for _, event := range zone.Events {
+		eventKind = event.Kind
+		status = event.Status
+		if err := write("event", "", event.Name, getPrintableName(event.Name), "", ""); err != nil {
+			return err
+		}
+		for _, reward := range event.Rewards {
+			err := write("reward", event.Name, reward.ActorBP, getPrintableName(reward.ActorBP), strconv.Itoa(int(reward.Quantity)), strconv.FormatBool(reward.OwnedByCharacter))
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, child := range zone.Children {
+		if err := writeCSVZone(writer, prefix, child); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// JSONRenderer writes the world as a single indented JSON document.
+type JSONRenderer struct{}
+
+func (JSONRenderer) Render(w io.Writer, characterData CharacterData, zoneInfo ZoneInfo) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+
+	return encoder.Encode(worldDocument{
+		Character: characterData,
+		World:     zoneInfo,
+	})
+}
+
+// RenderAll writes the worlds as one JSON array.
+func (JSONRenderer) RenderAll(w io.Writer, documents []worldDocument) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+
+	if documents == nil {
+		documents = []worldDocument{}
+	}
+	return encoder.Encode(documents)
+}
+
+// MarshalJSON keeps empty lists as [] instead of null so consumers can
+// always iterate them.
+func (zone ZoneActor) MarshalJSON() ([]byte, error) {
+	type jsonZoneActor ZoneActor
+	if zone.ZoneLinks == nil {
+		zone.ZoneLinks = []ZoneLinkInfo{}
+	}
+	if zone.Events == nil {
+		zone.Events = []Event{}
+	}
+	if zone.Items == nil {
+		zone.Items = []ItemData{}
+	}
+	if zone.Children == nil {
+		zone.Children = []*ZoneActor{}
+	}
+	return json.Marshal(jsonZoneActor(zone))
+}
+
+func (event Event) MarshalJSON() ([]byte, error) {
+	type jsonEvent Event
+	if event.Rewards == nil {
+		event.Rewards = []LootSpawn{}
+	}
+	return json.Marshal(jsonEvent(event))
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// MarkdownRenderer writes the world as a Markdown checklist, one heading per
+// zone, with a checkbox for every item and reward.
+type MarkdownRenderer struct{}
+
+func markdownCheckbox(owned bool) string {
+	if owned {
+		return "[x]"
+	}
+	return "[ ]"
+}
+
+func (MarkdownRenderer) Render(w io.Writer, characterData CharacterData, zoneInfo ZoneInfo) error {
+	var buf bytes.Buffer
+
+	fmt.Fprintf(&buf, "# %s\n\n", getBiomeName(zoneInfo.Biome))
+	fmt.Fprintf(&buf, "- **Archetype:** %s\n", characterData.Archetype)
+	fmt.Fprintf(&buf, "- **Character:** %s\n", getCharacterTypeName(characterData.Type))
+	if zoneInfo.Biome == "Jungle" {
+		fmt.Fprintf(&buf, "- **Blood Moon:** %v\n", zoneInfo.BloodMoon)
+	}
+
+	if zoneInfo.ZoneActor != nil {
+		renderMarkdownZone(&buf, zoneInfo.ZoneActor, 2)
+	}
+
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+func renderMarkdownZone(buf *bytes.Buffer, zone *ZoneActor, level int) {
+	// Markdown only has six heading levels, deeper zones reuse the last one.
+	fmt.Fprintf(buf, "\n%s %s\n\n", strings.Repeat("#", min(level, 6)), zone.Label)
+
+	for _, link := range zone.ZoneLinks {
+		if link.Type == "EZoneLinkType::Waypoint" {
+			fmt.Fprintf(buf, "- Waypoint: %s\n", link.Label)
+		}
+	}
+	for _, item := range zone.Items {
+		if isMaterial(item.Name) {
+			fmt.Fprintf(buf, "- Material: %s x%d\n", getPrintableName(item.Name), item.Quantity)
+		} else {
+			fmt.Fprintf(buf, "- %s %s x%d\n", markdownCheckbox(item.OwnedByCharacter), getPrintableName(item.Name), item.Quantity)
+		}
+	}
+	for _, event := range zone.Events {
+		fmt.Fprintf(buf, "- %s: **%s** (%s)\n", event.Kind.Label(), getItemName(event.Name), event.Status)
+		for _, reward := range event.Rewards {
+			fmt.Fprintf(buf, "  - %s %s x%d\n", markdownCheckbox(reward.OwnedByCharacter), getPrintableName(reward.ActorBP), reward.Quantity)
+		}
+	}
+
+	for _, child := range zone.Children {
+		renderMarkdownZone(buf, child, level+1)
+	}
+}
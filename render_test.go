@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"testing"
+)
+
+func TestRenderAll(t *testing.T) {
+	documents := []worldDocument{
+		{Character: CharacterData{ID: 1}, World: ZoneInfo{Biome: "Fae", ZoneActor: &ZoneActor{
+			Label: "Root",
+			Items: []ItemData{{Name: "Weapon_Nightfall_C", Quantity: 1}},
+		}}},
+		{Character: CharacterData{ID: 2}, World: ZoneInfo{Biome: "Jungle", ZoneActor: &ZoneActor{
+			Label: "Root",
+			Items: []ItemData{{Name: "Weapon_Dreamcatcher_C", Quantity: 1}},
+		}}},
+	}
+
+	tests := []struct {
+		name      string
+		documents []worldDocument
+	}{
+		{"none", nil},
+		{"one", documents[:1]},
+		{"two", documents},
+	}
+	for _, test := range tests {
+		t.Run("json "+test.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := JSONRenderer{}.RenderAll(&buf, test.documents)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var decoded []worldDocument
+			err = json.Unmarshal(buf.Bytes(), &decoded)
+			if err != nil {
+				t.Fatalf("not one JSON array: %v\n%s", err, buf.String())
+			}
+			if decoded == nil || len(decoded) != len(test.documents) {
+				t.Fatalf("decoded %d documents, want %d", len(decoded), len(test.documents))
+			}
+			for i, document := range decoded {
+				if document.Character.ID != test.documents[i].Character.ID {
+					t.Errorf("document %d has character %d, want %d", i, document.Character.ID, test.documents[i].Character.ID)
+				}
+			}
+		})
+
+		t.Run("csv "+test.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := CSVRenderer{}.RenderAll(&buf, test.documents)
+			if err != nil {
+				t.Fatal(err)
+			}
+			records, err := csv.NewReader(&buf).ReadAll()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(records) != len(test.documents)+1 {
+				t.Fatalf("got %d records, want a header and %d rows", len(records), len(test.documents))
+			}
+			if records[0][0] != "character_id" {
+				t.Errorf("header = %v", records[0])
+			}
+			for i, record := range records[1:] {
+				if record[0] == "character_id" {
+					t.Errorf("row %d repeats the header", i)
+				}
+				want := []string{"1", "2"}[i]
+				if record[0] != want {
+					t.Errorf("row %d has character %s, want %s", i, record[0], want)
+				}
+			}
+		})
+	}
+}
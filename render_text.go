@@ -0,0 +1,118 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+)
+
+// TextRenderer prints the zone tree for a terminal.
+type TextRenderer struct {
+	// Indent is repeated once per tree level.
+	Indent string
+	// Owned and Missing mark whether the character already has an item.
+	Owned   string
+	Missing string
+	// Changes highlights what changed since the previous save, entries are
+	// wrapped in the Added or Modified escape sequence and Reset.
+	Changes  *WorldChanges
+	Added    string
+	Modified string
+	Reset    string
+	// Banner is printed above everything else, like wishlist matches.
+	Banner []string
+}
+
+func NewTextRenderer() *TextRenderer {
+	return &TextRenderer{
+		Indent:   "---",
+		Owned:    "✅",
+		Missing:  "❌",
+		Added:    "\033[32m",
+		Modified: "\033[33m",
+		Reset:    "\033[0m",
+	}
+}
+
+func (t *TextRenderer) ownedMark(owned bool) string {
+	if owned {
+		return t.Owned
+	}
+	return t.Missing
+}
+
+// highlight writes a line, colored when its entry changed since the
+// previous save.
+func (t *TextRenderer) highlight(buf *bytes.Buffer, key string, line string) {
+	switch t.Changes.Mark(key) {
+	case ChangeAdded:
+		fmt.Fprintf(buf, "%s%s%s\n", t.Added, line, t.Reset)
+	case ChangeModified:
+		fmt.Fprintf(buf, "%s%s%s\n", t.Modified, line, t.Reset)
+	default:
+		fmt.Fprintln(buf, line)
+	}
+}
+
+func (t *TextRenderer) Render(w io.Writer, characterData CharacterData, zoneInfo ZoneInfo) error {
+	var buf bytes.Buffer
+
+	if len(t.Banner) > 0 {
+		for _, line := range t.Banner {
+			fmt.Fprintf(&buf, "%s%s%s\n", t.Added, line, t.Reset)
+		}
+		fmt.Fprintln(&buf)
+	}
+
+	if t.Changes != nil && len(t.Changes.Summary) > 0 {
+		for _, line := range t.Changes.Summary {
+			fmt.Fprintln(&buf, line)
+		}
+		fmt.Fprintln(&buf)
+	}
+
+	fmt.Fprintf(&buf, "%-11s %s\n", "Archetype:", characterData.Archetype)
+	fmt.Fprintf(&buf, "%-11s %s\n", "Character:", getCharacterTypeName(characterData.Type))
+	fmt.Fprintf(&buf, "%-11s %s\n", "Biome:", getBiomeName(zoneInfo.Biome))
+
+	if zoneInfo.Biome == "Jungle" {
+		fmt.Fprintf(&buf, "%-11s %v\n\n", "Blood Moon:", zoneInfo.BloodMoon)
+	}
+
+	if zoneInfo.ZoneActor != nil {
+		t.renderTree(&buf, zoneInfo.ZoneActor, "")
+	}
+
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+func (t *TextRenderer) renderTree(buf *bytes.Buffer, zone *ZoneActor, indent string) {
+	if indent == "" {
+		t.highlight(buf, zoneChangeKey(zone), zone.Label)
+	} else {
+		t.highlight(buf, zoneChangeKey(zone), fmt.Sprintf("%s %s", indent, zone.Label))
+	}
+	for _, link := range zone.ZoneLinks {
+		if link.Type == "EZoneLinkType::Waypoint" {
+			fmt.Fprintf(buf, "%s%s || [Waypoint] %s\n", indent, t.Indent, link.Label)
+		}
+	}
+	for _, item := range zone.Items {
+		if isMaterial(item.Name) {
+			t.highlight(buf, itemChangeKey(zone, item), fmt.Sprintf("%s%s || [Material] x%d %s", indent, t.Indent, item.Quantity, getPrintableName(item.Name)))
+		} else {
+			t.highlight(buf, itemChangeKey(zone, item), fmt.Sprintf("%s%s || [Item] %s x%d %s", indent, t.Indent, t.ownedMark(item.OwnedByCharacter), item.Quantity, getPrintableName(item.Name)))
+		}
+	}
+	for _, event := range zone.Events {
+		t.highlight(buf, eventChangeKey(zone, event), fmt.Sprintf("%s%s || [%s] %s (%s)", indent, t.Indent, event.Kind.Label(), getItemName(event.Name), event.Status))
+		for _, reward := range event.Rewards {
+			t.highlight(buf, rewardChangeKey(zone, event, reward), fmt.Sprintf("%s%s%s || [Reward] %s x%d %s", indent, t.Indent, t.Indent, t.ownedMark(reward.OwnedByCharacter), reward.Quantity, getPrintableName(reward.ActorBP)))
+		}
+	}
+
+	for _, child := range zone.Children {
+		t.renderTree(buf, child, indent+t.Indent)
+	}
+}
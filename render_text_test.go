@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bytes"
+	"refinder/locale"
+	"strings"
+	"testing"
+)
+
+func TestTextRendererSettings(t *testing.T) {
+	zoneInfo := ZoneInfo{Biome: "Fae", ZoneActor: &ZoneActor{Label: "Root", Children: []*ZoneActor{{
+		Label: "Child",
+		Items: []ItemData{
+			{Name: "Weapon_Nightfall_C", Quantity: 1, OwnedByCharacter: true},
+			{Name: "Weapon_Dreamcatcher_C", Quantity: 1},
+		},
+	}}}}
+
+	tests := []struct {
+		name    string
+		indent  string
+		owned   string
+		missing string
+		lines   []string
+	}{
+		{"defaults", "---", "✅", "❌", []string{
+			"--- Child",
+			"------ || [Item] ✅ x1 " + getPrintableName("Weapon_Nightfall_C"),
+			"------ || [Item] ❌ x1 " + getPrintableName("Weapon_Dreamcatcher_C"),
+		}},
+		{"plain", "  ", "[x]", "[ ]", []string{
+			"   Child",
+			"     || [Item] [x] x1 " + getPrintableName("Weapon_Nightfall_C"),
+			"     || [Item] [ ] x1 " + getPrintableName("Weapon_Dreamcatcher_C"),
+		}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			renderer := NewTextRenderer()
+			renderer.Indent = test.indent
+			renderer.Owned = test.owned
+			renderer.Missing = test.missing
+
+			var buf bytes.Buffer
+			err := renderer.Render(&buf, CharacterData{}, zoneInfo)
+			if err != nil {
+				t.Fatal(err)
+			}
+			lines := strings.Split(buf.String(), "\n")
+			for _, want := range test.lines {
+				found := false
+				for _, line := range lines {
+					found = found || line == want
+				}
+				if !found {
+					t.Errorf("line %q missing from\n%s", want, buf.String())
+				}
+			}
+		})
+	}
+}
+
+func TestTextRendererEventKind(t *testing.T) {
+	defer func(table *locale.Table) { language = table }(language)
+	zoneInfo := ZoneInfo{Biome: "Nerud", ZoneActor: &ZoneActor{Label: "Root", Events: []Event{
+		{Name: "Quest_Boss_Corruptor_C", Kind: EventWorldBoss, Status: EventCompleted},
+		{Name: "Quest_SideD_Vault_C", Kind: EventDungeon, Status: EventNotStarted},
+	}}}
+
+	tests := []struct {
+		lang  string
+		lines []string
+	}{
+		{"en", []string{"--- || [World Boss] Corruptor (completed)", "--- || [Dungeon] " + getItemName("Quest_SideD_Vault_C") + " (not started)"}},
+		{"de", []string{"--- || [Weltboss] Verderber (completed)", "--- || [Dungeon] " + getItemName("Quest_SideD_Vault_C") + " (not started)"}},
+		{"ru", []string{"--- || [Босс мира] Осквернитель (completed)", "--- || [Подземелье] " + getItemName("Quest_SideD_Vault_C") + " (not started)"}},
+	}
+	for _, test := range tests {
+		t.Run(test.lang, func(t *testing.T) {
+			err := setLanguage(test.lang)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var buf bytes.Buffer
+			err = NewTextRenderer().Render(&buf, CharacterData{}, zoneInfo)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, want := range test.lines {
+				if !strings.Contains(buf.String(), want+"\n") {
+					t.Errorf("line %q missing from\n%s", want, buf.String())
+				}
+			}
+			// The kind is shown once, not again as the {Tag} of the name.
+			if strings.Contains(buf.String(), "{") {
+				t.Errorf("event shown with its category:\n%s", buf.String())
+			}
+		})
+	}
+}
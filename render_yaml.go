@@ -0,0 +1,198 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// YAMLRenderer writes the same document as JSONRenderer in block-style YAML.
+// The document is marshaled to JSON first and converted, so MarshalJSON
+// methods, omitempty and embedded structs give both formats one schema.
+type YAMLRenderer struct{}
+
+func (YAMLRenderer) Render(w io.Writer, characterData CharacterData, zoneInfo ZoneInfo) error {
+	var buf bytes.Buffer
+	buf.WriteString("---\n")
+	err := writeYAML(&buf, worldDocument{
+		Character: characterData,
+		World:     zoneInfo,
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = buf.WriteTo(w)
+	return err
+}
+
+// yamlEntry is a member of a JSON object, objects keep the order of their
+// members.
+type yamlEntry struct {
+	Key   string
+	Value interface{}
+}
+
+// writeYAML writes v as YAML through its JSON encoding.
+func writeYAML(buf *bytes.Buffer, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	value, err := decodeYAMLValue(decoder)
+	if err != nil {
+		return err
+	}
+	// Empty lists and objects are scalars, a block would write nothing.
+	if scalar, ok, err := yamlScalar(value); ok || err != nil {
+		fmt.Fprintf(buf, "%s\n", scalar)
+		return err
+	}
+	return writeYAMLValue(buf, value, 0)
+}
+
+// decodeYAMLValue reads the next JSON value as a scalar, a []interface{} for
+// arrays or a []yamlEntry for objects.
+func decodeYAMLValue(decoder *json.Decoder) (interface{}, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch token {
+	case json.Delim('['):
+		items := []interface{}{}
+		for decoder.More() {
+			item, err := decodeYAMLValue(decoder)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+		_, err = decoder.Token()
+		return items, err
+
+	case json.Delim('{'):
+		entries := []yamlEntry{}
+		for decoder.More() {
+			key, err := decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+			value, err := decodeYAMLValue(decoder)
+			if err != nil {
+				return nil, err
+			}
+			entries = append(entries, yamlEntry{Key: key.(string), Value: value})
+		}
+		_, err = decoder.Token()
+		return entries, err
+	}
+
+	return token, nil
+}
+
+// yamlPlainKey matches keys that need no quotes, words YAML reads as
+// booleans or null are quoted anyway.
+var yamlPlainKey = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+func yamlKey(key string) string {
+	switch strings.ToLower(key) {
+	case "true", "false", "yes", "no", "on", "off", "y", "n", "null":
+	default:
+		if yamlPlainKey.MatchString(key) {
+			return key
+		}
+	}
+	quoted, _ := json.Marshal(key)
+	return string(quoted)
+}
+
+// yamlScalar returns the inline representation of v, or false if v needs a
+// nested block.
+func yamlScalar(v interface{}) (string, bool, error) {
+	switch v := v.(type) {
+	case nil:
+		return "null", true, nil
+	case []interface{}:
+		if len(v) == 0 {
+			return "[]", true, nil
+		}
+		return "", false, nil
+	case []yamlEntry:
+		if len(v) == 0 {
+			return "{}", true, nil
+		}
+		return "", false, nil
+	case string:
+		// JSON strings are valid double-quoted YAML scalars.
+		quoted, err := json.Marshal(v)
+		return string(quoted), true, err
+	case json.Number:
+		return v.String(), true, nil
+	case bool:
+		return fmt.Sprint(v), true, nil
+	default:
+		return "", false, fmt.Errorf("yaml: unsupported value %T", v)
+	}
+}
+
+func writeYAMLValue(buf *bytes.Buffer, v interface{}, indent int) error {
+	padding := strings.Repeat("  ", indent)
+
+	switch v := v.(type) {
+	case []yamlEntry:
+		for _, entry := range v {
+			if err := writeYAMLEntry(buf, padding+yamlKey(entry.Key)+":", entry.Value, indent); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		for _, item := range v {
+			scalar, ok, err := yamlScalar(item)
+			if err != nil {
+				return err
+			}
+			if ok {
+				fmt.Fprintf(buf, "%s- %s\n", padding, scalar)
+				continue
+			}
+
+			// Render the element one level deeper, then hang its first line
+			// off the list marker.
+			var element bytes.Buffer
+			if err := writeYAMLValue(&element, item, indent+1); err != nil {
+				return err
+			}
+			buf.WriteString(padding + "- ")
+			buf.Write(bytes.TrimPrefix(element.Bytes(), []byte(padding+"  ")))
+		}
+	default:
+		scalar, _, err := yamlScalar(v)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(buf, "%s%s\n", padding, scalar)
+	}
+
+	return nil
+}
+
+func writeYAMLEntry(buf *bytes.Buffer, key string, v interface{}, indent int) error {
+	scalar, ok, err := yamlScalar(v)
+	if err != nil {
+		return err
+	}
+	if ok {
+		fmt.Fprintf(buf, "%s %s\n", key, scalar)
+		return nil
+	}
+
+	buf.WriteString(key + "\n")
+	return writeYAMLValue(buf, v, indent+1)
+}
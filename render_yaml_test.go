@@ -0,0 +1,85 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+type yamlTestStatus int
+
+func (s yamlTestStatus) MarshalJSON() ([]byte, error) {
+	return []byte(`"status ` + strings.Repeat("I", int(s)) + `"`), nil
+}
+
+type yamlTestInner struct {
+	Level int `json:"level"`
+}
+
+type yamlTestDocument struct {
+	yamlTestInner
+	Name     string            `json:"name"`
+	Note     string            `json:"note,omitempty"`
+	Status   yamlTestStatus    `json:"status"`
+	Hidden   string            `json:"-"`
+	Saved    time.Time         `json:"saved"`
+	Tags     []string          `json:"tags"`
+	Counts   map[string]int    `json:"counts"`
+	Children []yamlTestInner   `json:"children"`
+	Empty    map[string]string `json:"empty"`
+	Missing  *yamlTestInner    `json:"missing"`
+}
+
+func TestWriteYAML(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		yaml  string
+	}{
+		{"scalar", "a \"b\"", "\"a \\\"b\\\"\"\n"},
+		{"empty list", []string{}, "[]\n"},
+		{"document", yamlTestDocument{
+			yamlTestInner: yamlTestInner{Level: 3},
+			Name:          "Nightfall",
+			Status:        2,
+			Hidden:        "secret",
+			Saved:         time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+			Tags:          []string{"a", "b"},
+			Counts:        map[string]int{"b": 2, "1": 1, "true": 0},
+			Children:      []yamlTestInner{{Level: 1}, {Level: 2}},
+		}, `level: 3
+name: "Nightfall"
+status: "status II"
+saved: "2024-05-01T12:00:00Z"
+tags:
+  - "a"
+  - "b"
+counts:
+  "1": 1
+  b: 2
+  "true": 0
+children:
+  - level: 1
+  - level: 2
+empty: null
+missing: null
+`},
+		{"nested lists", [][]int{{1, 2}, {}}, `- - 1
+  - 2
+- []
+`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := writeYAML(&buf, test.value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if buf.String() != test.yaml {
+				t.Errorf("yaml =\n%s\nwant\n%s", buf.String(), test.yaml)
+			}
+		})
+	}
+}
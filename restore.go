@@ -0,0 +1,61 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+)
+
+func runRestore(args []string) error {
+	flags := flag.NewFlagSet("restore", flag.ExitOnError)
+	allowHardcore := flags.Bool("allow-hardcore", false, "restore the save even if it belongs to a hardcore character")
+	positional, err := parseCommandArgs(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return fmt.Errorf("usage: %s", commands["restore"].Usage)
+	}
+	savePath := positional[0]
+	backupPath, err := latestBackup(savePath)
+	if err != nil {
+		return err
+	}
+
+	// Only a backup that reads as a save replaces the current one.
+	_, err = readSaveArchiveFile(backupPath)
+	if err != nil {
+		return fmt.Errorf("backup is not a readable save: %w", err)
+	}
+
+	err = checkHardcoreWrite(savePath, *allowHardcore)
+	if err != nil {
+		return err
+	}
+
+	// The current save becomes the newest backup, restoring again undoes
+	// the restore.
+	tempPath := savePath + ".tmp"
+	err = os.Rename(backupPath, tempPath)
+	if err != nil {
+		return err
+	}
+	currentPath, err := backupSave(savePath, time.Now())
+	if err != nil {
+		os.Rename(tempPath, backupPath)
+		return err
+	}
+	if currentPath != "" {
+		log.Printf("Current save moved to %s\n", currentPath)
+	}
+	err = os.Rename(tempPath, savePath)
+	if err != nil {
+		os.Rename(tempPath, backupPath)
+		return undoBackup(currentPath, savePath, err)
+	}
+	log.Printf("Restored %s\n", savePath)
+
+	return nil
+}
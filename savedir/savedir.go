@@ -0,0 +1,246 @@
+// Package savedir finds the Remnant 2 save folders of the current user.
+//
+// A save folder belongs to one account and holds profile.sav and one
+// save_N.sav per character. Accounts live in per-launcher subdirectories:
+//
+//	Windows, Steam:     %USERPROFILE%\Saved Games\Remnant2\Steam\<steam id>
+//	Windows, other:     %USERPROFILE%\Saved Games\Remnant2\<account id>
+//	Linux, Proton:      <steam library>/steamapps/compatdata/1282100/pfx/drive_c/users/steamuser/Saved Games/Remnant2/Steam/<steam id>
+//	Windows, Xbox app:  %LOCALAPPDATA%\Packages\<package>\SystemAppData\wgs\<user>, see wgs.go
+package savedir
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+)
+
+// EnvVar overrides the discovered save folder.
+const EnvVar = "REFINDER_SAVE_DIR"
+
+// SteamAppID is the Steam application id of Remnant 2, Proton keeps the
+// Windows user profile of the game in compatdata/<id>.
+const SteamAppID = "1282100"
+
+const profileFile = "profile.sav"
+
+// Account is a save folder that contains a profile.
+type Account struct {
+	Dir      string
+	Modified time.Time
+	// files maps logical save names to blobs for Xbox accounts.
+	files map[string]string
+}
+
+// Name returns the account folder name, usually the platform user id.
+func (a Account) Name() string {
+	return filepath.Base(a.Dir)
+}
+
+// Xbox reports whether the account uses the Xbox app container layout.
+func (a Account) Xbox() bool {
+	return a.files != nil
+}
+
+// Path returns the path of a save file of the account, like profile.sav.
+// Xbox saves are looked up in the containers index.
+func (a Account) Path(name string) string {
+	if a.files != nil {
+		if path, ok := a.files[name]; ok {
+			return path
+		}
+	}
+	return filepath.Join(a.Dir, name)
+}
+
+// Reload reads the containers index of an Xbox account again, the game
+// writes every save to a new blob.
+func (a *Account) Reload() error {
+	if a.files == nil {
+		return nil
+	}
+	files, err := readXboxFiles(a.Dir)
+	if err != nil {
+		return err
+	}
+	a.files = files
+	return nil
+}
+
+// Accounts returns the accounts found in dir. dir may be an account folder
+// itself or a folder whose subdirectories are accounts, like
+// Saved Games\Remnant2 or Saved Games\Remnant2\Steam.
+func Accounts(dir string) ([]Account, error) {
+	if account, ok := readAccount(dir); ok {
+		return []Account{account}, nil
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var accounts []Account
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		if account, ok := readAccount(filepath.Join(dir, entry.Name())); ok {
+			accounts = append(accounts, account)
+		}
+	}
+	sortAccounts(accounts)
+
+	return accounts, nil
+}
+
+func readAccount(dir string) (Account, bool) {
+	info, err := os.Stat(filepath.Join(dir, profileFile))
+	if err == nil && !info.IsDir() {
+		return Account{Dir: dir, Modified: info.ModTime()}, true
+	}
+
+	info, err = os.Stat(filepath.Join(dir, IndexFile))
+	if err != nil || info.IsDir() {
+		return Account{}, false
+	}
+	files, err := readXboxFiles(dir)
+	if err != nil {
+		return Account{}, false
+	}
+	if _, ok := files[profileFile]; !ok {
+		return Account{}, false
+	}
+	return Account{Dir: dir, Modified: info.ModTime(), files: files}, true
+}
+
+// FileAccount returns the account a save file belongs to and the logical
+// name of the file in it, like save_0.sav, or nil for files outside of an
+// account folder. Xbox blobs are named by GUID, they take the name of the
+// container whose folder holds them. An Xbox index that can not be read is
+// an error, the file may well belong to the account.
+func FileAccount(path string) (*Account, string, error) {
+	dir := filepath.Dir(path)
+	if account, ok := readAccount(dir); ok && !account.Xbox() {
+		return &account, filepath.Base(path), nil
+	}
+
+	xboxDir := filepath.Dir(dir)
+	info, err := os.Stat(filepath.Join(xboxDir, IndexFile))
+	if err != nil || info.IsDir() {
+		return nil, "", nil
+	}
+	containers, err := ReadContainers(xboxDir)
+	if err != nil {
+		return nil, "", err
+	}
+	account := Account{Dir: xboxDir, Modified: info.ModTime(), files: xboxFiles(containers)}
+	if _, ok := account.files[profileFile]; !ok {
+		return nil, "", nil
+	}
+	for _, container := range containers {
+		if strings.EqualFold(container.Folder, filepath.Base(dir)) {
+			return &account, container.FileName(), nil
+		}
+	}
+	return nil, "", nil
+}
+
+// sortAccounts puts the most recently played account first.
+func sortAccounts(accounts []Account) {
+	slices.SortStableFunc(accounts, func(a, b Account) int {
+		return b.Modified.Compare(a.Modified)
+	})
+}
+
+// Find returns the accounts in the folder given by the REFINDER_SAVE_DIR
+// environment variable, or all accounts found in the default locations of
+// the current platform.
+func Find() ([]Account, error) {
+	if dir := os.Getenv(EnvVar); dir != "" {
+		return Accounts(dir)
+	}
+
+	var accounts []Account
+	seen := map[string]bool{}
+	for _, root := range Roots() {
+		found, err := Accounts(root)
+		if err != nil {
+			continue
+		}
+		for _, account := range found {
+			if !seen[account.Dir] {
+				seen[account.Dir] = true
+				accounts = append(accounts, account)
+			}
+		}
+	}
+	sortAccounts(accounts)
+
+	return accounts, nil
+}
+
+// Roots returns the folders that may contain account folders on the current
+// platform, whether they exist or not.
+func Roots() []string {
+	var roots []string
+
+	if runtime.GOOS == "windows" {
+		savedGames := filepath.Join(os.Getenv("USERPROFILE"), "Saved Games", "Remnant2")
+		roots = append(roots, filepath.Join(savedGames, "Steam"), savedGames)
+		return append(roots, xboxRoots()...)
+	}
+
+	for _, library := range steamLibraries() {
+		savedGames := filepath.Join(library, "steamapps", "compatdata", SteamAppID, "pfx", "drive_c", "users", "steamuser", "Saved Games", "Remnant2")
+		roots = append(roots, filepath.Join(savedGames, "Steam"), savedGames)
+	}
+	return roots
+}
+
+var libraryPathPattern = regexp.MustCompile(`"path"\s+"([^"]+)"`)
+
+// steamLibraries returns the Steam installation folders and every extra
+// library listed in their libraryfolders.vdf.
+func steamLibraries() []string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil
+	}
+
+	installs := []string{
+		filepath.Join(home, ".steam", "steam"),
+		filepath.Join(home, ".local", "share", "Steam"),
+		filepath.Join(home, ".var", "app", "com.valvesoftware.Steam", ".local", "share", "Steam"),
+	}
+
+	var libraries []string
+	seen := map[string]bool{}
+	add := func(dir string) {
+		if resolved, err := filepath.EvalSymlinks(dir); err == nil {
+			dir = resolved
+		}
+		if !seen[dir] {
+			seen[dir] = true
+			libraries = append(libraries, dir)
+		}
+	}
+
+	for _, install := range installs {
+		add(install)
+
+		data, err := os.ReadFile(filepath.Join(install, "steamapps", "libraryfolders.vdf"))
+		if err != nil {
+			continue
+		}
+		for _, match := range libraryPathPattern.FindAllStringSubmatch(string(data), -1) {
+			add(match[1])
+		}
+	}
+
+	return libraries
+}
@@ -0,0 +1,251 @@
+package savedir
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf16"
+)
+
+// The Xbox app (Game Pass, Microsoft Store) keeps saves in a "wgs" folder:
+//
+//	%LOCALAPPDATA%\Packages\<package>\SystemAppData\wgs\<user>\containers.index
+//	%LOCALAPPDATA%\Packages\<package>\SystemAppData\wgs\<user>\<container guid>\container.N
+//	%LOCALAPPDATA%\Packages\<package>\SystemAppData\wgs\<user>\<container guid>\<blob guid>
+//
+// containers.index lists the logical containers ("profile", "save_0", ...)
+// with the folder that holds them, container.N lists the blobs of a container.
+// The blobs are regular compressed saves. Every write creates a new blob, the
+// index is updated last.
+
+// IndexFile is the containers index of an Xbox account folder.
+const IndexFile = "containers.index"
+
+const xboxPackagePattern = "PerfectWorldEntertainment.GFREMP2_*"
+
+// Container is a logical save file in an Xbox account folder.
+type Container struct {
+	Name     string
+	Number   uint8
+	Folder   string
+	Modified time.Time
+	Blobs    []Blob
+}
+
+// Blob is a file inside a container.
+type Blob struct {
+	Name string
+	Path string
+}
+
+// Windows FILETIME counts 100 nanosecond intervals since 1601-01-01.
+const filetimeUnixEpoch = 116444736000000000
+
+func filetimeToTime(filetime uint64) time.Time {
+	return time.Unix(0, (int64(filetime)-filetimeUnixEpoch)*100)
+}
+
+// formatGUID formats a GUID the way the wgs folder and blob names use it:
+// upper case hex without dashes, the first three fields little endian.
+func formatGUID(guid [16]byte) string {
+	return fmt.Sprintf("%08X%04X%04X%X",
+		binary.LittleEndian.Uint32(guid[0:4]),
+		binary.LittleEndian.Uint16(guid[4:6]),
+		binary.LittleEndian.Uint16(guid[6:8]),
+		guid[8:16])
+}
+
+type wgsReader struct {
+	r   *bufio.Reader
+	err error
+}
+
+func (w *wgsReader) read(data interface{}) {
+	if w.err == nil {
+		w.err = binary.Read(w.r, binary.LittleEndian, data)
+	}
+}
+
+func (w *wgsReader) skip(n int) {
+	if w.err == nil {
+		_, w.err = w.r.Discard(n)
+	}
+}
+
+func (w *wgsReader) u32() uint32 {
+	var value uint32
+	w.read(&value)
+	return value
+}
+
+func (w *wgsReader) u64() uint64 {
+	var value uint64
+	w.read(&value)
+	return value
+}
+
+// utf16String reads length UTF-16 characters, a negative length reads a
+// uint32 length prefix first.
+func (w *wgsReader) utf16String(length int) string {
+	if length < 0 {
+		length = int(w.u32())
+	}
+	if w.err != nil {
+		return ""
+	}
+	if length > 1<<16 {
+		w.err = fmt.Errorf("string length %d is too large", length)
+		return ""
+	}
+
+	chars := make([]uint16, length)
+	w.read(chars)
+	return strings.TrimRight(string(utf16.Decode(chars)), "\x00")
+}
+
+func (w *wgsReader) guid() [16]byte {
+	var guid [16]byte
+	w.read(&guid)
+	return guid
+}
+
+// ReadContainers reads containers.index in dir and the container files it
+// points to.
+func ReadContainers(dir string) ([]Container, error) {
+	file, err := os.Open(filepath.Join(dir, IndexFile))
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	r := &wgsReader{r: bufio.NewReader(file)}
+	r.skip(4) // version
+	count := r.u32()
+	r.skip(4)
+	r.utf16String(-1) // package name
+	r.skip(8)         // last modified
+	r.skip(4)
+	r.utf16String(-1) // id
+	r.skip(8)
+	if r.err != nil {
+		return nil, fmt.Errorf("failed to read %s header: %w", IndexFile, r.err)
+	}
+
+	// count comes from the file, it is not trusted to size anything.
+	containers := []Container{}
+	for i := uint32(0); i < count; i++ {
+		container := Container{}
+		container.Name = r.utf16String(-1)
+		r.utf16String(-1) // same name again
+		r.utf16String(-1) // quoted hex id
+		r.read(&container.Number)
+		r.skip(4)
+		container.Folder = formatGUID(r.guid())
+		container.Modified = filetimeToTime(r.u64())
+		r.skip(8)
+		r.skip(8) // size
+		if r.err != nil {
+			return nil, fmt.Errorf("failed to read %s entry %d: %w", IndexFile, i, r.err)
+		}
+
+		container.Blobs, err = readContainer(filepath.Join(dir, container.Folder), container.Number)
+		if err != nil {
+			return nil, fmt.Errorf("container %s: %w", container.Name, err)
+		}
+		containers = append(containers, container)
+	}
+
+	return containers, nil
+}
+
+// truncated reports a file that ends early as such, not as a clean end.
+func truncated(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+func readContainer(folder string, number uint8) ([]Blob, error) {
+	file, err := os.Open(filepath.Join(folder, fmt.Sprintf("container.%d", number)))
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	r := &wgsReader{r: bufio.NewReader(file)}
+	r.skip(4) // version
+	count := r.u32()
+	if r.err != nil {
+		return nil, truncated(r.err)
+	}
+
+	var blobs []Blob
+	for i := uint32(0); i < count; i++ {
+		name := r.utf16String(64)
+		// The blob is stored under one of two GUIDs, usually both are the same.
+		guids := []string{formatGUID(r.guid()), formatGUID(r.guid())}
+		if r.err != nil {
+			return nil, truncated(r.err)
+		}
+
+		blob := Blob{Name: name, Path: filepath.Join(folder, guids[0])}
+		for _, guid := range guids {
+			if _, err := os.Stat(filepath.Join(folder, guid)); err == nil {
+				blob.Path = filepath.Join(folder, guid)
+				break
+			}
+		}
+		blobs = append(blobs, blob)
+	}
+
+	return blobs, nil
+}
+
+// FileName returns the logical save name of a container, like save_0.sav.
+func (c Container) FileName() string {
+	if filepath.Ext(c.Name) == "" {
+		return c.Name + ".sav"
+	}
+	return c.Name
+}
+
+// readXboxFiles maps the logical save names (profile.sav, save_0.sav, ...) of
+// an Xbox account folder to the blobs that hold them.
+func readXboxFiles(dir string) (map[string]string, error) {
+	containers, err := ReadContainers(dir)
+	if err != nil {
+		return nil, err
+	}
+	return xboxFiles(containers), nil
+}
+
+func xboxFiles(containers []Container) map[string]string {
+	files := map[string]string{}
+	for _, container := range containers {
+		if len(container.Blobs) == 0 {
+			continue
+		}
+		files[container.FileName()] = container.Blobs[0].Path
+	}
+	return files
+}
+
+// xboxRoots returns the wgs folders of the Xbox app package on Windows.
+func xboxRoots() []string {
+	packages, err := filepath.Glob(filepath.Join(os.Getenv("LOCALAPPDATA"), "Packages", xboxPackagePattern))
+	if err != nil {
+		return nil
+	}
+
+	roots := make([]string, 0, len(packages))
+	for _, pkg := range packages {
+		roots = append(roots, filepath.Join(pkg, "SystemAppData", "wgs"))
+	}
+	return roots
+}
@@ -0,0 +1,319 @@
+package savedir
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf16"
+)
+
+type wgsWriter struct {
+	bytes.Buffer
+}
+
+func (w *wgsWriter) write(data interface{}) {
+	binary.Write(w, binary.LittleEndian, data)
+}
+
+func (w *wgsWriter) utf16String(value string) {
+	chars := utf16.Encode([]rune(value))
+	w.write(uint32(len(chars)))
+	w.write(chars)
+}
+
+func (w *wgsWriter) fixedUTF16String(value string, length int) {
+	chars := make([]uint16, length)
+	copy(chars, utf16.Encode([]rune(value)))
+	w.write(chars)
+}
+
+type testBlob struct {
+	name string
+	// guids are the two GUIDs of the blob, files are created for the ones
+	// in stored.
+	guids  [2][16]byte
+	stored [][16]byte
+}
+
+type testContainer struct {
+	name   string
+	number uint8
+	folder [16]byte
+	blobs  []testBlob
+}
+
+func testGUID(b byte) [16]byte {
+	return [16]byte{b, 0, 0, 0x11, 1, 0x22, 2, 0x33, 0xaa, 0xbb, 0xcc, 0xdd, 0xee, 0xff, 0x01, b}
+}
+
+func writeFile(t *testing.T, path string, data []byte) {
+	t.Helper()
+	err := os.MkdirAll(filepath.Dir(path), 0o755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.WriteFile(path, data, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// writeWGS writes containers.index and the container files of an Xbox
+// account folder.
+func writeWGS(t *testing.T, dir string, containers []testContainer) {
+	t.Helper()
+
+	var index wgsWriter
+	index.write(uint32(14))
+	index.write(uint32(len(containers)))
+	index.write(uint32(0))
+	index.utf16String("PerfectWorldEntertainment.GFREMP2_jrajkyc4tsa6w!AppRemnant2Shipping")
+	index.write(uint64(filetimeUnixEpoch))
+	index.write(uint32(0))
+	index.utf16String("0x0000000000000000")
+	index.write(uint64(0))
+
+	for _, container := range containers {
+		index.utf16String(container.name)
+		index.utf16String(container.name)
+		index.utf16String(`"0x8DB0000000000000"`)
+		index.write(container.number)
+		index.write(uint32(0))
+		index.write(container.folder)
+		index.write(uint64(filetimeUnixEpoch + 10_000_000))
+		index.write(uint64(0))
+		index.write(uint64(1234))
+
+		folder := filepath.Join(dir, formatGUID(container.folder))
+		var containerFile wgsWriter
+		containerFile.write(uint32(4))
+		containerFile.write(uint32(len(container.blobs)))
+		for _, blob := range container.blobs {
+			containerFile.fixedUTF16String(blob.name, 64)
+			containerFile.write(blob.guids)
+			for _, guid := range blob.stored {
+				writeFile(t, filepath.Join(folder, formatGUID(guid)), []byte("blob"))
+			}
+		}
+		writeFile(t, filepath.Join(folder, "container."+string('0'+container.number)), containerFile.Bytes())
+	}
+
+	writeFile(t, filepath.Join(dir, IndexFile), index.Bytes())
+}
+
+func testXboxAccount(t *testing.T) string {
+	dir := t.TempDir()
+	writeWGS(t, dir, []testContainer{
+		{name: "profile", number: 1, folder: testGUID(1), blobs: []testBlob{
+			{name: "Data", guids: [2][16]byte{testGUID(11), testGUID(11)}, stored: [][16]byte{testGUID(11)}},
+		}},
+		{name: "save_1", number: 3, folder: testGUID(2), blobs: []testBlob{
+			{name: "Data", guids: [2][16]byte{testGUID(21), testGUID(22)}, stored: [][16]byte{testGUID(22)}},
+		}},
+	})
+	return dir
+}
+
+func TestFileAccount(t *testing.T) {
+	xboxDir := testXboxAccount(t)
+	steamDir := t.TempDir()
+	writeFile(t, filepath.Join(steamDir, profileFile), []byte("profile"))
+	corruptDir := testXboxAccount(t)
+	writeFile(t, filepath.Join(corruptDir, IndexFile), []byte{14, 0, 0, 0, 2})
+
+	tests := []struct {
+		name    string
+		path    string
+		account string
+		file    string
+		err     bool
+	}{
+		{"steam save", filepath.Join(steamDir, "save_2.sav"), steamDir, "save_2.sav", false},
+		{"steam profile", filepath.Join(steamDir, profileFile), steamDir, profileFile, false},
+		{"xbox save blob", filepath.Join(xboxDir, formatGUID(testGUID(2)), formatGUID(testGUID(22))), xboxDir, "save_1.sav", false},
+		{"xbox older blob", filepath.Join(xboxDir, formatGUID(testGUID(2)), "00000000000000000000000000000000"), xboxDir, "save_1.sav", false},
+		{"xbox profile blob", filepath.Join(xboxDir, formatGUID(testGUID(1)), formatGUID(testGUID(11))), xboxDir, profileFile, false},
+		{"xbox unknown folder", filepath.Join(xboxDir, "FOLDER", "BLOB"), "", "", false},
+		{"xbox index", filepath.Join(xboxDir, IndexFile), "", "", false},
+		{"corrupt xbox index", filepath.Join(corruptDir, formatGUID(testGUID(2)), "BLOB"), "", "", true},
+		{"outside of an account", filepath.Join(t.TempDir(), "save_0.sav"), "", "", false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			account, file, err := FileAccount(test.path)
+			if (err != nil) != test.err {
+				t.Fatalf("err = %v, want error %t", err, test.err)
+			}
+			dir := ""
+			if account != nil {
+				dir = account.Dir
+			}
+			if dir != test.account || file != test.file {
+				t.Errorf("FileAccount = %q, %q, want %q, %q", dir, file, test.account, test.file)
+			}
+		})
+	}
+}
+
+func TestFormatGUID(t *testing.T) {
+	tests := []struct {
+		guid [16]byte
+		name string
+	}{
+		{[16]byte{}, "00000000000000000000000000000000"},
+		{[16]byte{0x33, 0x22, 0x11, 0x00, 0x55, 0x44, 0x77, 0x66, 0x88, 0x99, 0xaa, 0xbb, 0xcc, 0xdd, 0xee, 0xff}, "00112233445566778899AABBCCDDEEFF"},
+		{[16]byte{0x01, 0, 0, 0, 0x02, 0, 0x03, 0, 0, 0x04, 0, 0, 0, 0, 0, 0x05}, "00000001000200030004000000000005"},
+	}
+	for _, test := range tests {
+		if name := formatGUID(test.guid); name != test.name {
+			t.Errorf("formatGUID(%x) = %s, want %s", test.guid, name, test.name)
+		}
+	}
+}
+
+func TestReadContainers(t *testing.T) {
+	dir := t.TempDir()
+	writeWGS(t, dir, []testContainer{
+		{name: "profile", number: 1, folder: testGUID(1), blobs: []testBlob{
+			{name: "Data", guids: [2][16]byte{testGUID(11), testGUID(11)}, stored: [][16]byte{testGUID(11)}},
+		}},
+		{name: "save_0", number: 2, folder: testGUID(2), blobs: []testBlob{
+			// Only the second GUID was written.
+			{name: "Data", guids: [2][16]byte{testGUID(21), testGUID(22)}, stored: [][16]byte{testGUID(22)}},
+			// Both were, the first wins.
+			{name: "Extra", guids: [2][16]byte{testGUID(23), testGUID(24)}, stored: [][16]byte{testGUID(23), testGUID(24)}},
+			// Neither was, the path is the first.
+			{name: "Missing", guids: [2][16]byte{testGUID(25), testGUID(26)}},
+		}},
+	})
+
+	containers, err := ReadContainers(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(containers) != 2 {
+		t.Fatalf("%d containers, want 2", len(containers))
+	}
+
+	profile, save := containers[0], containers[1]
+	if profile.Name != "profile" || profile.Number != 1 || profile.Folder != formatGUID(testGUID(1)) || profile.FileName() != "profile.sav" {
+		t.Errorf("profile = %+v", profile)
+	}
+	if !profile.Modified.Equal(time.Unix(1, 0)) {
+		t.Errorf("modified = %v, want %v", profile.Modified, time.Unix(1, 0))
+	}
+
+	folder := filepath.Join(dir, formatGUID(testGUID(2)))
+	wantBlobs := []Blob{
+		{Name: "Data", Path: filepath.Join(folder, formatGUID(testGUID(22)))},
+		{Name: "Extra", Path: filepath.Join(folder, formatGUID(testGUID(23)))},
+		{Name: "Missing", Path: filepath.Join(folder, formatGUID(testGUID(25)))},
+	}
+	if save.Name != "save_0" || save.Number != 2 || len(save.Blobs) != len(wantBlobs) {
+		t.Fatalf("save = %+v", save)
+	}
+	for i, blob := range save.Blobs {
+		if blob != wantBlobs[i] {
+			t.Errorf("blob %d = %+v, want %+v", i, blob, wantBlobs[i])
+		}
+	}
+
+	files := xboxFiles(containers)
+	if files["save_0.sav"] != wantBlobs[0].Path || files["profile.sav"] != filepath.Join(dir, formatGUID(testGUID(1)), formatGUID(testGUID(11))) {
+		t.Errorf("files = %v", files)
+	}
+}
+
+func TestReadContainersTruncated(t *testing.T) {
+	containers := []testContainer{
+		{name: "save_0", number: 1, folder: testGUID(2), blobs: []testBlob{
+			{name: "Data", guids: [2][16]byte{testGUID(21), testGUID(21)}, stored: [][16]byte{testGUID(21)}},
+		}},
+	}
+	containerFile := filepath.Join(formatGUID(testGUID(2)), "container.1")
+
+	tests := []struct {
+		name string
+		file string
+		// keep is the number of bytes left in file, -1 removes it.
+		keep func(size int) int
+	}{
+		{"empty index", IndexFile, func(int) int { return 0 }},
+		{"index header", IndexFile, func(int) int { return 10 }},
+		{"index entry", IndexFile, func(size int) int { return size - 10 }},
+		{"container header", containerFile, func(int) int { return 6 }},
+		{"container blob name", containerFile, func(int) int { return 40 }},
+		{"container blob guid", containerFile, func(size int) int { return size - 8 }},
+		{"missing container", containerFile, func(int) int { return -1 }},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeWGS(t, dir, containers)
+
+			path := filepath.Join(dir, test.file)
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if keep := test.keep(len(data)); keep < 0 {
+				err = os.Remove(path)
+			} else {
+				err = os.WriteFile(path, data[:keep], 0o644)
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, err = ReadContainers(dir)
+			if err == nil {
+				t.Error("no error for a truncated file")
+			}
+		})
+	}
+
+	t.Run("string length", func(t *testing.T) {
+		dir := t.TempDir()
+		var index wgsWriter
+		index.write(uint32(14))
+		index.write(uint32(1))
+		index.write(uint32(0))
+		index.write(uint32(1 << 20))
+		writeFile(t, filepath.Join(dir, IndexFile), index.Bytes())
+
+		_, err := ReadContainers(dir)
+		if err == nil || !strings.Contains(err.Error(), "too large") {
+			t.Errorf("err = %v, want a too large string", err)
+		}
+	})
+
+	t.Run("container count", func(t *testing.T) {
+		// A corrupt count must fail on the missing entries, not size the
+		// result by it.
+		dir := t.TempDir()
+		writeWGS(t, dir, containers)
+		path := filepath.Join(dir, IndexFile)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		binary.LittleEndian.PutUint32(data[4:], 0xffffffff)
+		writeFile(t, path, data)
+
+		var before, after runtime.MemStats
+		runtime.ReadMemStats(&before)
+		_, err = ReadContainers(dir)
+		runtime.ReadMemStats(&after)
+		if err == nil {
+			t.Error("no error for a count past the entries")
+		}
+		if allocated := after.TotalAlloc - before.TotalAlloc; allocated > 1<<20 {
+			t.Errorf("allocated %d bytes for one entry", allocated)
+		}
+	})
+}
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// server shares one session between HTTP handlers. Saves are read again
+// whenever the game writes them and every subscriber is notified.
+type server struct {
+	mu      sync.RWMutex
+	session *Session
+	// errors holds why the world of a character could not be read.
+	errors  map[int32]error
+	version int
+
+	subscribersMu sync.Mutex
+	subscribers   map[chan int]struct{}
+}
+
+func newServer(session *Session) *server {
+	return &server{
+		session:     session,
+		errors:      map[int32]error{},
+		subscribers: map[chan int]struct{}{},
+	}
+}
+
+// refreshAll reads the profile and the world of every character.
+func (s *server) refreshAll() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	err := s.session.RefreshProfile()
+	if err != nil {
+		return err
+	}
+	for _, characterID := range s.session.CharacterIDs() {
+		s.refreshCharacter(characterID)
+	}
+	s.version++
+	return nil
+}
+
+// refreshCharacter must be called with mu held.
+func (s *server) refreshCharacter(characterID int32) {
+	_, err := s.session.RefreshCharacter(characterID)
+	if err != nil {
+		s.errors[characterID] = err
+		return
+	}
+	delete(s.errors, characterID)
+}
+
+func (s *server) onWrite(name string) {
+	s.mu.Lock()
+	name, err := s.session.changedFile(name)
+	s.mu.Unlock()
+	if err != nil {
+		log.Println("error:", err)
+		return
+	}
+
+	if name == profileFileName {
+		err := s.refreshAll()
+		if err != nil {
+			log.Println("error:", err)
+			return
+		}
+	} else if characterID, ok := parseSaveFileName(name); ok {
+		s.mu.Lock()
+		if _, exists := s.session.Characters[characterID]; !exists {
+			s.mu.Unlock()
+			return
+		}
+		s.refreshCharacter(characterID)
+		s.version++
+		s.mu.Unlock()
+	} else {
+		return
+	}
+
+	s.notify()
+}
+
+func (s *server) subscribe() chan int {
+	updates := make(chan int, 1)
+	s.subscribersMu.Lock()
+	s.subscribers[updates] = struct{}{}
+	s.subscribersMu.Unlock()
+	return updates
+}
+
+func (s *server) unsubscribe(updates chan int) {
+	s.subscribersMu.Lock()
+	delete(s.subscribers, updates)
+	s.subscribersMu.Unlock()
+}
+
+// notify sends the current version to every subscriber, a subscriber that
+// did not read the previous update only gets the latest one.
+func (s *server) notify() {
+	s.mu.RLock()
+	version := s.version
+	s.mu.RUnlock()
+
+	s.subscribersMu.Lock()
+	defer s.subscribersMu.Unlock()
+	for updates := range s.subscribers {
+		select {
+		case <-updates:
+		default:
+		}
+		updates <- version
+	}
+}
+
+// sseKeepAlive keeps idle event streams open through proxies.
+const sseKeepAlive = 30 * time.Second
+
+// handleEvents streams an "update" server-sent event after every save write.
+func (s *server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	flusher.Flush()
+
+	updates := s.subscribe()
+	defer s.unsubscribe(updates)
+
+	keepAlive := time.NewTicker(sseKeepAlive)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case version := <-updates:
+			fmt.Fprintf(w, "event: update\ndata: %d\n\n", version)
+			flusher.Flush()
+		case <-keepAlive.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+			flusher.Flush()
+		}
+	}
+}
+
+func (s *server) routes() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", s.handleIndex)
+	mux.HandleFunc("GET /events", s.handleEvents)
+	mux.HandleFunc("GET /overlay", s.handleOverlay)
+	mux.HandleFunc("GET /overlay.json", s.handleOverlayJSON)
+	mux.HandleFunc("GET /characters", s.handleCharacters)
+	mux.HandleFunc("GET /characters/{id}/worlds", s.handleWorlds)
+	mux.HandleFunc("GET /characters/{id}/inventory", s.handleInventory)
+	mux.HandleFunc("GET /search", s.handleSearch)
+	return mux
+}
+
+func runServe(args []string) error {
+	flags := flag.NewFlagSet("serve", flag.ExitOnError)
+	addr := flags.String("addr", "127.0.0.1:8080", "address to listen on")
+	saveDir := addSaveDirFlag(flags)
+	addLangFlag(flags)
+	world := addWorldFlag(flags)
+	ownedBy := addOwnedByFlag(flags)
+	positional, err := parseCommandArgs(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 0 {
+		return fmt.Errorf("usage: %s", commands["serve"].Usage)
+	}
+
+	account, err := selectAccount(*saveDir)
+	if err != nil {
+		return err
+	}
+
+	session := NewSession(account, *world)
+	session.OwnedBy = *ownedBy
+	s := newServer(session)
+	err = s.refreshAll()
+	if err != nil {
+		return err
+	}
+
+	watcher, err := watchSaves(s.session.Account, s.onWrite)
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+
+	log.Printf("Serving http://%s\n", *addr)
+	return http.ListenAndServe(*addr, s.routes())
+}
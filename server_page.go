@@ -0,0 +1,114 @@
+package main
+
+import (
+	"bytes"
+	"html/template"
+	"log"
+	"net/http"
+)
+
+type pageCharacter struct {
+	Character CharacterData
+	World     ZoneInfo
+	Active    bool
+	Error     string
+}
+
+type pageData struct {
+	World      string
+	Characters []pageCharacter
+}
+
+var pageFuncs = template.FuncMap{
+	"printable":     getPrintableName,
+	"name":          getItemName,
+	"biome":         getBiomeName,
+	"characterType": getCharacterTypeName,
+	"isMaterial":    isMaterial,
+}
+
+var pageTemplate = template.Must(template.New("page").Funcs(pageFuncs).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>ReFinder</title>
+<style>
+body { font-family: sans-serif; background: #1b1b1f; color: #ddd; margin: 2em; }
+section { margin-bottom: 2em; }
+h2 { margin-bottom: 0.2em; }
+.meta { color: #999; }
+.active { color: #7c7; font-size: 0.7em; vertical-align: middle; }
+ul { list-style: none; padding-left: 1.2em; }
+details > summary { cursor: pointer; }
+.owned { color: #7c7; }
+.missing { color: #e77; }
+.kind { color: #999; }
+.status { color: #999; font-size: 0.9em; }
+.error { color: #e77; }
+</style>
+</head>
+<body>
+{{define "zone"}}
+<li><details open><summary>{{.Label}}</summary>
+<ul>
+{{range .ZoneLinks}}{{if eq .Type "EZoneLinkType::Waypoint"}}<li><span class="kind">[Waypoint]</span> {{.Label}}</li>{{end}}{{end}}
+{{range .Items}}{{if isMaterial .Name}}<li><span class="kind">[Material]</span> x{{.Quantity}} {{printable .Name}}</li>
+{{else}}<li class="{{if .OwnedByCharacter}}owned{{else}}missing{{end}}"><span class="kind">[Item]</span> x{{.Quantity}} {{printable .Name}}</li>
+{{end}}{{end}}
+{{range .Events}}<li><span class="kind">[{{.Kind.Label}}]</span> {{name .Name}} <span class="status">{{.Status}}</span>
+{{if .Rewards}}<ul>{{range .Rewards}}<li class="{{if .OwnedByCharacter}}owned{{else}}missing{{end}}"><span class="kind">[Reward]</span> x{{.Quantity}} {{printable .ActorBP}}</li>{{end}}</ul>{{end}}
+</li>
+{{end}}
+{{range .Children}}{{template "zone" .}}{{end}}
+</ul>
+</details></li>
+{{end}}
+<h1>ReFinder</h1>
+{{range .Characters}}
+<section>
+<h2>{{.Character.Archetype}} {{if .Active}}<span class="active">active</span>{{end}}</h2>
+<div class="meta">Character {{.Character.ID}}, {{characterType .Character.Type}}{{if not .Error}} | {{$.World}}: {{biome .World.Biome}}{{if .World.BloodMoon}} | Blood Moon{{end}}{{end}}</div>
+{{if .Error}}<p class="error">{{.Error}}</p>
+{{else if .World.ZoneActor}}<ul>{{template "zone" .World.ZoneActor}}</ul>{{end}}
+</section>
+{{end}}
+<script>
+new EventSource("/events").addEventListener("update", () => location.reload());
+</script>
+</body>
+</html>
+`))
+
+// pageCharacters returns every character with its world, must be called
+// with mu held for reading.
+func (s *server) pageCharacters() []pageCharacter {
+	var characters []pageCharacter
+	for _, characterID := range s.session.CharacterIDs() {
+		character := pageCharacter{
+			Character: s.session.Characters[characterID],
+			World:     s.session.Zones[characterID],
+			Active:    characterID == s.session.ActiveCharacterID,
+		}
+		if err, ok := s.errors[characterID]; ok {
+			character.Error = err.Error()
+		}
+		characters = append(characters, character)
+	}
+	return characters
+}
+
+func (s *server) handleIndex(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	data := pageData{World: s.session.World, Characters: s.pageCharacters()}
+	var buf bytes.Buffer
+	err := pageTemplate.Execute(&buf, data)
+	s.mu.RUnlock()
+	if err != nil {
+		log.Println("error:", err)
+		http.Error(w, "could not render page", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	buf.WriteTo(w)
+}
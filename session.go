@@ -0,0 +1,215 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"path/filepath"
+	"refinder/savedir"
+	"slices"
+	"strings"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+const profileFileName = "profile.sav"
+
+func saveFileName(characterID int32) string {
+	return fmt.Sprintf("save_%d.sav", characterID)
+}
+
+// parseSaveFileName returns the character ID of a save_N.sav file name.
+func parseSaveFileName(name string) (int32, bool) {
+	var characterID int32
+	_, err := fmt.Sscanf(name, "save_%d.sav", &characterID)
+	return characterID, err == nil && name == saveFileName(characterID)
+}
+
+// Ownership modes: items count as owned when the character has them, or
+// when any character of the account has them.
+const (
+	OwnedByCharacter = "character"
+	OwnedByAny       = "any"
+)
+
+// Session holds the characters of an account and the last world read for
+// each of them.
+type Session struct {
+	Account savedir.Account
+	World   string
+	// OwnedBy is the ownership mode the worlds are read with.
+	OwnedBy           string
+	Characters        map[int32]CharacterData
+	ActiveCharacterID int32
+	Zones             map[int32]ZoneInfo
+	// ProfileCRC is the checksum of the profile the characters were read from.
+	ProfileCRC uint32
+}
+
+func NewSession(account savedir.Account, world string) *Session {
+	return &Session{
+		Account:           account,
+		World:             world,
+		OwnedBy:           OwnedByCharacter,
+		Characters:        map[int32]CharacterData{},
+		ActiveCharacterID: -1,
+		Zones:             map[int32]ZoneInfo{},
+	}
+}
+
+func (s *Session) RefreshProfile() error {
+	characters, activeCharacterID, crc, err := refreshProfile(s.Account.Path(profileFileName))
+	if err != nil {
+		return err
+	}
+	s.ProfileCRC = crc
+	s.Characters = characters
+	s.ActiveCharacterID = activeCharacterID
+	return nil
+}
+
+// RefreshCharacter reads the save of a character again and returns what
+// changed since it was read last, nil the first time.
+func (s *Session) RefreshCharacter(characterID int32) (*WorldChanges, error) {
+	characterData, ok := s.Characters[characterID]
+	if !ok {
+		return nil, fmt.Errorf("character %d does not exist", characterID)
+	}
+
+	characterData.Items = s.OwnedItems(characterID)
+	zoneInfo, err := refreshSaveFile(s.Account.Path(saveFileName(characterID)), characterData, s.World)
+	if err != nil {
+		return nil, err
+	}
+
+	previous, seen := s.Zones[characterID]
+	s.Zones[characterID] = zoneInfo
+	if !seen {
+		return nil, nil
+	}
+	return diffWorlds(previous, zoneInfo), nil
+}
+
+// AccountItems returns the items owned by any character, sorted.
+func (s *Session) AccountItems() []string {
+	var items []string
+	for _, characterData := range s.Characters {
+		items = append(items, characterData.Items...)
+	}
+	slices.Sort(items)
+	return slices.Compact(items)
+}
+
+// OwnedItems returns the items that count as owned in the worlds of a
+// character under the ownership mode of the session.
+func (s *Session) OwnedItems(characterID int32) []string {
+	if s.OwnedBy == OwnedByAny {
+		return s.AccountItems()
+	}
+	return s.Characters[characterID].Items
+}
+
+// CharacterIDs returns the IDs of all characters in order.
+func (s *Session) CharacterIDs() []int32 {
+	ids := make([]int32, 0, len(s.Characters))
+	for id := range s.Characters {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+// SelectCharacters returns the characters to show: all of them, the given
+// one, or the active one when characterID is negative.
+func (s *Session) SelectCharacters(characterID int, all bool) ([]int32, error) {
+	if all {
+		return s.CharacterIDs(), nil
+	}
+	if characterID < 0 {
+		characterID = int(s.ActiveCharacterID)
+	}
+	if _, ok := s.Characters[int32(characterID)]; !ok {
+		return nil, fmt.Errorf("character %d does not exist, see refinder list", characterID)
+	}
+	return []int32{int32(characterID)}, nil
+}
+
+// changedFile returns the save file a name reported by watchSaves stands
+// for. A write of the containers index of an Xbox account does not tell
+// which container changed: the account is reloaded and the profile stands
+// for all of them. The reload changes the paths of the account, so it is
+// called wherever the session is used and never on the watcher goroutine.
+func (s *Session) changedFile(name string) (string, error) {
+	if name != savedir.IndexFile || !s.Account.Xbox() {
+		return name, nil
+	}
+	err := s.Account.Reload()
+	if err != nil {
+		return "", err
+	}
+	return profileFileName, nil
+}
+
+// watchSaves calls onWrite with the name of every save file the game writes
+// (profile.sav, save_N.sav, or the containers index of an Xbox account)
+// until the returned watcher is closed. Names go through changedFile before
+// a save is read.
+func watchSaves(account savedir.Account, onWrite func(name string)) (*fsnotify.Watcher, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				// Events carry the full path of the file.
+				fileName := filepath.Base(event.Name)
+
+				// Xbox saves are new blobs in container folders, the index
+				// is written last.
+				if account.Xbox() && fileName == savedir.IndexFile && event.Has(fsnotify.Write|fsnotify.Create) {
+					onWrite(fileName)
+					continue
+				}
+
+				if event.Has(fsnotify.Write) && strings.HasSuffix(fileName, ".sav") {
+					onWrite(fileName)
+				}
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				log.Println("error:", err)
+			}
+		}
+	}()
+
+	err = watcher.Add(account.Dir)
+	if err != nil {
+		watcher.Close()
+		return nil, err
+	}
+
+	return watcher, nil
+}
+
+func addOwnedByFlag(flags *flag.FlagSet) *string {
+	ownedBy := OwnedByCharacter
+	flags.Func("owned-by", fmt.Sprintf("count items as owned when the %s has them or when %s character has them (default %s)", OwnedByCharacter, OwnedByAny, OwnedByCharacter), func(value string) error {
+		if value != OwnedByCharacter && value != OwnedByAny {
+			return fmt.Errorf("must be %s or %s", OwnedByCharacter, OwnedByAny)
+		}
+		ownedBy = value
+		return nil
+	})
+	return &ownedBy
+}
+
+func addWorldFlag(flags *flag.FlagSet) *string {
+	return flags.String("world", WorldAdventure, fmt.Sprintf("world to show (%s or %s)", WorldAdventure, WorldCampaign))
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"refinder/savedir"
+	"sync"
+	"testing"
+	"time"
+	"unicode/utf16"
+)
+
+// writeXboxAccount writes an Xbox app account folder holding the given
+// saves, each in a container of its own with a single blob. Like the game,
+// a new generation writes new blobs and then the index.
+func writeXboxAccount(t *testing.T, dir string, generation byte, saves map[string][]byte) {
+	t.Helper()
+
+	var index bytes.Buffer
+	write := func(w *bytes.Buffer, data interface{}) {
+		binary.Write(w, binary.LittleEndian, data)
+	}
+	writeString := func(w *bytes.Buffer, value string) {
+		chars := utf16.Encode([]rune(value))
+		write(w, uint32(len(chars)))
+		write(w, chars)
+	}
+	guidName := func(guid [16]byte) string {
+		return fmt.Sprintf("%08X%04X%04X%X", binary.LittleEndian.Uint32(guid[0:4]),
+			binary.LittleEndian.Uint16(guid[4:6]), binary.LittleEndian.Uint16(guid[6:8]), guid[8:16])
+	}
+
+	write(&index, uint32(14))
+	write(&index, uint32(len(saves)))
+	write(&index, uint32(0))
+	writeString(&index, "PerfectWorldEntertainment.GFREMP2_jrajkyc4tsa6w!AppRemnant2Shipping")
+	write(&index, uint64(0))
+	write(&index, uint32(0))
+	writeString(&index, "0x0000000000000000")
+	write(&index, uint64(0))
+
+	i := byte(0)
+	for name, data := range saves {
+		i++
+		folder, blob := [16]byte{i, 1}, [16]byte{i, 2, generation}
+		writeString(&index, filepath.Base(name[:len(name)-len(filepath.Ext(name))]))
+		writeString(&index, name)
+		writeString(&index, `"0x8DB0000000000000"`)
+		write(&index, generation)
+		write(&index, uint32(0))
+		write(&index, folder)
+		write(&index, uint64(0))
+		write(&index, uint64(0))
+		write(&index, uint64(len(data)))
+
+		var container bytes.Buffer
+		write(&container, uint32(4))
+		write(&container, uint32(1))
+		chars := make([]uint16, 64)
+		copy(chars, utf16.Encode([]rune("Data")))
+		write(&container, chars)
+		write(&container, [2][16]byte{blob, blob})
+
+		folderPath := filepath.Join(dir, guidName(folder))
+		err := os.MkdirAll(folderPath, 0o755)
+		if err == nil {
+			err = os.WriteFile(filepath.Join(folderPath, fmt.Sprintf("container.%d", generation)), container.Bytes(), 0o644)
+		}
+		if err == nil {
+			err = os.WriteFile(filepath.Join(folderPath, guidName(blob)), data, 0o644)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	err := os.WriteFile(filepath.Join(dir, savedir.IndexFile), index.Bytes(), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestServerIndexWrite(t *testing.T) {
+	// The saves of a Steam account move into Xbox containers.
+	steam := t.TempDir()
+	writeCheckAccount(t, steam, "Nerud", nil)
+	saves := map[string][]byte{}
+	for _, name := range []string{profileFileName, saveFileName(0)} {
+		data, err := os.ReadFile(filepath.Join(steam, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		saves[name] = data
+	}
+	dir := t.TempDir()
+	writeXboxAccount(t, dir, 1, saves)
+
+	accounts, err := savedir.Accounts(dir)
+	if err != nil || len(accounts) != 1 || !accounts[0].Xbox() {
+		t.Fatalf("accounts = %+v, %v, want one Xbox account", accounts, err)
+	}
+	s := newServer(NewSession(accounts[0], WorldAdventure))
+	err = s.refreshAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	updates := s.subscribe()
+	defer s.unsubscribe(updates)
+
+	watcher, err := watchSaves(s.session.Account, s.onWrite)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer watcher.Close()
+
+	// Handlers resolve save paths while the index is written, go test -race
+	// reports a reload that is not synchronized with them.
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			recorder := httptest.NewRecorder()
+			s.routes().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/characters/0/worlds", nil))
+			if recorder.Code != http.StatusOK {
+				t.Errorf("status = %d: %s", recorder.Code, recorder.Body)
+				return
+			}
+		}
+	}()
+
+	writeXboxAccount(t, dir, 2, saves)
+	select {
+	case <-updates:
+	case <-time.After(5 * time.Second):
+		t.Error("no update after the index was written")
+	}
+	close(done)
+	wg.Wait()
+}
@@ -0,0 +1,687 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/term"
+)
+
+const (
+	keyUp        = "up"
+	keyDown      = "down"
+	keyLeft      = "left"
+	keyRight     = "right"
+	keyPageUp    = "pgup"
+	keyPageDown  = "pgdn"
+	keyHome      = "home"
+	keyEnd       = "end"
+	keyEnter     = "enter"
+	keyEscape    = "esc"
+	keyBackspace = "backspace"
+	keyTab       = "tab"
+	keyBackTab   = "backtab"
+	keyCtrlC     = "ctrl+c"
+)
+
+// parseKeys splits terminal input into key names, printable keys are
+// returned as themselves.
+func parseKeys(data []byte) []string {
+	var keys []string
+	for len(data) > 0 {
+		switch {
+		case data[0] == 27 && len(data) >= 3 && (data[1] == '[' || data[1] == 'O'):
+			sequence := map[byte]string{'A': keyUp, 'B': keyDown, 'C': keyRight, 'D': keyLeft, 'H': keyHome, 'F': keyEnd, 'Z': keyBackTab}
+			if key, ok := sequence[data[2]]; ok {
+				keys = append(keys, key)
+				data = data[3:]
+				continue
+			}
+			if len(data) >= 4 && data[3] == '~' {
+				switch data[2] {
+				case '1', '7':
+					keys = append(keys, keyHome)
+				case '4', '8':
+					keys = append(keys, keyEnd)
+				case '5':
+					keys = append(keys, keyPageUp)
+				case '6':
+					keys = append(keys, keyPageDown)
+				}
+				data = data[4:]
+				continue
+			}
+			// Unknown sequence, drop it.
+			data = data[3:]
+		case data[0] == 27:
+			keys = append(keys, keyEscape)
+			data = data[1:]
+		case data[0] == '\r' || data[0] == '\n':
+			keys = append(keys, keyEnter)
+			data = data[1:]
+		case data[0] == 127 || data[0] == 8:
+			keys = append(keys, keyBackspace)
+			data = data[1:]
+		case data[0] == '\t':
+			keys = append(keys, keyTab)
+			data = data[1:]
+		case data[0] == 3:
+			keys = append(keys, keyCtrlC)
+			data = data[1:]
+		case data[0] < 32:
+			data = data[1:]
+		default:
+			r, size := utf8.DecodeRune(data)
+			keys = append(keys, string(r))
+			data = data[size:]
+		}
+	}
+	return keys
+}
+
+func readKeys(r io.Reader, keys chan<- string) {
+	buf := make([]byte, 64)
+	for {
+		n, err := r.Read(buf)
+		if err != nil {
+			close(keys)
+			return
+		}
+		for _, key := range parseKeys(buf[:n]) {
+			keys <- key
+		}
+	}
+}
+
+// errNoCharacters is shown for a profile without characters, at start or
+// after the game rewrote it.
+var errNoCharacters = errors.New("the profile has no characters")
+
+// tuiRow is one line of the world tree.
+type tuiRow struct {
+	depth      int
+	key        string
+	text       string
+	expandable bool
+	expanded   bool
+	zone       *ZoneActor
+	item       *ItemData
+	event      *Event
+	reward     *LootSpawn
+}
+
+type tui struct {
+	session      *Session
+	characterIDs []int32
+	character    int
+	changes      map[int32]*WorldChanges
+
+	rows     []tuiRow
+	cursor   int
+	offset   int
+	expanded map[string]bool
+
+	filter        string
+	filtering     bool
+	hideOwned     bool
+	hideMaterials bool
+	status        string
+
+	text *TextRenderer
+}
+
+// characterID returns the selected character, -1 while the profile has
+// none.
+func (t *tui) characterID() int32 {
+	if len(t.characterIDs) == 0 {
+		return -1
+	}
+	return t.characterIDs[t.character]
+}
+
+// isExpanded returns the state of a node, zones start expanded and events
+// collapsed. A filter expands everything that matches.
+func (t *tui) isExpanded(key string, byDefault bool) bool {
+	if t.filter != "" {
+		return true
+	}
+	if expanded, ok := t.expanded[key]; ok {
+		return expanded
+	}
+	return byDefault
+}
+
+func (t *tui) matchesFilter(name string) bool {
+	return t.filter == "" || strings.Contains(strings.ToLower(getPrintableName(name)), strings.ToLower(t.filter))
+}
+
+func (t *tui) itemVisible(name string, owned bool) bool {
+	if t.hideOwned && owned {
+		return false
+	}
+	if t.hideMaterials && isMaterial(name) {
+		return false
+	}
+	return t.matchesFilter(name)
+}
+
+func (t *tui) eventVisible(event Event) bool {
+	if t.filter != "" && t.matchesFilter(event.Name) {
+		return true
+	}
+	if t.filter == "" && len(event.Rewards) == 0 {
+		return true
+	}
+	for _, reward := range event.Rewards {
+		if t.itemVisible(reward.ActorBP, reward.OwnedByCharacter) {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *tui) zoneVisible(zone *ZoneActor) bool {
+	if t.filter == "" {
+		return true
+	}
+	for _, item := range zone.Items {
+		if t.itemVisible(item.Name, item.OwnedByCharacter) {
+			return true
+		}
+	}
+	for _, event := range zone.Events {
+		if t.eventVisible(event) {
+			return true
+		}
+	}
+	for _, child := range zone.Children {
+		if t.zoneVisible(child) {
+			return true
+		}
+	}
+	return false
+}
+
+func expandMark(expanded bool) string {
+	if expanded {
+		return "▾"
+	}
+	return "▸"
+}
+
+func (t *tui) buildRows() {
+	t.rows = t.rows[:0]
+	if zoneInfo, ok := t.session.Zones[t.characterID()]; ok && zoneInfo.ZoneActor != nil {
+		t.addZone(zoneInfo.ZoneActor, 0)
+	}
+	t.cursor = max(0, min(t.cursor, len(t.rows)-1))
+}
+
+func (t *tui) addZone(zone *ZoneActor, depth int) {
+	if !t.zoneVisible(zone) {
+		return
+	}
+
+	key := zoneChangeKey(zone)
+	expanded := t.isExpanded(key, true)
+	t.rows = append(t.rows, tuiRow{depth: depth, key: key, text: expandMark(expanded) + " " + zone.Label, expandable: true, expanded: expanded, zone: zone})
+	if !expanded {
+		return
+	}
+
+	if t.filter == "" {
+		for _, link := range zone.ZoneLinks {
+			if link.Type == "EZoneLinkType::Waypoint" {
+				t.rows = append(t.rows, tuiRow{depth: depth + 1, text: "[Waypoint] " + link.Label, zone: zone})
+			}
+		}
+	}
+	for i := range zone.Items {
+		item := &zone.Items[i]
+		if !t.itemVisible(item.Name, item.OwnedByCharacter) {
+			continue
+		}
+		text := fmt.Sprintf("[Item] %s x%d %s", t.text.ownedMark(item.OwnedByCharacter), item.Quantity, getPrintableName(item.Name))
+		if isMaterial(item.Name) {
+			text = fmt.Sprintf("[Material] x%d %s", item.Quantity, getPrintableName(item.Name))
+		}
+		t.rows = append(t.rows, tuiRow{depth: depth + 1, key: itemChangeKey(zone, *item), text: text, zone: zone, item: item})
+	}
+	for i := range zone.Events {
+		event := &zone.Events[i]
+		if !t.eventVisible(*event) {
+			continue
+		}
+		key := eventChangeKey(zone, *event)
+		expanded := t.isExpanded(key, false)
+		owned, total := rewardCounts(*event)
+		text := fmt.Sprintf("%s [%s] %s (%d/%d, %s)", expandMark(expanded), event.Kind.Label(), getItemName(event.Name), owned, total, event.Status)
+		t.rows = append(t.rows, tuiRow{depth: depth + 1, key: key, text: text, expandable: len(event.Rewards) > 0, expanded: expanded, zone: zone, event: event})
+		if !expanded {
+			continue
+		}
+		for j := range event.Rewards {
+			reward := &event.Rewards[j]
+			if !t.itemVisible(reward.ActorBP, reward.OwnedByCharacter) {
+				continue
+			}
+			text := fmt.Sprintf("[Reward] %s x%d %s", t.text.ownedMark(reward.OwnedByCharacter), reward.Quantity, getPrintableName(reward.ActorBP))
+			t.rows = append(t.rows, tuiRow{depth: depth + 2, key: rewardChangeKey(zone, *event, *reward), text: text, zone: zone, event: event, reward: reward})
+		}
+	}
+
+	for _, child := range zone.Children {
+		t.addZone(child, depth+1)
+	}
+}
+
+func rewardCounts(event Event) (int, int) {
+	owned := 0
+	for _, reward := range event.Rewards {
+		if reward.OwnedByCharacter {
+			owned++
+		}
+	}
+	return owned, len(event.Rewards)
+}
+
+// details describes the selected row below the tree.
+func (t *tui) details() []string {
+	if len(t.rows) == 0 {
+		return nil
+	}
+	row := t.rows[t.cursor]
+
+	switch {
+	case row.event != nil:
+		owned, total := rewardCounts(*row.event)
+		lines := []string{fmt.Sprintf("%s in %s, %d of %d rewards owned", getPrintableName(row.event.Name), row.zone.Label, owned, total)}
+		for _, reward := range row.event.Rewards {
+			lines = append(lines, fmt.Sprintf("  %s x%d %s", t.text.ownedMark(reward.OwnedByCharacter), reward.Quantity, getPrintableName(reward.ActorBP)))
+		}
+		return lines
+	case row.item != nil:
+		return []string{fmt.Sprintf("%s x%d in %s", getPrintableName(row.item.Name), row.item.Quantity, row.zone.Label)}
+	case row.zone != nil:
+		items, missing, events := 0, 0, len(row.zone.Events)
+		for _, item := range row.zone.Items {
+			if !isMaterial(item.Name) {
+				items++
+				if !item.OwnedByCharacter {
+					missing++
+				}
+			}
+		}
+		for _, event := range row.zone.Events {
+			owned, total := rewardCounts(event)
+			items += total
+			missing += total - owned
+		}
+		return []string{fmt.Sprintf("%s: %d events, %d items and rewards, %d missing", row.zone.Label, events, items, missing)}
+	}
+	return nil
+}
+
+func (t *tui) header() string {
+	if len(t.characterIDs) == 0 {
+		return "No characters"
+	}
+	characterData := t.session.Characters[t.characterID()]
+	zoneInfo := t.session.Zones[t.characterID()]
+	header := fmt.Sprintf("Character %d (%d/%d): %s, %s | %s: %s",
+		characterData.ID, t.character+1, len(t.characterIDs), characterData.Archetype, getCharacterTypeName(characterData.Type),
+		t.session.World, getBiomeName(zoneInfo.Biome))
+	if zoneInfo.BloodMoon {
+		header += " | Blood Moon"
+	}
+	return header
+}
+
+func (t *tui) footer() string {
+	if t.filtering {
+		return "Filter: " + t.filter + "█"
+	}
+	var toggles []string
+	if t.filter != "" {
+		toggles = append(toggles, fmt.Sprintf("filter %q", t.filter))
+	}
+	if t.hideOwned {
+		toggles = append(toggles, "owned hidden")
+	}
+	if t.hideMaterials {
+		toggles = append(toggles, "materials hidden")
+	}
+	footer := "↑↓ move  ←→ collapse/expand  tab character  w world  / filter  o owned  m materials  q quit"
+	if len(toggles) > 0 {
+		footer = strings.Join(toggles, ", ") + " | " + footer
+	}
+	if t.status != "" {
+		footer = t.status + " | " + footer
+	}
+	return footer
+}
+
+// fit cuts a line to width runes, escape sequences are added afterwards.
+func fit(line string, width int) string {
+	if utf8.RuneCountInString(line) <= width {
+		return line
+	}
+	runes := []rune(line)
+	return string(runes[:max(0, width)])
+}
+
+func (t *tui) draw(w io.Writer) {
+	width, height, err := term.GetSize(int(os.Stdout.Fd()))
+	if err != nil {
+		width, height = 80, 24
+	}
+
+	details := t.details()
+	treeHeight := max(1, height-len(details)-4)
+	if t.cursor < t.offset {
+		t.offset = t.cursor
+	}
+	if t.cursor >= t.offset+treeHeight {
+		t.offset = t.cursor - treeHeight + 1
+	}
+
+	changes := t.changes[t.characterID()]
+
+	var buf bytes.Buffer
+	buf.WriteString("\033[H\033[2J")
+	fmt.Fprintf(&buf, "\033[1m%s\033[0m\r\n", fit(t.header(), width))
+	if changes != nil && len(changes.Summary) > 0 {
+		fmt.Fprintf(&buf, "%s\r\n", fit(changes.Summary[0], width))
+	} else {
+		buf.WriteString("\r\n")
+	}
+
+	for i := t.offset; i < len(t.rows) && i < t.offset+treeHeight; i++ {
+		row := t.rows[i]
+		line := fit(strings.Repeat("  ", row.depth)+row.text, width)
+		switch {
+		case i == t.cursor:
+			line = "\033[7m" + line + "\033[0m"
+		case changes.Mark(row.key) == ChangeAdded:
+			line = t.text.Added + line + t.text.Reset
+		case changes.Mark(row.key) == ChangeModified:
+			line = t.text.Modified + line + t.text.Reset
+		}
+		buf.WriteString(line + "\r\n")
+	}
+	if len(t.rows) == 0 {
+		buf.WriteString("Nothing to show\r\n")
+	}
+
+	fmt.Fprintf(&buf, "\033[%d;1H", height-len(details)-1)
+	for _, line := range details {
+		buf.WriteString(fit(line, width) + "\r\n")
+	}
+	fmt.Fprintf(&buf, "\033[2m%s\033[0m", fit(t.footer(), width))
+
+	buf.WriteTo(w)
+}
+
+// loadCharacter reads the world of the selected character if it was not read
+// yet.
+func (t *tui) loadCharacter() {
+	if len(t.characterIDs) == 0 {
+		return
+	}
+	if _, ok := t.session.Zones[t.characterID()]; ok {
+		return
+	}
+	_, err := t.session.RefreshCharacter(t.characterID())
+	if err != nil {
+		t.status = err.Error()
+	}
+}
+
+func (t *tui) switchCharacter(step int) {
+	if len(t.characterIDs) == 0 {
+		return
+	}
+	t.character = (t.character + step + len(t.characterIDs)) % len(t.characterIDs)
+	t.cursor, t.offset = 0, 0
+	t.loadCharacter()
+}
+
+func (t *tui) switchWorld() {
+	if t.session.World == WorldAdventure {
+		t.session.World = WorldCampaign
+	} else {
+		t.session.World = WorldAdventure
+	}
+	t.session.Zones = map[int32]ZoneInfo{}
+	t.changes = map[int32]*WorldChanges{}
+	t.cursor, t.offset = 0, 0
+	t.loadCharacter()
+}
+
+// onWrite reads a save the game wrote, the selected character is kept even
+// if the active one changes.
+func (t *tui) onWrite(name string) {
+	t.status = ""
+	selected := t.characterID()
+
+	name, err := t.session.changedFile(name)
+	if err != nil {
+		t.status = err.Error()
+		return
+	}
+
+	if name == profileFileName {
+		err := t.session.RefreshProfile()
+		if err != nil {
+			t.status = err.Error()
+			return
+		}
+		t.characterIDs = t.session.CharacterIDs()
+		t.character = 0
+		if len(t.characterIDs) == 0 {
+			t.status = errNoCharacters.Error()
+			return
+		}
+		for i, characterID := range t.characterIDs {
+			if characterID == selected {
+				t.character = i
+			}
+		}
+		// Ownership lives in the profile, every world that was read has
+		// to be matched against the new inventory.
+		for characterID := range t.session.Zones {
+			t.refresh(characterID)
+		}
+		t.loadCharacter()
+		return
+	}
+
+	if characterID, ok := parseSaveFileName(name); ok {
+		if _, loaded := t.session.Zones[characterID]; loaded {
+			t.refresh(characterID)
+		}
+	}
+}
+
+func (t *tui) refresh(characterID int32) {
+	changes, err := t.session.RefreshCharacter(characterID)
+	if err != nil {
+		t.status = err.Error()
+		return
+	}
+	t.changes[characterID] = changes
+}
+
+// handleKey applies a key press, it returns false to quit.
+func (t *tui) handleKey(key string) bool {
+	if t.filtering {
+		switch key {
+		case keyEnter:
+			t.filtering = false
+		case keyEscape:
+			t.filtering = false
+			t.filter = ""
+		case keyBackspace:
+			if t.filter != "" {
+				_, size := utf8.DecodeLastRuneInString(t.filter)
+				t.filter = t.filter[:len(t.filter)-size]
+			}
+		case keyCtrlC:
+			return false
+		default:
+			if utf8.RuneCountInString(key) == 1 {
+				t.filter += key
+			}
+		}
+		t.cursor, t.offset = 0, 0
+		return true
+	}
+
+	page := 10
+	switch key {
+	case "q", keyCtrlC:
+		return false
+	case keyUp, "k":
+		t.cursor--
+	case keyDown, "j":
+		t.cursor++
+	case keyPageUp:
+		t.cursor -= page
+	case keyPageDown:
+		t.cursor += page
+	case keyHome, "g":
+		t.cursor = 0
+	case keyEnd, "G":
+		t.cursor = len(t.rows) - 1
+	case keyRight, "l", keyEnter, " ":
+		if len(t.rows) > 0 && t.rows[t.cursor].expandable {
+			row := t.rows[t.cursor]
+			if key == keyRight || key == "l" {
+				t.expanded[row.key] = true
+			} else {
+				t.expanded[row.key] = !row.expanded
+			}
+		}
+	case keyLeft, "h":
+		if len(t.rows) == 0 {
+			break
+		}
+		row := t.rows[t.cursor]
+		if row.expandable && row.expanded {
+			t.expanded[row.key] = false
+			break
+		}
+		// Jump to the parent node.
+		for i := t.cursor - 1; i >= 0; i-- {
+			if t.rows[i].depth < row.depth {
+				t.cursor = i
+				break
+			}
+		}
+	case keyTab, "c":
+		t.switchCharacter(1)
+	case keyBackTab, "C":
+		t.switchCharacter(-1)
+	case "w":
+		t.switchWorld()
+	case "/":
+		t.filtering = true
+	case keyEscape:
+		t.filter = ""
+	case "o":
+		t.hideOwned = !t.hideOwned
+	case "m":
+		t.hideMaterials = !t.hideMaterials
+	}
+	return true
+}
+
+func runTUI(args []string) error {
+	flags := flag.NewFlagSet("tui", flag.ExitOnError)
+	saveDir := addSaveDirFlag(flags)
+	addLangFlag(flags)
+	world := addWorldFlag(flags)
+	ownedBy := addOwnedByFlag(flags)
+	positional, err := parseCommandArgs(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 0 {
+		return fmt.Errorf("usage: %s", commands["tui"].Usage)
+	}
+	if !term.IsTerminal(int(os.Stdin.Fd())) || !term.IsTerminal(int(os.Stdout.Fd())) {
+		return fmt.Errorf("tui needs an interactive terminal")
+	}
+
+	account, err := selectAccount(*saveDir)
+	if err != nil {
+		return err
+	}
+	session := NewSession(account, *world)
+	session.OwnedBy = *ownedBy
+	err = session.RefreshProfile()
+	if err != nil {
+		return err
+	}
+
+	t := &tui{
+		session:      session,
+		characterIDs: session.CharacterIDs(),
+		changes:      map[int32]*WorldChanges{},
+		expanded:     map[string]bool{},
+		text:         NewTextRenderer(),
+	}
+	if len(t.characterIDs) == 0 {
+		return errNoCharacters
+	}
+	for i, characterID := range t.characterIDs {
+		if characterID == session.ActiveCharacterID {
+			t.character = i
+		}
+	}
+	t.loadCharacter()
+
+	// The watcher only reports names, saves are read on the UI goroutine so
+	// the session is never touched concurrently.
+	writes := make(chan string, 16)
+	watcher, err := watchSaves(session.Account, func(name string) {
+		writes <- name
+	})
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+
+	state, err := term.MakeRaw(int(os.Stdin.Fd()))
+	if err != nil {
+		return err
+	}
+	defer term.Restore(int(os.Stdin.Fd()), state)
+
+	// Alternate screen, hidden cursor.
+	fmt.Print("\033[?1049h\033[?25l")
+	defer fmt.Print("\033[?25h\033[?1049l")
+
+	keys := make(chan string)
+	go readKeys(os.Stdin, keys)
+
+	for {
+		t.buildRows()
+		t.draw(os.Stdout)
+
+		select {
+		case key, ok := <-keys:
+			if !ok || !t.handleKey(key) {
+				return nil
+			}
+		case name := <-writes:
+			t.onWrite(name)
+		}
+	}
+}
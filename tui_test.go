@@ -0,0 +1,98 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"refinder/remnant"
+	"refinder/savedir"
+	"reflect"
+	"testing"
+)
+
+func TestParseKeys(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		keys []string
+	}{
+		{"arrows", "\033[A\033[B\033[C\033[D", []string{keyUp, keyDown, keyRight, keyLeft}},
+		{"application mode arrows", "\033OA\033OB", []string{keyUp, keyDown}},
+		{"home and end", "\033[H\033[F\033[1~\033[4~\033[7~\033[8~", []string{keyHome, keyEnd, keyHome, keyEnd, keyHome, keyEnd}},
+		{"pages", "\033[5~\033[6~", []string{keyPageUp, keyPageDown}},
+		{"back tab", "\033[Z", []string{keyBackTab}},
+		{"unknown sequence", "\033[Xq", []string{"q"}},
+		{"escape", "\033", []string{keyEscape}},
+		{"control keys", "\r\n\t\x7f\x08\x03", []string{keyEnter, keyEnter, keyTab, keyBackspace, keyBackspace, keyCtrlC}},
+		{"other control keys", "\x01a", []string{"a"}},
+		{"utf-8", "ä/", []string{"ä", "/"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if keys := parseKeys([]byte(test.data)); !reflect.DeepEqual(keys, test.keys) {
+				t.Errorf("parseKeys(%q) = %q, want %q", test.data, keys, test.keys)
+			}
+		})
+	}
+}
+
+func newTestTUI(t *testing.T, dir string) *tui {
+	t.Helper()
+	accounts, err := savedir.Accounts(dir)
+	if err != nil || len(accounts) != 1 {
+		t.Fatalf("accounts = %+v, %v, want one", accounts, err)
+	}
+	session := NewSession(accounts[0], WorldAdventure)
+	err = session.RefreshProfile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ui := &tui{
+		session:      session,
+		characterIDs: session.CharacterIDs(),
+		changes:      map[int32]*WorldChanges{},
+		expanded:     map[string]bool{},
+		text:         NewTextRenderer(),
+	}
+	ui.loadCharacter()
+	return ui
+}
+
+func TestTUIOnWrite(t *testing.T) {
+	dir := t.TempDir()
+	writeCheckAccount(t, dir, "Nerud", nil)
+	ui := newTestTUI(t, dir)
+	if ui.characterID() != 0 || ui.status != "" {
+		t.Fatalf("character %d, status %q, want character 0", ui.characterID(), ui.status)
+	}
+
+	// A new roll of the selected character is read and diffed.
+	writeCheckAccount(t, dir, "Jungle", nil)
+	ui.onWrite(saveFileName(0))
+	if ui.status != "" || ui.session.Zones[0].Biome != "Jungle" {
+		t.Errorf("status %q, biome %s, want the new roll", ui.status, ui.session.Zones[0].Biome)
+	}
+	if ui.changes[0] == nil || len(ui.changes[0].Summary) == 0 {
+		t.Error("no changes for the new roll")
+	}
+
+	// The game rewrites the profile without characters.
+	writeTestArchive(t, filepath.Join(dir, profileFileName), testSaveArchive(remnant.REMNANT_SAVE_GAME_PROFILE))
+	ui.onWrite(profileFileName)
+	if ui.status != errNoCharacters.Error() {
+		t.Errorf("status %q, want %q", ui.status, errNoCharacters)
+	}
+	ui.onWrite(saveFileName(0))
+	ui.handleKey(keyTab)
+	ui.buildRows()
+	ui.draw(&bytes.Buffer{})
+	if header := ui.header(); header != "No characters" {
+		t.Errorf("header %q, want No characters", header)
+	}
+
+	// The character comes back with the next write of the profile.
+	writeCheckAccount(t, dir, "Nerud", nil)
+	ui.onWrite(profileFileName)
+	if ui.status != "" || ui.characterID() != 0 {
+		t.Errorf("character %d, status %q, want character 0 back", ui.characterID(), ui.status)
+	}
+}
@@ -5,6 +5,10 @@ import (
 	"encoding/binary"
 	"io"
 	"refinder/memory"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
 )
 
 type FTopLevelAssetPath struct {
@@ -12,14 +16,24 @@ type FTopLevelAssetPath struct {
 	Name string
 }
 
+// ReadFString reads a length-prefixed, null-terminated string. A negative
+// length marks a UTF-16 string of that many characters.
 func ReadFString(r io.Reader) (string, error) {
 	stringSize, err := memory.ReadInt[int32](r)
 	if err != nil {
 		return "", err
 	}
-	if stringSize <= 0 {
+	if stringSize == 0 {
 		return "", nil
 	}
+	if stringSize < 0 {
+		stringData := make([]uint16, -int64(stringSize))
+		err = binary.Read(r, binary.LittleEndian, stringData)
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimRight(string(utf16.Decode(stringData)), "\x00"), nil
+	}
 	stringData := make([]byte, stringSize)
 	err = binary.Read(r, binary.LittleEndian, &stringData)
 	if err != nil {
@@ -28,6 +42,45 @@ func ReadFString(r io.Reader) (string, error) {
 	return string(bytes.Trim(stringData, "\x00")), nil
 }
 
+// WriteFString writes a null-terminated string, an empty string is written
+// as a zero length. Like Unreal, strings with characters outside of ASCII
+// are written as UTF-16.
+func WriteFString(w io.Writer, value string) error {
+	if value == "" {
+		return memory.WriteInt[int32](w, 0)
+	}
+
+	if isWide(value) {
+		stringData := append(utf16.Encode([]rune(value)), 0)
+		err := memory.WriteInt[int32](w, -int32(len(stringData)))
+		if err != nil {
+			return err
+		}
+		return binary.Write(w, binary.LittleEndian, stringData)
+	}
+
+	err := memory.WriteInt[int32](w, int32(len(value)+1))
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append([]byte(value), 0))
+	return err
+}
+
+// isWide tells whether a string needs UTF-16. Bytes that are not UTF-8 came
+// from a single byte string and are written back as they are.
+func isWide(value string) bool {
+	if !utf8.ValidString(value) {
+		return false
+	}
+	for _, char := range value {
+		if char > unicode.MaxASCII {
+			return true
+		}
+	}
+	return false
+}
+
 type FName struct {
 	Index  uint16
 	Number int32
@@ -75,6 +128,10 @@ func ReadGuid(r io.Reader) (FGuid, error) {
 	return guidData, nil
 }
 
+func WriteGuid(w io.Writer, guid FGuid) error {
+	return binary.Write(w, binary.LittleEndian, guid)
+}
+
 type FInfo struct {
 	UniqueID uint64
 	Offset   uint32
@@ -91,6 +148,10 @@ func ReadFInfo(r io.Reader) (FInfo, error) {
 	return info, nil
 }
 
+func WriteFInfo(w io.Writer, info FInfo) error {
+	return binary.Write(w, binary.LittleEndian, info)
+}
+
 type FVector struct {
 	X float64
 	Y float64
@@ -107,6 +168,10 @@ func ReadFVector(r io.Reader) (FVector, error) {
 	return vector, nil
 }
 
+func WriteFVector(w io.Writer, vector FVector) error {
+	return binary.Write(w, binary.LittleEndian, vector)
+}
+
 type FQuaternion struct {
 	X float64
 	Y float64
@@ -140,6 +205,10 @@ func ReadFTransform(r io.Reader) (FTransform, error) {
 	return transform, nil
 }
 
+func WriteFTransform(w io.Writer, transform FTransform) error {
+	return binary.Write(w, binary.LittleEndian, transform)
+}
+
 func ReadFTopLevelAssetPath(r io.Reader) (FTopLevelAssetPath, error) {
 	topLevelAssetPath := FTopLevelAssetPath{}
 	var err error
@@ -156,3 +225,12 @@ func ReadFTopLevelAssetPath(r io.Reader) (FTopLevelAssetPath, error) {
 
 	return topLevelAssetPath, nil
 }
+
+func WriteFTopLevelAssetPath(w io.Writer, topLevelAssetPath FTopLevelAssetPath) error {
+	err := WriteFString(w, topLevelAssetPath.Path)
+	if err != nil {
+		return err
+	}
+
+	return WriteFString(w, topLevelAssetPath.Name)
+}
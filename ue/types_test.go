@@ -0,0 +1,36 @@
+package ue
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestFString(t *testing.T) {
+	tests := []struct {
+		value   string
+		encoded []byte
+	}{
+		{"", []byte{0, 0, 0, 0}},
+		{"Ab", []byte{3, 0, 0, 0, 'A', 'b', 0}},
+		{"Aü", []byte{0xfd, 0xff, 0xff, 0xff, 'A', 0, 0xfc, 0, 0, 0}},
+		{"\xe9t\xe9", []byte{4, 0, 0, 0, 0xe9, 't', 0xe9, 0}},
+	}
+	for _, test := range tests {
+		var buf bytes.Buffer
+		err := WriteFString(&buf, test.value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(buf.Bytes(), test.encoded) {
+			t.Errorf("WriteFString(%q) = %v, want %v", test.value, buf.Bytes(), test.encoded)
+		}
+
+		value, err := ReadFString(bytes.NewReader(test.encoded))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if value != test.value {
+			t.Errorf("ReadFString(%v) = %q, want %q", test.encoded, value, test.value)
+		}
+	}
+}
@@ -0,0 +1,291 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Webhooks are told when a character rolls a new world, that is when the
+// biome or the set of zones differs from the previous read of the save.
+// Discord webhook URLs get an embed, every other URL the generic JSON.
+
+const (
+	WebhookAuto    = "auto"
+	WebhookJSON    = "json"
+	WebhookDiscord = "discord"
+)
+
+var webhookFormats = []string{WebhookAuto, WebhookJSON, WebhookDiscord}
+
+// webhookURLs collects every --webhook flag.
+type webhookURLs []string
+
+func (urls *webhookURLs) String() string {
+	return strings.Join(*urls, ",")
+}
+
+func (urls *webhookURLs) Set(value string) error {
+	u, err := url.Parse(value)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid webhook URL %q", value)
+	}
+	*urls = append(*urls, value)
+	return nil
+}
+
+func isDiscordWebhook(webhookURL string) bool {
+	u, err := url.Parse(webhookURL)
+	if err != nil {
+		return false
+	}
+	host := strings.TrimPrefix(u.Hostname(), "www.")
+	return (host == "discord.com" || host == "discordapp.com" || strings.HasSuffix(host, ".discord.com")) &&
+		strings.HasPrefix(u.Path, "/api/webhooks/")
+}
+
+// zoneLabels returns the sorted labels of all zones in the tree.
+func zoneLabels(zone *ZoneActor) []string {
+	var labels []string
+	var collect func(zone *ZoneActor)
+	collect = func(zone *ZoneActor) {
+		if zone == nil {
+			return
+		}
+		labels = append(labels, zone.Label)
+		for _, child := range zone.Children {
+			collect(child)
+		}
+	}
+	collect(zone)
+	slices.Sort(labels)
+	return labels
+}
+
+// rollChanged reports whether the current world is a new roll of the
+// previous one.
+func rollChanged(previous, current ZoneInfo) bool {
+	return previous.Biome != current.Biome || !slices.Equal(zoneLabels(previous.ZoneActor), zoneLabels(current.ZoneActor))
+}
+
+// webhookPayload is the generic JSON body. It carries the same facts as the
+// stream overlay plus the zones of the roll.
+type webhookPayload struct {
+	Event         string   `json:"event"`
+	World         string   `json:"world"`
+	PreviousBiome string   `json:"previous_biome"`
+	Zones         []string `json:"zones"`
+	overlayData
+	Time time.Time `json:"time"`
+}
+
+func newWebhookPayload(characterData CharacterData, previous, current ZoneInfo) webhookPayload {
+	zones := zoneLabels(current.ZoneActor)
+	if zones == nil {
+		zones = []string{}
+	}
+	return webhookPayload{
+		Event:         "roll",
+		World:         current.Mode,
+		PreviousBiome: getBiomeName(previous.Biome),
+		Zones:         zones,
+		overlayData:   newOverlayData(characterData, current),
+		Time:          time.Now().UTC(),
+	}
+}
+
+type discordField struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Inline bool   `json:"inline,omitempty"`
+}
+
+type discordEmbed struct {
+	Title       string         `json:"title"`
+	Description string         `json:"description,omitempty"`
+	Color       int            `json:"color"`
+	Fields      []discordField `json:"fields,omitempty"`
+	Timestamp   string         `json:"timestamp"`
+}
+
+type discordMessage struct {
+	Username string         `json:"username"`
+	Embeds   []discordEmbed `json:"embeds"`
+}
+
+// Discord rejects embed fields longer than this.
+const discordFieldLimit = 1024
+
+const (
+	discordColor          = 0x5865f2
+	discordBloodMoonColor = 0xcc2020
+)
+
+// discordFieldValue joins lines into a field value, lines that do not fit
+// are counted instead.
+func discordFieldValue(lines []string) string {
+	var value strings.Builder
+	for i, line := range lines {
+		// Keep room for the count of the remaining lines.
+		if value.Len()+len(line) > discordFieldLimit-len("... and 0000 more") {
+			fmt.Fprintf(&value, "... and %d more", len(lines)-i)
+			break
+		}
+		value.WriteString(line + "\n")
+	}
+	return strings.TrimSuffix(value.String(), "\n")
+}
+
+func newDiscordMessage(payload webhookPayload) discordMessage {
+	embed := discordEmbed{
+		Title:       fmt.Sprintf("New %s roll: %s", payload.World, payload.Biome),
+		Description: fmt.Sprintf("Character %d (%s), was %s", payload.Character, payload.Archetype, payload.PreviousBiome),
+		Color:       discordColor,
+		Timestamp:   payload.Time.Format(time.RFC3339),
+	}
+	if payload.BloodMoon {
+		embed.Title += " (Blood Moon)"
+		embed.Color = discordBloodMoonColor
+	}
+
+	eventLines := func(events []overlayEvent) []string {
+		var lines []string
+		for _, event := range events {
+			lines = append(lines, fmt.Sprintf("%s (%s)", event.Name, event.Zone))
+		}
+		return lines
+	}
+	var missing []string
+	for _, item := range payload.Missing {
+		missing = append(missing, fmt.Sprintf("%s (%s)", item.Name, item.Zone))
+	}
+
+	for _, field := range []struct {
+		name   string
+		lines  []string
+		inline bool
+	}{
+		{"Zones", payload.Zones, false},
+		{"Bosses", eventLines(payload.Bosses), true},
+		{"Dungeons", eventLines(payload.Dungeons), true},
+		{"Missing", missing, false},
+	} {
+		if len(field.lines) > 0 {
+			embed.Fields = append(embed.Fields, discordField{Name: field.name, Value: discordFieldValue(field.lines), Inline: field.inline})
+		}
+	}
+
+	return discordMessage{Username: "ReFinder", Embeds: []discordEmbed{embed}}
+}
+
+// webhookNotifier posts roll changes to every configured URL. Failed
+// requests are retried with exponential backoff in the background.
+type webhookNotifier struct {
+	urls    []string
+	format  string
+	client  *http.Client
+	retries int
+	backoff time.Duration
+}
+
+func newWebhookNotifier(urls []string, format string) (*webhookNotifier, error) {
+	if !slices.Contains(webhookFormats, format) {
+		return nil, fmt.Errorf("unknown webhook format %q (supported: %s)", format, strings.Join(webhookFormats, ", "))
+	}
+	if len(urls) == 0 {
+		return nil, nil
+	}
+	return &webhookNotifier{
+		urls:    urls,
+		format:  format,
+		client:  &http.Client{Timeout: 10 * time.Second},
+		retries: 5,
+		backoff: time.Second,
+	}, nil
+}
+
+func (n *webhookNotifier) body(webhookURL string, payload webhookPayload) ([]byte, error) {
+	if n.format == WebhookDiscord || n.format == WebhookAuto && isDiscordWebhook(webhookURL) {
+		return json.Marshal(newDiscordMessage(payload))
+	}
+	return json.Marshal(payload)
+}
+
+// Notify posts the roll of a character if it differs from the previous one,
+// it does not wait for the requests.
+func (n *webhookNotifier) Notify(characterData CharacterData, previous, current ZoneInfo) {
+	if n == nil || !rollChanged(previous, current) {
+		return
+	}
+
+	payload := newWebhookPayload(characterData, previous, current)
+	for _, webhookURL := range n.urls {
+		body, err := n.body(webhookURL, payload)
+		if err != nil {
+			log.Println("webhook:", err)
+			continue
+		}
+		go func() {
+			err := n.post(webhookURL, body)
+			if err != nil {
+				log.Println("webhook:", err)
+			}
+		}()
+	}
+}
+
+// redactURL returns a webhook URL for logs. The path of a Discord webhook
+// holds its token, only the host is kept.
+func redactURL(webhookURL string) string {
+	u, err := url.Parse(webhookURL)
+	if err != nil || u.Host == "" {
+		return "webhook"
+	}
+	return u.Scheme + "://" + u.Host + "/..."
+}
+
+// post sends one request, retrying on network errors, rate limits and
+// server errors. Retry-After is honored when the server sends it. The
+// error is that of the last attempt.
+func (n *webhookNotifier) post(webhookURL string, body []byte) error {
+	backoff := n.backoff
+	var err error
+	for attempt := 0; attempt <= n.retries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+
+		var resp *http.Response
+		resp, err = n.client.Post(webhookURL, "application/json", bytes.NewReader(body))
+		if err != nil {
+			var urlErr *url.Error
+			if errors.As(err, &urlErr) {
+				urlErr.URL = redactURL(webhookURL)
+			}
+			continue
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode < 300 {
+			return nil
+		}
+		err = fmt.Errorf("%s: %s", redactURL(webhookURL), resp.Status)
+		if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode < 500 {
+			break
+		}
+		if seconds, parseErr := strconv.ParseFloat(resp.Header.Get("Retry-After"), 64); parseErr == nil && seconds > 0 {
+			backoff = time.Duration(seconds * float64(time.Second))
+		}
+	}
+
+	return err
+}
@@ -0,0 +1,275 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func testRoll() (CharacterData, ZoneInfo, ZoneInfo) {
+	characterData := CharacterData{ID: 2, Archetype: "Hunter / Medic"}
+	previous := ZoneInfo{Mode: WorldAdventure, Biome: "Jungle", ZoneActor: &ZoneActor{Label: "Old"}}
+	current := ZoneInfo{Mode: WorldAdventure, Biome: "Fae", BloodMoon: true, ZoneActor: &ZoneActor{
+		Label: "Forgotten Commune",
+		Items: []ItemData{{Name: "Weapon_Nightfall_C"}, {Name: "Material_Scrap_C"}},
+	}}
+	return characterData, previous, current
+}
+
+func TestIsDiscordWebhook(t *testing.T) {
+	tests := []struct {
+		url     string
+		discord bool
+	}{
+		{"https://discord.com/api/webhooks/1/token", true},
+		{"https://www.discordapp.com/api/webhooks/1/token", true},
+		{"https://ptb.discord.com/api/webhooks/1/token", true},
+		{"https://discord.com/channels/1", false},
+		{"https://notdiscord.com/api/webhooks/1/token", false},
+		{"https://example.com/hook", false},
+	}
+	for _, test := range tests {
+		if discord := isDiscordWebhook(test.url); discord != test.discord {
+			t.Errorf("isDiscordWebhook(%q) = %t, want %t", test.url, discord, test.discord)
+		}
+	}
+}
+
+func TestDiscordFieldValue(t *testing.T) {
+	long := make([]string, 100)
+	for i := range long {
+		long[i] = strings.Repeat("x", 30)
+	}
+
+	tests := []struct {
+		name   string
+		lines  []string
+		value  string
+		suffix string
+	}{
+		{"empty", nil, "", ""},
+		{"lines", []string{"a", "b"}, "a\nb", ""},
+		{"truncated", long, "", "... and 68 more"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			value := discordFieldValue(test.lines)
+			if len(value) > discordFieldLimit {
+				t.Errorf("value has %d bytes, more than %d", len(value), discordFieldLimit)
+			}
+			if test.suffix != "" {
+				if !strings.HasSuffix(value, test.suffix) {
+					t.Errorf("value ends with %q, want %q", value[max(0, len(value)-20):], test.suffix)
+				}
+				return
+			}
+			if value != test.value {
+				t.Errorf("value = %q, want %q", value, test.value)
+			}
+		})
+	}
+}
+
+// webhookServer records the bodies posted to it and answers with the given
+// statuses in turn, the last one repeats.
+type webhookServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	statuses []int
+	header   http.Header
+	bodies   [][]byte
+	posted   chan struct{}
+}
+
+func newWebhookServer(t *testing.T, header http.Header, statuses ...int) *webhookServer {
+	server := &webhookServer{statuses: statuses, header: header, posted: make(chan struct{}, 10)}
+	server.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		server.mu.Lock()
+		status := server.statuses[min(len(server.bodies), len(server.statuses)-1)]
+		server.bodies = append(server.bodies, body)
+		server.mu.Unlock()
+		for key, values := range server.header {
+			w.Header()[key] = values
+		}
+		w.WriteHeader(status)
+		server.posted <- struct{}{}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func (s *webhookServer) requests() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.bodies)
+}
+
+func TestWebhookPost(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []int
+		header   http.Header
+		requests int
+		err      bool
+	}{
+		{"ok", []int{http.StatusNoContent}, nil, 1, false},
+		{"server error then ok", []int{http.StatusBadGateway, http.StatusOK}, nil, 2, false},
+		{"rate limited with Retry-After", []int{http.StatusTooManyRequests, http.StatusOK}, http.Header{"Retry-After": {"0.01"}}, 2, false},
+		{"client error", []int{http.StatusBadRequest}, nil, 1, true},
+		{"not found", []int{http.StatusNotFound}, nil, 1, true},
+		{"server error every time", []int{http.StatusInternalServerError}, nil, 3, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := newWebhookServer(t, test.header, test.statuses...)
+			webhookURL := server.URL + "/api/webhooks/1/secret-token"
+			notifier, err := newWebhookNotifier([]string{webhookURL}, WebhookJSON)
+			if err != nil {
+				t.Fatal(err)
+			}
+			notifier.retries = 2
+			notifier.backoff = time.Millisecond
+			if test.header.Get("Retry-After") != "" {
+				// Only the Retry-After of the server keeps the test fast.
+				notifier.backoff = time.Hour
+			}
+
+			done := make(chan error)
+			go func() {
+				done <- notifier.post(webhookURL, []byte("{}"))
+			}()
+			select {
+			case err = <-done:
+			case <-time.After(5 * time.Second):
+				t.Fatal("post did not return")
+			}
+
+			if (err != nil) != test.err {
+				t.Errorf("err = %v, want error %t", err, test.err)
+			}
+			if err != nil && strings.Contains(err.Error(), "secret-token") {
+				t.Errorf("err = %v, leaks the webhook token", err)
+			}
+			if server.requests() != test.requests {
+				t.Errorf("%d requests, want %d", server.requests(), test.requests)
+			}
+		})
+	}
+
+	t.Run("network error", func(t *testing.T) {
+		server := newWebhookServer(t, nil, http.StatusOK)
+		webhookURL := server.URL + "/api/webhooks/1/secret-token"
+		server.Close()
+		notifier, err := newWebhookNotifier([]string{webhookURL}, WebhookJSON)
+		if err != nil {
+			t.Fatal(err)
+		}
+		notifier.retries = 0
+
+		err = notifier.post(webhookURL, []byte("{}"))
+		if err == nil || strings.Contains(err.Error(), "secret-token") {
+			t.Errorf("err = %v, want an error without the webhook token", err)
+		}
+	})
+}
+
+func TestWebhookNotify(t *testing.T) {
+	characterData, previous, current := testRoll()
+
+	tests := []struct {
+		name   string
+		format string
+		check  func(t *testing.T, body []byte)
+	}{
+		{"generic JSON", WebhookAuto, func(t *testing.T, body []byte) {
+			var payload struct {
+				Event         string        `json:"event"`
+				World         string        `json:"world"`
+				Character     int32         `json:"character"`
+				Biome         string        `json:"biome"`
+				PreviousBiome string        `json:"previous_biome"`
+				BloodMoon     bool          `json:"blood_moon"`
+				Zones         []string      `json:"zones"`
+				Missing       []overlayItem `json:"missing"`
+			}
+			err := json.Unmarshal(body, &payload)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if payload.Event != "roll" || payload.World != WorldAdventure || payload.Character != 2 || !payload.BloodMoon {
+				t.Errorf("payload = %+v", payload)
+			}
+			if payload.Biome != getBiomeName("Fae") || payload.PreviousBiome != getBiomeName("Jungle") {
+				t.Errorf("biomes = %q from %q", payload.Biome, payload.PreviousBiome)
+			}
+			if len(payload.Zones) != 1 || payload.Zones[0] != "Forgotten Commune" {
+				t.Errorf("zones = %v", payload.Zones)
+			}
+			// Scrap is a currency, only the weapon is missing.
+			if len(payload.Missing) != 1 || payload.Missing[0].Name != getPrintableName("Weapon_Nightfall_C") {
+				t.Errorf("missing = %+v", payload.Missing)
+			}
+		}},
+		{"discord embed", WebhookDiscord, func(t *testing.T, body []byte) {
+			var message discordMessage
+			err := json.Unmarshal(body, &message)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(message.Embeds) != 1 {
+				t.Fatalf("embeds = %+v", message.Embeds)
+			}
+			embed := message.Embeds[0]
+			if !strings.Contains(embed.Title, getBiomeName("Fae")) || !strings.HasSuffix(embed.Title, "(Blood Moon)") || embed.Color != discordBloodMoonColor {
+				t.Errorf("embed = %+v", embed)
+			}
+			fields := map[string]string{}
+			for _, field := range embed.Fields {
+				fields[field.Name] = field.Value
+			}
+			if fields["Zones"] != "Forgotten Commune" || fields["Missing"] != getPrintableName("Weapon_Nightfall_C")+" (Forgotten Commune)" {
+				t.Errorf("fields = %v", fields)
+			}
+		}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := newWebhookServer(t, nil, http.StatusNoContent)
+			notifier, err := newWebhookNotifier([]string{server.URL}, test.format)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			notifier.Notify(characterData, previous, current)
+			select {
+			case <-server.posted:
+			case <-time.After(5 * time.Second):
+				t.Fatal("nothing was posted")
+			}
+			server.mu.Lock()
+			body := server.bodies[0]
+			server.mu.Unlock()
+			test.check(t, body)
+		})
+	}
+
+	t.Run("same roll", func(t *testing.T) {
+		server := newWebhookServer(t, nil, http.StatusNoContent)
+		notifier, err := newWebhookNotifier([]string{server.URL}, WebhookJSON)
+		if err != nil {
+			t.Fatal(err)
+		}
+		notifier.Notify(characterData, current, current)
+		select {
+		case <-server.posted:
+			t.Error("an unchanged roll was posted")
+		case <-time.After(50 * time.Millisecond):
+		}
+	})
+}
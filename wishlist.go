@@ -0,0 +1,223 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path"
+	"runtime"
+	"strings"
+)
+
+// A wishlist is a JSON file with the rolls a player is hoping for:
+//
+//	{
+//	  "command": "notify-send ReFinder \"$REFINDER_WISH\"",
+//	  "wishes": [
+//	    {"name": "Nightweed", "item": "Weapon_Nightweed_C"},
+//	    {"event": "Quest_Miniboss_*", "biome": "Jungle", "blood_moon": true}
+//	  ]
+//	}
+//
+// A wish matches when all of its conditions hold. Item and event are globs
+// matched against blueprint names, items only match while the character does
+// not own them. The command runs through the shell on every new match.
+
+type Wish struct {
+	Name      string `json:"name,omitempty"`
+	Item      string `json:"item,omitempty"`
+	Event     string `json:"event,omitempty"`
+	Biome     string `json:"biome,omitempty"`
+	BloodMoon *bool  `json:"blood_moon,omitempty"`
+}
+
+type Wishlist struct {
+	Command string `json:"command,omitempty"`
+	Wishes  []Wish `json:"wishes"`
+}
+
+func (wish Wish) String() string {
+	if wish.Name != "" {
+		return wish.Name
+	}
+
+	var conditions []string
+	if wish.Item != "" {
+		conditions = append(conditions, "item "+wish.Item)
+	}
+	if wish.Event != "" {
+		conditions = append(conditions, "event "+wish.Event)
+	}
+	if wish.Biome != "" {
+		conditions = append(conditions, "biome "+wish.Biome)
+	}
+	if wish.BloodMoon != nil {
+		conditions = append(conditions, fmt.Sprintf("blood moon %v", *wish.BloodMoon))
+	}
+	return strings.Join(conditions, ", ")
+}
+
+func loadWishlist(fullPath string) (*Wishlist, error) {
+	data, err := os.ReadFile(fullPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var wishlist Wishlist
+	err = json.Unmarshal(data, &wishlist)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fullPath, err)
+	}
+
+	for i, wish := range wishlist.Wishes {
+		if wish.Item == "" && wish.Event == "" && wish.Biome == "" && wish.BloodMoon == nil {
+			return nil, fmt.Errorf("%s: wish %d has no conditions", fullPath, i+1)
+		}
+		for _, pattern := range []string{wish.Item, wish.Event} {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return nil, fmt.Errorf("%s: wish %d: invalid pattern %q", fullPath, i+1, pattern)
+			}
+		}
+	}
+
+	return &wishlist, nil
+}
+
+// WishMatch is a wish that holds for a world, Found lists what matched.
+type WishMatch struct {
+	Wish  Wish
+	Found []string
+}
+
+func (match WishMatch) String() string {
+	if len(match.Found) == 0 {
+		return match.Wish.String()
+	}
+	return fmt.Sprintf("%s: %s", match.Wish, strings.Join(match.Found, ", "))
+}
+
+func globMatch(pattern, name string) bool {
+	matched, _ := path.Match(pattern, name)
+	return matched
+}
+
+func matchBiome(biome string, zoneInfo ZoneInfo) bool {
+	return strings.EqualFold(biome, zoneInfo.Biome) || strings.EqualFold(biome, getBiomeName(zoneInfo.Biome))
+}
+
+// findWished collects what a wish looks for in the zone tree.
+func findWished(wish Wish, zone *ZoneActor, found *[]string) {
+	if zone == nil {
+		return
+	}
+
+	for _, item := range zone.Items {
+		if wish.Item != "" && wish.Event == "" && !item.OwnedByCharacter && globMatch(wish.Item, item.Name) {
+			*found = append(*found, fmt.Sprintf("%s in %s", getPrintableName(item.Name), zone.Label))
+		}
+	}
+	for _, event := range zone.Events {
+		if wish.Event != "" && !globMatch(wish.Event, event.Name) {
+			continue
+		}
+		if wish.Item == "" {
+			*found = append(*found, fmt.Sprintf("%s in %s", getPrintableName(event.Name), zone.Label))
+			continue
+		}
+		for _, reward := range event.Rewards {
+			if !reward.OwnedByCharacter && globMatch(wish.Item, reward.ActorBP) {
+				*found = append(*found, fmt.Sprintf("%s from %s in %s", getPrintableName(reward.ActorBP), getPrintableName(event.Name), zone.Label))
+			}
+		}
+	}
+
+	for _, child := range zone.Children {
+		findWished(wish, child, found)
+	}
+}
+
+// Match returns the wishes that hold for a world.
+func (wishlist *Wishlist) Match(zoneInfo ZoneInfo) []WishMatch {
+	var matches []WishMatch
+	for _, wish := range wishlist.Wishes {
+		if wish.Biome != "" && !matchBiome(wish.Biome, zoneInfo) {
+			continue
+		}
+		if wish.BloodMoon != nil && *wish.BloodMoon != zoneInfo.BloodMoon {
+			continue
+		}
+
+		match := WishMatch{Wish: wish}
+		if wish.Item != "" || wish.Event != "" {
+			findWished(wish, zoneInfo.ZoneActor, &match.Found)
+			if len(match.Found) == 0 {
+				continue
+			}
+		}
+		matches = append(matches, match)
+	}
+	return matches
+}
+
+// wishTracker remembers what matched per character, so a match is only
+// announced when a save first has it.
+type wishTracker struct {
+	wishlist *Wishlist
+	seen     map[int32]map[string]bool
+}
+
+func newWishTracker(wishlist *Wishlist) *wishTracker {
+	return &wishTracker{wishlist: wishlist, seen: map[int32]map[string]bool{}}
+}
+
+// Check returns the matches of a world that were not there at the previous
+// check.
+func (t *wishTracker) Check(characterID int32, zoneInfo ZoneInfo) []WishMatch {
+	if t == nil {
+		return nil
+	}
+
+	var matches []WishMatch
+	seen := map[string]bool{}
+	for _, match := range t.wishlist.Match(zoneInfo) {
+		key := match.String()
+		seen[key] = true
+		if !t.seen[characterID][key] {
+			matches = append(matches, match)
+		}
+	}
+	t.seen[characterID] = seen
+	return matches
+}
+
+// runWishCommand starts the wishlist command for a match without waiting for
+// it. The match is passed in environment variables.
+func (t *wishTracker) runWishCommand(characterData CharacterData, zoneInfo ZoneInfo, match WishMatch) {
+	if t == nil || t.wishlist.Command == "" {
+		return
+	}
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/C", t.wishlist.Command)
+	} else {
+		cmd = exec.Command("sh", "-c", t.wishlist.Command)
+	}
+	cmd.Env = append(os.Environ(),
+		"REFINDER_WISH="+match.String(),
+		fmt.Sprintf("REFINDER_CHARACTER=%d", characterData.ID),
+		"REFINDER_BIOME="+getBiomeName(zoneInfo.Biome),
+		fmt.Sprintf("REFINDER_BLOOD_MOON=%v", zoneInfo.BloodMoon),
+	)
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+
+	err := cmd.Start()
+	if err != nil {
+		log.Println("wishlist command:", err)
+		return
+	}
+	go cmd.Wait()
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func testWishWorld() ZoneInfo {
+	return ZoneInfo{Biome: "Fae", BloodMoon: false, ZoneActor: &ZoneActor{
+		Label: "Root",
+		Items: []ItemData{
+			{Name: "Weapon_Nightfall_C"},
+			{Name: "Ring_Owned_C", OwnedByCharacter: true},
+		},
+		Events: []Event{{
+			Name: "Quest_Miniboss_Root",
+			Rewards: []LootSpawn{
+				{ActorBP: "Amulet_Reward_C"},
+				{ActorBP: "Weapon_OwnedReward_C", OwnedByCharacter: true},
+			},
+		}},
+		Children: []*ZoneActor{{
+			Label:  "Child",
+			Events: []Event{{Name: "Quest_Boss_Child"}},
+		}},
+	}}
+}
+
+func TestWishlistMatch(t *testing.T) {
+	yes, no := true, false
+	in := func(name, zone string) string {
+		return getPrintableName(name) + " in " + zone
+	}
+
+	tests := []struct {
+		name  string
+		wish  Wish
+		found []string
+		match bool
+	}{
+		{"item", Wish{Item: "Weapon_Nightfall_C"}, []string{in("Weapon_Nightfall_C", "Root")}, true},
+		{"item glob", Wish{Item: "Weapon_*"}, []string{in("Weapon_Nightfall_C", "Root")}, true},
+		{"owned item", Wish{Item: "Ring_Owned_C"}, nil, false},
+		{"reward", Wish{Item: "Amulet_*"}, []string{getPrintableName("Amulet_Reward_C") + " from " + in("Quest_Miniboss_Root", "Root")}, true},
+		{"owned reward", Wish{Item: "Weapon_OwnedReward_C"}, nil, false},
+		{"event", Wish{Event: "Quest_Boss_*"}, []string{in("Quest_Boss_Child", "Child")}, true},
+		{"reward of event", Wish{Event: "Quest_Miniboss_*", Item: "Amulet_*"}, []string{getPrintableName("Amulet_Reward_C") + " from " + in("Quest_Miniboss_Root", "Root")}, true},
+		{"reward of other event", Wish{Event: "Quest_Boss_*", Item: "Amulet_*"}, nil, false},
+		{"biome", Wish{Biome: "fae"}, nil, true},
+		{"biome by name", Wish{Biome: getBiomeName("Fae")}, nil, true},
+		{"other biome", Wish{Biome: "Jungle", Item: "Weapon_*"}, nil, false},
+		{"no blood moon", Wish{BloodMoon: &no}, nil, true},
+		{"blood moon", Wish{BloodMoon: &yes}, nil, false},
+		{"all conditions", Wish{Biome: "Fae", BloodMoon: &no, Event: "Quest_Boss_Child"}, []string{in("Quest_Boss_Child", "Child")}, true},
+		{"missing event", Wish{Event: "Quest_Missing"}, nil, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			wishlist := &Wishlist{Wishes: []Wish{test.wish}}
+			matches := wishlist.Match(testWishWorld())
+			if (len(matches) == 1) != test.match {
+				t.Fatalf("matches = %v, want match %t", matches, test.match)
+			}
+			if test.match && !slices.Equal(matches[0].Found, test.found) {
+				t.Errorf("found = %q, want %q", matches[0].Found, test.found)
+			}
+		})
+	}
+}
+
+func TestWishTrackerCheck(t *testing.T) {
+	tracker := newWishTracker(&Wishlist{Wishes: []Wish{{Name: "Nightfall", Item: "Weapon_Nightfall_C"}}})
+	world := testWishWorld()
+	rolled := testWishWorld()
+	rolled.ZoneActor.Items = nil
+
+	steps := []struct {
+		name        string
+		characterID int32
+		world       ZoneInfo
+		matches     int
+	}{
+		{"first match", 0, world, 1},
+		{"same save", 0, world, 0},
+		{"other character", 1, world, 1},
+		{"rolled away", 0, rolled, 0},
+		{"rolled again", 0, world, 1},
+	}
+	for _, step := range steps {
+		if matches := tracker.Check(step.characterID, step.world); len(matches) != step.matches {
+			t.Errorf("%s: %d new matches, want %d", step.name, len(matches), step.matches)
+		}
+	}
+
+	var nilTracker *wishTracker
+	if matches := nilTracker.Check(0, world); matches != nil {
+		t.Errorf("nil tracker matched %v", matches)
+	}
+}
+
+func TestLoadWishlist(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   string
+		wishes int
+		err    string
+	}{
+		{"wishes", `{"command": "true", "wishes": [{"item": "Weapon_*"}, {"biome": "Fae", "blood_moon": true}]}`, 2, ""},
+		{"invalid JSON", `{"wishes": [}`, 0, "invalid character"},
+		{"no conditions", `{"wishes": [{"name": "nothing"}]}`, 0, "wish 1 has no conditions"},
+		{"invalid pattern", `{"wishes": [{"item": "Weapon_*"}, {"event": "Quest_["}]}`, 0, `wish 2: invalid pattern "Quest_["`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "wishlist.json")
+			err := os.WriteFile(path, []byte(test.data), 0o644)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			wishlist, err := loadWishlist(path)
+			if test.err != "" {
+				if err == nil || !strings.Contains(err.Error(), test.err) {
+					t.Errorf("err = %v, want it to contain %q", err, test.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(wishlist.Wishes) != test.wishes {
+				t.Errorf("%d wishes, want %d", len(wishlist.Wishes), test.wishes)
+			}
+		})
+	}
+}